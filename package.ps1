@@ -11,6 +11,13 @@ $files = @(
   @{ src = "$src\levels\hub.json";    dst = "levels/hub.json" }
 )
 
+# Data the game loads from these folders at startup.
+foreach ($d in @("biomes", "encounters", "prefabs")) {
+  foreach ($f in Get-ChildItem -Path "$src\$d" -Filter *.json -File) {
+    $files += @{ src = $f.FullName; dst = "$d/$($f.Name)" }
+  }
+}
+
 Add-Type -Assembly "System.IO.Compression.FileSystem"
 $zip = [System.IO.Compression.ZipFile]::Open($out, "Create")
 
@@ -0,0 +1,199 @@
+// Command levelgen runs the level generators outside the game and dumps what
+// they produce, so layouts can be inspected across many seeds at once.
+//
+// For every seed it writes an ASCII map (see legend below) and, on request, a
+// PNG and the LevelData JSON that the editor and game load:
+//
+//	go run ./cmd/levelgen -seed 1 -count 200 -png -out gen
+//	go run ./cmd/levelgen -gen maze -routing braid -seed 7
+//	go run ./cmd/levelgen -gen cave -flavor moss -seed 3
+//
+// With a single seed and no -out directory the ASCII map goes to stdout.
+//
+// -check runs every level through levelcheck and exits non-zero if any seed
+// breaks an invariant, so a batch doubles as a generator regression check:
+//
+//	go run ./cmd/levelgen -seed 1 -count 500 -check
+package main
+
+import (
+	"dungeoneer/levelcheck"
+	"dungeoneer/leveleditor"
+	"dungeoneer/levels"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// floor holds a generated level with the markers the game would place on it.
+type floor struct {
+	Level          *levels.Level
+	SpawnX, SpawnY int
+	ExitX, ExitY   int
+}
+
+func main() {
+	gen := flag.String("gen", "dungeon", "generator: dungeon (Generate64x64), cave (GenerateCave), maze (GenerateMaze) or forest (NewForestLevel)")
+	seed := flag.Int64("seed", 1, "first seed")
+	count := flag.Int("count", 1, "number of consecutive seeds to generate")
+	out := flag.String("out", "", "output directory (default: ASCII to stdout for a single seed)")
+	writePNG := flag.Bool("png", false, "also write a PNG per seed")
+	writeJSON := flag.Bool("json", false, "also write the LevelData JSON per seed")
+	scale := flag.Int("scale", 8, "PNG pixels per tile")
+	boss := flag.Bool("boss", false, "tag rooms as for the final (boss) floor")
+	check := flag.Bool("check", false, "validate every level and exit 1 on any violation")
+	minCoverage := flag.Float64("min-coverage", levelcheck.DefaultOptions().MinCoverage, "with -check: lowest allowed walkable ratio")
+	maxCoverage := flag.Float64("max-coverage", levelcheck.DefaultOptions().MaxCoverage, "with -check: highest allowed walkable ratio")
+	maxDeadEnds := flag.Int("max-dead-ends", -1, "with -check: most dead-end tiles allowed (-1 = report only)")
+
+	width := flag.Int("w", 64, "level width")
+	height := flag.Int("h", 64, "level height")
+	roomsMin := flag.Int("rooms-min", 9, "minimum room count")
+	roomsMax := flag.Int("rooms-max", 14, "maximum room count")
+	roomWMin := flag.Int("room-w-min", 8, "minimum room width")
+	roomWMax := flag.Int("room-w-max", 14, "maximum room width")
+	roomHMin := flag.Int("room-h-min", 8, "minimum room height")
+	roomHMax := flag.Int("room-h-max", 14, "maximum room height")
+	corridor := flag.Int("corridor", 1, "corridor width")
+	dashLen := flag.Int("dash-len", 7, "minimum dash lane length")
+	grapple := flag.Int("grapple", 10, "grapple anchor range")
+	extras := flag.Int("extras", 2, "extra corridor loops beyond the spanning tree")
+	coverage := flag.Float64("coverage", 0.42, "target walkable ratio")
+	filler := flag.Int("filler", 5, "maximum filler rooms")
+	lockChance := flag.Float64("lock-chance", 0.35, "chance a door is locked")
+	doorRC := flag.Float64("door-room-corridor", 0, "room-corridor door chance (0 = default)")
+	doorRR := flag.Float64("door-room-room", 0, "room-room door chance (0 = default)")
+	doorMax := flag.Int("door-max", 0, "max doors per room (0 = default)")
+	doorSpacing := flag.Int("door-spacing", 0, "min throat spacing (0 = default)")
+	wallFlavor := flag.String("flavor", "crypt", "wall flavor")
+	floorFlavor := flag.String("floor-flavor", "", "floor flavor (default: same as -flavor)")
+
+	tess := flag.String("tessellation", "ortho", "maze tessellation: ortho or fractal")
+	routing := flag.String("routing", "prim", "maze routing: prim, eller, braid or unicursal")
+	texture := flag.String("texture", "elitism", "maze texture: elitism, run or river")
+	flag.Parse()
+
+	if *floorFlavor == "" {
+		*floorFlavor = *wallFlavor
+	}
+	if *count < 1 {
+		*count = 1
+	}
+	if *out != "" {
+		if err := os.MkdirAll(*out, 0755); err != nil {
+			log.Fatal(err)
+		}
+	} else if *writePNG || *writeJSON || (*count > 1 && !*check) {
+		log.Fatal("-out is required with -png, -json, or -count > 1 without -check")
+	}
+
+	opts := levelcheck.DefaultOptions()
+	opts.MinCoverage = *minCoverage
+	opts.MaxCoverage = *maxCoverage
+	opts.MaxDeadEnds = *maxDeadEnds
+	failed := 0
+
+	for i := 0; i < *count; i++ {
+		s := *seed + int64(i)
+		var lvl *levels.Level
+		params := levels.GenParams{
+			Seed:           s,
+			Width:          *width,
+			Height:         *height,
+			RoomCountMin:   *roomsMin,
+			RoomCountMax:   *roomsMax,
+			RoomWMin:       *roomWMin,
+			RoomWMax:       *roomWMax,
+			RoomHMin:       *roomHMin,
+			RoomHMax:       *roomHMax,
+			CorridorWidth:  *corridor,
+			DashLaneMinLen: *dashLen,
+			GrappleRange:   *grapple,
+			Extras:         *extras,
+			CoverageTarget: *coverage,
+			FillerRoomsMax: *filler,
+			DoorLockChance: *lockChance,
+			DoorDensity: levels.DoorDensityConfig{
+				RoomCorridorChance: *doorRC,
+				RoomRoomChance:     *doorRR,
+				MaxDoorsPerRoom:    *doorMax,
+				MinThroatSpacing:   *doorSpacing,
+			},
+			WallFlavor:  *wallFlavor,
+			FloorFlavor: *floorFlavor,
+		}
+		switch *gen {
+		case "dungeon":
+			lvl = levels.Generate64x64(params)
+		case "cave":
+			lvl = levels.GenerateCave(params)
+		case "maze":
+			// The maze generators roll from the global source, so -seed only
+			// names the output file.
+			lvl = levels.GenerateMaze(levels.MazeConfig{
+				Width:        *width,
+				Height:       *height,
+				Tessellation: *tess,
+				Routing:      *routing,
+				Texture:      *texture,
+				WallFlavor:   *wallFlavor,
+			}, nil)
+		case "forest":
+			// Likewise unseeded.
+			var err error
+			lvl, err = levels.NewForestLevel()
+			if err != nil {
+				log.Fatal(err)
+			}
+		default:
+			log.Fatalf("unknown generator %q", *gen)
+		}
+		if lvl == nil {
+			log.Fatalf("seed %d: generator returned no level", s)
+		}
+
+		f := floor{Level: lvl}
+		f.SpawnX, f.SpawnY, f.ExitX, f.ExitY = levels.FindSpawnAndExit(lvl)
+		levels.TagRooms(lvl, f.SpawnX, f.SpawnY, f.ExitX, f.ExitY, *boss)
+
+		if *check {
+			report := levelcheck.Check(lvl, opts)
+			if !report.OK() {
+				failed++
+			}
+			fmt.Printf("seed %d: %s\n", s, report)
+		}
+		if *out == "" {
+			if *count == 1 {
+				fmt.Print(renderASCII(f))
+			}
+			continue
+		}
+		base := filepath.Join(*out, fmt.Sprintf("%s-%d", *gen, s))
+		if err := os.WriteFile(base+".txt", []byte(renderASCII(f)), 0644); err != nil {
+			log.Fatal(err)
+		}
+		if *writePNG {
+			if err := writePNGFile(base+".png", f, *scale); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if *writeJSON {
+			if err := leveleditor.SaveLevelToFile(lvl, base+".json"); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if !*check {
+			fmt.Println(summary(s, f))
+		}
+	}
+
+	if *check {
+		fmt.Printf("%d of %d seeds failed\n", failed, *count)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	}
+}
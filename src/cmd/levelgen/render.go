@@ -0,0 +1,203 @@
+package main
+
+import (
+	"dungeoneer/levels"
+	"dungeoneer/tiles"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+)
+
+// Map legend. Room letters mark the centre of each room with its primary tag.
+const legend = `# wall   . floor   = dash lane   ^ grapple anchor
++ closed door   / open door   L locked door   S spawn   E exit
+rooms: B boss arena  s sanctuary  t treasure  g guard post  b barracks
+       a ambush  c crossroads  d dead end  x secret  o common
+       (spawn/exit rooms use S/E)
+`
+
+var tagGlyphs = map[levels.RoomTag]byte{
+	levels.TagBossArena:  'B',
+	levels.TagSanctuary:  's',
+	levels.TagTreasure:   't',
+	levels.TagGuardPost:  'g',
+	levels.TagBarracks:   'b',
+	levels.TagAmbush:     'a',
+	levels.TagCrossroads: 'c',
+	levels.TagDeadEnd:    'd',
+	levels.TagSecret:     'x',
+	levels.TagCommon:     'o',
+}
+
+// tileGlyph returns the ASCII character for a single tile.
+func tileGlyph(t *tiles.Tile) byte {
+	switch {
+	case t == nil:
+		return ' '
+	case t.HasTag(tiles.TagDoor):
+		switch t.DoorState {
+		case 1:
+			return '/'
+		case 3:
+			return 'L'
+		default:
+			return '+'
+		}
+	case !t.IsWalkable:
+		if len(t.Sprites) == 0 {
+			return ' '
+		}
+		return '#'
+	case t.HasTag(tiles.TagGrappleAnchor):
+		return '^'
+	case t.HasTag(tiles.TagDashLane):
+		return '='
+	default:
+		return '.'
+	}
+}
+
+// renderASCII draws the level followed by the legend and a room listing.
+func renderASCII(f floor) string {
+	l := f.Level
+	grid := make([][]byte, l.H)
+	for y := range grid {
+		grid[y] = make([]byte, l.W)
+		for x := range grid[y] {
+			grid[y][x] = tileGlyph(l.Tile(x, y))
+		}
+	}
+	for _, r := range l.Rooms {
+		if g, ok := tagGlyphs[r.PrimaryTag()]; ok && grid[r.CenterY][r.CenterX] == '.' {
+			grid[r.CenterY][r.CenterX] = g
+		}
+	}
+	grid[f.SpawnY][f.SpawnX] = 'S'
+	grid[f.ExitY][f.ExitX] = 'E'
+
+	var sb strings.Builder
+	for _, row := range grid {
+		sb.Write(row)
+		sb.WriteByte('\n')
+	}
+	sb.WriteByte('\n')
+	sb.WriteString(legend)
+	if len(l.Rooms) > 0 {
+		sb.WriteString("\nrooms:\n")
+		for _, r := range l.Rooms {
+			tags := make([]string, len(r.Tags))
+			for i, t := range r.Tags {
+				tags[i] = string(t)
+			}
+			fmt.Fprintf(&sb, "  %2d  (%2d,%2d) %2dx%-2d %-6s %s\n",
+				r.Index, r.X, r.Y, r.W, r.H, r.Size, strings.Join(tags, ", "))
+		}
+	}
+	return sb.String()
+}
+
+// Tile colours for the PNG dump.
+var (
+	colVoid    = color.RGBA{0, 0, 0, 255}
+	colWall    = color.RGBA{70, 70, 80, 255}
+	colFloor   = color.RGBA{190, 180, 160, 255}
+	colDash    = color.RGBA{110, 170, 230, 255}
+	colAnchor  = color.RGBA{230, 200, 60, 255}
+	colDoor    = color.RGBA{150, 90, 40, 255}
+	colOpen    = color.RGBA{200, 140, 80, 255}
+	colLocked  = color.RGBA{200, 40, 40, 255}
+	colSpawn   = color.RGBA{60, 200, 80, 255}
+	colExit    = color.RGBA{180, 60, 220, 255}
+	colRoomTag = color.RGBA{255, 255, 255, 255}
+)
+
+func glyphColor(g byte) color.RGBA {
+	switch g {
+	case '#':
+		return colWall
+	case '.':
+		return colFloor
+	case '=':
+		return colDash
+	case '^':
+		return colAnchor
+	case '+':
+		return colDoor
+	case '/':
+		return colOpen
+	case 'L':
+		return colLocked
+	case 'S':
+		return colSpawn
+	case 'E':
+		return colExit
+	case ' ':
+		return colVoid
+	default:
+		return colRoomTag
+	}
+}
+
+// writePNGFile renders the same map as renderASCII with scale pixels per tile.
+func writePNGFile(path string, f floor, scale int) error {
+	if scale < 1 {
+		scale = 1
+	}
+	l := f.Level
+	img := image.NewRGBA(image.Rect(0, 0, l.W*scale, l.H*scale))
+	lines := strings.SplitN(renderASCII(f), "\n", l.H+1)
+	for y := 0; y < l.H; y++ {
+		for x := 0; x < l.W; x++ {
+			c := glyphColor(lines[y][x])
+			for py := 0; py < scale; py++ {
+				for px := 0; px < scale; px++ {
+					img.SetRGBA(x*scale+px, y*scale+py, c)
+				}
+			}
+		}
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(out, img); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// summary is the one-line report printed per seed in batch runs.
+func summary(seed int64, f floor) string {
+	l := f.Level
+	walkable, doors, locked, anchors, lanes := 0, 0, 0, 0, 0
+	for y := 0; y < l.H; y++ {
+		for x := 0; x < l.W; x++ {
+			t := l.Tile(x, y)
+			if t == nil {
+				continue
+			}
+			if t.IsWalkable {
+				walkable++
+			}
+			if t.HasTag(tiles.TagDoor) {
+				doors++
+				if t.DoorState == 3 {
+					locked++
+				}
+			}
+			if t.HasTag(tiles.TagGrappleAnchor) {
+				anchors++
+			}
+			if t.HasTag(tiles.TagDashLane) {
+				lanes++
+			}
+		}
+	}
+	return fmt.Sprintf("seed %d: %d rooms, coverage %.2f, %d doors (%d locked), %d anchors, %d dash tiles, spawn (%d,%d) exit (%d,%d)",
+		seed, len(l.Rooms), float64(walkable)/float64(l.W*l.H), doors, locked, anchors, lanes,
+		f.SpawnX, f.SpawnY, f.ExitX, f.ExitY)
+}
@@ -71,6 +71,9 @@ const (
 	DashSpeedMultiplier = 3.0 // multiple of normal move speed
 )
 
+// BeltSlots is how many consumables the quick-use belt holds.
+const BeltSlots = 4
+
 // Grappling hook mechanics
 const (
 	GrappleMaxDistance = 12.0 // tiles
@@ -28,6 +28,12 @@ const (
 	ActionSpell5 ActionID = "spell_5"
 	ActionSpell6 ActionID = "spell_6"
 
+	// Quick-use belt
+	ActionBelt1 ActionID = "belt_1"
+	ActionBelt2 ActionID = "belt_2"
+	ActionBelt3 ActionID = "belt_3"
+	ActionBelt4 ActionID = "belt_4"
+
 	// Player abilities
 	ActionDash   ActionID = "dash"
 	ActionGrapple ActionID = "grapple"
@@ -81,6 +87,12 @@ var defaultBindings = map[ActionID]KeyBinding{
 	ActionSpell5: {Primary: ebiten.Key5},
 	ActionSpell6: {Primary: ebiten.Key6},
 
+	// Quick-use belt
+	ActionBelt1: {Primary: ebiten.Key7},
+	ActionBelt2: {Primary: ebiten.Key8},
+	ActionBelt3: {Primary: ebiten.Key9},
+	ActionBelt4: {Primary: ebiten.Key0},
+
 	// Game
 	ActionInventory:     {Primary: ebiten.KeyTab},
 	ActionHeroPanel:     {Primary: ebiten.KeyH},
@@ -159,6 +171,10 @@ func GetAllActionIDs() []ActionID {
 		ActionSpell4,
 		ActionSpell5,
 		ActionSpell6,
+		ActionBelt1,
+		ActionBelt2,
+		ActionBelt3,
+		ActionBelt4,
 		ActionInventory,
 		ActionHeroPanel,
 		ActionInteract,
@@ -187,6 +203,10 @@ func GetActionLabel(action ActionID) string {
 		ActionSpell4:        "Spell 4 - Lightning Storm",
 		ActionSpell5:        "Spell 5 - Fractal Bloom",
 		ActionSpell6:        "Spell 6 - Fractal Canopy",
+		ActionBelt1:         "Belt Slot 1",
+		ActionBelt2:         "Belt Slot 2",
+		ActionBelt3:         "Belt Slot 3",
+		ActionBelt4:         "Belt Slot 4",
 		ActionInteract:      "Interact",
 		ActionInventory:     "Open Inventory",
 		ActionHeroPanel:     "Toggle Hero Panel",
@@ -0,0 +1,200 @@
+package entities
+
+import (
+	"dungeoneer/levels"
+	"dungeoneer/simrand"
+)
+
+// Affix is an elite modifier rolled onto a monster when it spawns.
+type Affix string
+
+const (
+	AffixVampiric    Affix = "vampiric"    // heals for part of the damage it deals
+	AffixExplosive   Affix = "explosive"   // bursts when it dies, hurting the player nearby
+	AffixTeleporting Affix = "teleporting" // blinks to the player's side every few seconds
+	AffixShielded    Affix = "shielded"    // a shield soaks damage and comes back after a while
+	AffixFrenzied    Affix = "frenzied"    // moves and attacks faster once badly hurt
+	AffixPoisonous   Affix = "poisonous"   // poisons the player standing near it
+)
+
+// AffixInfo describes how an affix shows on a monster.
+type AffixInfo struct {
+	Prefix string     // put in front of the monster's name
+	Tint   [3]float32 // colour scale applied to its sprite
+}
+
+// Affixes maps each affix to its description.
+var Affixes = map[Affix]AffixInfo{
+	AffixVampiric:    {Prefix: "Vampiric", Tint: [3]float32{1, 0.55, 0.6}},
+	AffixExplosive:   {Prefix: "Volatile", Tint: [3]float32{1, 0.7, 0.35}},
+	AffixTeleporting: {Prefix: "Blinking", Tint: [3]float32{0.75, 0.6, 1}},
+	AffixShielded:    {Prefix: "Warded", Tint: [3]float32{0.6, 0.85, 1}},
+	AffixFrenzied:    {Prefix: "Frenzied", Tint: [3]float32{1, 0.45, 0.4}},
+	AffixPoisonous:   {Prefix: "Venomous", Tint: [3]float32{0.6, 1, 0.5}},
+}
+
+// AffixOrder lists every affix in a fixed order, for seeded rolls.
+var AffixOrder = []Affix{
+	AffixVampiric, AffixExplosive, AffixTeleporting, AffixShielded, AffixFrenzied, AffixPoisonous,
+}
+
+const (
+	// affixHPBonus is the extra max HP each affix gives, as a fraction.
+	affixHPBonus = 0.25
+	// vampiricLeech is the fraction of damage dealt a vampiric monster heals.
+	vampiricLeech = 0.5
+	// teleportTicks is how often a teleporting monster blinks to the player.
+	teleportTicks = 300
+	// shieldTicks is how long a shielded monster's shield takes to come back
+	// after it breaks, and shieldShare the part of max HP it soaks.
+	shieldTicks = 480
+	shieldShare = 3
+	// A frenzied monster is hasted below half health and attacks faster.
+	frenzyHaste = 50
+	// A poisonous monster's aura poisons the player within auraRadius tiles
+	// once every auraTicks.
+	auraRadius = 2
+	auraTicks  = 60
+)
+
+// affixState holds an affixed monster's timers.
+type affixState struct {
+	teleport, shield, aura int
+}
+
+// AddAffix gives m an affix: its name gains the prefix and its health grows.
+// A shielded monster starts with its shield up.
+func (m *Monster) AddAffix(a Affix) {
+	if m.HasAffix(a) {
+		return
+	}
+	m.Affixes = append(m.Affixes, a)
+	m.Name = Affixes[a].Prefix + " " + m.Name
+	bonus := int(float64(m.MaxHP) * affixHPBonus)
+	m.MaxHP += bonus
+	m.HP += bonus
+	if a == AffixShielded {
+		m.raiseShield()
+	}
+}
+
+// HasAffix reports whether m has affix a.
+func (m *Monster) HasAffix(a Affix) bool {
+	for _, have := range m.Affixes {
+		if have == a {
+			return true
+		}
+	}
+	return false
+}
+
+// AffixTint returns the colour scale m is drawn with: its affixes' tints
+// blended together. ok is false for a monster without affixes.
+func (m *Monster) AffixTint() (r, g, b float32, ok bool) {
+	if len(m.Affixes) == 0 {
+		return 1, 1, 1, false
+	}
+	for _, a := range m.Affixes {
+		t := Affixes[a].Tint
+		r, g, b = r+t[0], g+t[1], b+t[2]
+	}
+	n := float32(len(m.Affixes))
+	return r / n, g / n, b / n, true
+}
+
+// updateAffixes runs m's affixes for one tick.
+func (m *Monster) updateAffixes(p *Player, level *levels.Level) {
+	if len(m.Affixes) == 0 || m.IsDead {
+		return
+	}
+	for _, a := range m.Affixes {
+		switch a {
+		case AffixTeleporting:
+			m.affix.teleport++
+			if m.affix.teleport >= teleportTicks && m.Noticed() && !m.Moving {
+				if m.teleportNear(p, level) {
+					m.affix.teleport = 0
+				}
+			}
+		case AffixShielded:
+			if m.Effects.ShieldAmount() > 0 {
+				m.affix.shield = 0
+				continue
+			}
+			m.affix.shield++
+			if m.affix.shield >= shieldTicks {
+				m.raiseShield()
+			}
+		case AffixFrenzied:
+			if m.frenzied() {
+				m.Effects.AddEffect(&StatusEffect{Type: EffectHaste, Duration: 1, Value: frenzyHaste, Source: "frenzy"})
+			}
+		case AffixPoisonous:
+			m.affix.aura++
+			if m.affix.aura < auraTicks || p == nil || p.IsDead {
+				continue
+			}
+			m.affix.aura = 0
+			if absi(p.TileX-m.TileX) <= auraRadius && absi(p.TileY-m.TileY) <= auraRadius {
+				p.Effects.AddEffect(&StatusEffect{
+					Type: EffectPoison, Duration: 3, TickRate: 1, Value: max(1, m.Damage/4), Source: "poison_aura",
+				})
+			}
+		}
+	}
+}
+
+// raiseShield gives m a shield worth a share of its max HP. It lasts until
+// broken.
+func (m *Monster) raiseShield() {
+	m.Effects.AddEffect(&StatusEffect{Type: EffectShield, Duration: 1e9, Value: max(1, m.MaxHP/shieldShare), Source: "ward"})
+	m.affix.shield = 0
+}
+
+// frenzied reports whether a frenzied monster is hurt enough to rage.
+func (m *Monster) frenzied() bool {
+	return m.HasAffix(AffixFrenzied) && m.HP*2 <= m.MaxHP
+}
+
+// attackRate is the ticks between m's melee attacks, a third less while
+// frenzied.
+func (m *Monster) attackRate() int {
+	if m.frenzied() {
+		return m.AttackRate * 2 / 3
+	}
+	return m.AttackRate
+}
+
+// leech heals a vampiric monster for part of dmg it just dealt.
+func (m *Monster) leech(dmg int) {
+	if m.HasAffix(AffixVampiric) {
+		m.HP = min(m.MaxHP, m.HP+max(1, int(float64(dmg)*vampiricLeech)))
+	}
+}
+
+// teleportNear blinks m onto a free tile next to the player, queuing the
+// blink trail for the game to draw. It reports false when m is already next
+// to the player or there is no room.
+func (m *Monster) teleportNear(p *Player, level *levels.Level) bool {
+	if p == nil || p.IsDead || absi(p.TileX-m.TileX) <= 1 && absi(p.TileY-m.TileY) <= 1 {
+		return false
+	}
+	dirs := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	simrand.Shuffle(len(dirs), func(i, j int) { dirs[i], dirs[j] = dirs[j], dirs[i] })
+	for _, d := range dirs {
+		x, y := p.TileX+d[0], p.TileY+d[1]
+		if !level.IsWalkable(x, y) {
+			continue
+		}
+		m.PendingSpells = append(m.PendingSpells, PendingSpellCast{
+			SpellName: "blink",
+			OriginX:   m.InterpX, OriginY: m.InterpY,
+			TargetX: float64(x), TargetY: float64(y),
+		})
+		m.TileX, m.TileY = x, y
+		m.InterpX, m.InterpY = float64(x), float64(y)
+		m.Path = nil
+		return true
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package entities
+
+import "testing"
+
+func TestAffixesShowAndToughen(t *testing.T) {
+	m := newSentry(2, 2)
+	m.Name = "Grey Knight"
+	m.HP, m.MaxHP = 40, 40
+	m.AddAffix(AffixShielded)
+	m.AddAffix(AffixVampiric)
+	m.AddAffix(AffixVampiric) // already has it
+
+	if m.Name != "Vampiric Warded Grey Knight" {
+		t.Fatalf("name = %q", m.Name)
+	}
+	if len(m.Affixes) != 2 || m.MaxHP <= 40 || m.HP != m.MaxHP {
+		t.Fatalf("affixes %v, HP %d/%d", m.Affixes, m.HP, m.MaxHP)
+	}
+	if _, _, _, ok := m.AffixTint(); !ok {
+		t.Fatal("affixed monster has no tint")
+	}
+
+	// The ward soaks the first hit.
+	shield := m.Effects.ShieldAmount()
+	var markers []HitMarker
+	var numbers []DamageNumber
+	m.TakeDamage(shield, &markers, &numbers)
+	if m.HP != m.MaxHP {
+		t.Fatalf("HP %d/%d after a hit the shield should have taken", m.HP, m.MaxHP)
+	}
+
+	// Vampiric attacks heal.
+	m.HP, m.Damage = 10, 6
+	p := &Player{HP: 100, TileX: 3, TileY: 2}
+	m.AttackTick = m.AttackRate
+	m.CombatCheck(p)
+	if p.HP == 100 || m.HP <= 10 {
+		t.Fatalf("after a vampiric hit: player HP %d, monster HP %d", p.HP, m.HP)
+	}
+}
@@ -126,21 +126,16 @@ func NewStatueMonster(ss *sprites.SpriteSheet) []*Monster {
 	}
 }
 
+func (b *AmbushBehavior) sightRadius() int { return b.TriggerRadius }
+
 func (b *AmbushBehavior) Update(m *Monster, p *Player, level *levels.Level) {
 	if m.IsDead {
 		return
 	}
 
+	b.Triggered = m.Noticed()
 	if !b.Triggered {
-		// Check if player is close enough to activate
-		dx := m.TileX - p.TileX
-		dy := m.TileY - p.TileY
-		distSq := dx*dx + dy*dy
-		if distSq <= b.TriggerRadius*b.TriggerRadius {
-			b.Triggered = true
-		} else {
-			return // stay dormant
-		}
+		return // stay dormant
 	}
 
 	// Once triggered, behave like an aggressive chaser
@@ -36,6 +36,8 @@ func NewCasterBehavior(triggerRadius int) *CasterBehavior {
 	}
 }
 
+func (c *CasterBehavior) sightRadius() int { return c.TriggerRadius }
+
 func (c *CasterBehavior) Update(m *Monster, p *Player, level *levels.Level) {
 	if m.IsDead || m.Moving {
 		return
@@ -46,13 +48,9 @@ func (c *CasterBehavior) Update(m *Monster, p *Player, level *levels.Level) {
 	distSq := dx*dx + dy*dy
 	dist := math.Sqrt(float64(distSq))
 
-	// Trigger check.
+	c.Triggered = m.Noticed()
 	if !c.Triggered {
-		if distSq <= c.TriggerRadius*c.TriggerRadius {
-			c.Triggered = true
-		} else {
-			return
-		}
+		return
 	}
 
 	c.CastCounter++
@@ -5,4 +5,5 @@ type DamageNumber struct {
 	Value    int
 	Ticks    int
 	MaxTicks int
+	Label    string // shown after the value, e.g. the item effect behind it
 }
@@ -0,0 +1,122 @@
+package entities
+
+import (
+	"dungeoneer/levels"
+	"dungeoneer/pathing"
+	"dungeoneer/tiles"
+)
+
+// DoorPolicy is how a monster gets past a closed door. Locked doors stop
+// every monster whatever its policy.
+type DoorPolicy uint8
+
+const (
+	DoorsBlock DoorPolicy = iota // closed doors are walls
+	DoorsOpen                    // opens them the way the player does
+	DoorsBash                    // batters them until they break open
+	DoorsPhase                   // drifts through them, leaving them shut
+)
+
+var doorPolicyNames = map[DoorPolicy]string{
+	DoorsBlock: "block",
+	DoorsOpen:  "open",
+	DoorsBash:  "bash",
+	DoorsPhase: "phase",
+}
+
+func (d DoorPolicy) String() string { return doorPolicyNames[d] }
+
+// DoorPolicyByName returns the door policy with the given name.
+func DoorPolicyByName(name string) (DoorPolicy, bool) {
+	for d, n := range doorPolicyNames {
+		if n == name {
+			return d, true
+		}
+	}
+	return DoorsBlock, false
+}
+
+// DoorRequest asks the game to open the closed door at (X, Y) for a monster,
+// or with Bash to land one blow on it.
+type DoorRequest struct {
+	X, Y int
+	Bash bool
+}
+
+// pathTo finds m a path to (x, y) that goes through closed doors if m can
+// get past them.
+func (m *Monster) pathTo(level *levels.Level, x, y int) []pathing.PathNode {
+	if m.Doors == DoorsBlock {
+		return pathing.AStar(level, m.TileX, m.TileY, x, y)
+	}
+	return pathing.AStarThroughDoors(level, m.TileX, m.TileY, x, y)
+}
+
+// walkable reports whether m could stand on (x, y), counting closed doors it
+// can get past.
+func (m *Monster) walkable(level *levels.Level, x, y int) bool {
+	return level.IsWalkable(x, y) || m.Doors != DoorsBlock && closedDoor(level, x, y)
+}
+
+// stepOnto moves m onto the next tile of its path. A closed door there is
+// dealt with as m's DoorPolicy says: opening or bashing it keeps m waiting in
+// front of it, so moved is false but the path stays good. blocked is set
+// when the path is no use any more.
+func (m *Monster) stepOnto(level *levels.Level, x, y int) (moved, blocked bool) {
+	if level.IsWalkable(x, y) {
+		m.MoveTo(x, y)
+		return true, false
+	}
+	if !closedDoor(level, x, y) {
+		return false, true
+	}
+	switch m.Doors {
+	case DoorsPhase:
+		m.MoveTo(x, y)
+		return true, false
+	case DoorsOpen:
+		m.faceToward(x)
+		m.requestDoor(DoorRequest{X: x, Y: y})
+		return false, false
+	case DoorsBash:
+		m.faceToward(x)
+		m.doorTick++
+		if m.doorTick >= m.AttackRate {
+			m.doorTick = 0
+			m.requestDoor(DoorRequest{X: x, Y: y, Bash: true})
+		}
+		return false, false
+	}
+	return false, true
+}
+
+// requestDoor queues r for the game unless it is already queued.
+func (m *Monster) requestDoor(r DoorRequest) {
+	for _, q := range m.PendingDoors {
+		if q == r {
+			return
+		}
+	}
+	m.PendingDoors = append(m.PendingDoors, r)
+}
+
+// followPath takes the next step of m.Path, dropping the path if it is
+// blocked.
+func (m *Monster) followPath(level *levels.Level) {
+	if len(m.Path) == 0 {
+		return
+	}
+	next := m.Path[0]
+	moved, blocked := m.stepOnto(level, next.X, next.Y)
+	switch {
+	case moved:
+		m.Path = m.Path[1:]
+	case blocked:
+		m.Path = nil
+	}
+}
+
+func closedDoor(level *levels.Level, x, y int) bool {
+	t := level.Tile(x, y)
+	return t != nil && t.HasTag(tiles.TagDoor) && t.DoorState == 2
+}
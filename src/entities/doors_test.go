@@ -0,0 +1,76 @@
+package entities
+
+import (
+	"testing"
+
+	"dungeoneer/tiles"
+)
+
+func TestStepOntoClosedDoor(t *testing.T) {
+	l := openRoom(6, 3)
+	door := l.Tiles[1][3]
+	door.SetTag(tiles.TagDoor)
+	door.DoorState = 2
+	door.IsWalkable = false
+
+	for _, tc := range []struct {
+		doors          DoorPolicy
+		moved, blocked bool
+		request        bool
+	}{
+		{DoorsBlock, false, true, false},
+		{DoorsOpen, false, false, true},
+		{DoorsBash, false, false, true},
+		{DoorsPhase, true, false, false},
+	} {
+		m := newSentry(2, 1)
+		m.Doors = tc.doors
+		m.AttackRate = 1
+		moved, blocked := m.stepOnto(l, 3, 1)
+		if moved != tc.moved || blocked != tc.blocked {
+			t.Errorf("%v: moved %v blocked %v, want %v %v", tc.doors, moved, blocked, tc.moved, tc.blocked)
+		}
+		if got := len(m.PendingDoors) > 0; got != tc.request {
+			t.Errorf("%v: door request %v, want %v", tc.doors, got, tc.request)
+		}
+		if tc.doors == DoorsBash && len(m.PendingDoors) > 0 && !m.PendingDoors[0].Bash {
+			t.Errorf("bash policy asked to open the door instead")
+		}
+	}
+
+	door.DoorState = 3
+	for _, d := range []DoorPolicy{DoorsOpen, DoorsBash, DoorsPhase} {
+		m := newSentry(2, 1)
+		m.Doors = d
+		if moved, blocked := m.stepOnto(l, 3, 1); moved || !blocked {
+			t.Errorf("%v: got past a locked door", d)
+		}
+	}
+}
+
+func TestSwarmChaseKeepsDoorPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		doors DoorPolicy
+		moved bool
+	}{
+		{DoorsBlock, false},
+		{DoorsPhase, true},
+	} {
+		l := openRoom(6, 3)
+		door := l.Tiles[1][3]
+		door.SetTag(tiles.TagDoor)
+		door.DoorState = 2
+		door.IsWalkable = false
+
+		m := newSentry(2, 1)
+		m.Behavior = NewSwarmBehavior(4)
+		m.Doors = tc.doors
+		p := &Player{TileX: 4, TileY: 1}
+		m.Alert(p.TileX, p.TileY)
+
+		m.Behavior.Update(m, p, l)
+		if m.Moving != tc.moved {
+			t.Errorf("%v: swarm moved onto the closed door %v, want %v", tc.doors, m.Moving, tc.moved)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package entities
+
+import "dungeoneer/levels"
+
+// separationCost is the flow field penalty for each living sibling on a tile
+// next to a candidate step, in path cost units (10 per orthogonal step). It
+// is enough to pick a free tile over an equally short crowded one, not to
+// send a swarm the long way round.
+const separationCost = 6
+
+// flowStep picks m's next tile toward the player from the shared flow field.
+// ok is false when there is no field leading from m to the player and the
+// caller should fall back to its own pathing. Otherwise (x, y) is the tile
+// to move to, or m's own tile when it should hold its ground: it is already
+// next to the player, or its siblings have every way forward covered.
+func (m *Monster) flowStep(p *Player, level *levels.Level) (x, y int, ok bool) {
+	f := m.Flow
+	if f == nil || !f.Covers(level, m.TileX, m.TileY) || f.Dist(p.TileX, p.TileY) != 0 {
+		return 0, 0, false
+	}
+	if IsAdjacent(m.TileX, m.TileY, p.TileX, p.TileY) {
+		return m.TileX, m.TileY, true
+	}
+	penalty := func(x, y int) int {
+		if x == p.TileX && y == p.TileY {
+			return -1
+		}
+		return m.separation(x, y)
+	}
+	if nx, ny, ok := f.Next(m.TileX, m.TileY, penalty); ok {
+		return nx, ny, true
+	}
+	return m.TileX, m.TileY, true
+}
+
+// followFlow moves m one step down the flow field and reports whether the
+// field was in charge; see flowStep.
+func (m *Monster) followFlow(p *Player, level *levels.Level) bool {
+	x, y, ok := m.flowStep(p, level)
+	if ok && (x != m.TileX || y != m.TileY) {
+		m.MoveTo(x, y)
+	}
+	return ok
+}
+
+// separation scores how crowded (x, y) is by m's siblings: -1 if one stands
+// on or is moving onto it, otherwise separationCost per sibling next to it.
+func (m *Monster) separation(x, y int) int {
+	cost := 0
+	for _, sib := range m.Siblings {
+		if sib == m || sib.IsDead {
+			continue
+		}
+		sx, sy := sib.TileX, sib.TileY
+		if sib.Moving {
+			sx, sy = int(sib.TargetX), int(sib.TargetY)
+		}
+		if sx == x && sy == y {
+			return -1
+		}
+		if absi(sx-x) <= 1 && absi(sy-y) <= 1 {
+			cost += separationCost
+		}
+	}
+	return cost
+}
@@ -0,0 +1,35 @@
+package entities
+
+import "dungeoneer/tiles"
+
+// hazardEffects is the status effect each hazard keeps on whoever stands in
+// it. Durations are short so the effect lingers briefly after stepping out.
+var hazardEffects = map[uint8]StatusEffect{
+	tiles.HazardLava:  {Type: EffectBurn, Duration: 2, TickRate: 0.5, Value: 4},
+	tiles.HazardWater: {Type: EffectSlow, Duration: 0.5, Value: 40},
+	tiles.HazardGas:   {Type: EffectPoison, Duration: 3, TickRate: 1, Value: 2},
+}
+
+// hazardEntryDamage is dealt once when stepping onto a hazard tile.
+var hazardEntryDamage = map[uint8]int{
+	tiles.HazardLava:   6,
+	tiles.HazardSpikes: 8,
+}
+
+// ApplyHazard refreshes the hazard's status effect on h and returns the damage
+// the caller should deal, which is non-zero only when entered is set (the
+// entity has just stepped onto the tile).
+func ApplyHazard(h *EffectHolder, hazard uint8, entered bool) int {
+	info, ok := tiles.Hazards[hazard]
+	if !ok {
+		return 0
+	}
+	if e, ok := hazardEffects[hazard]; ok {
+		e.Source = "hazard_" + info.Name
+		h.AddEffect(&e)
+	}
+	if !entered {
+		return 0
+	}
+	return hazardEntryDamage[hazard]
+}
@@ -159,5 +159,60 @@ func (p *Player) AddToInventory(it *items.Item) bool {
 		return false
 	}
 	p.Inventory.Grid[y][x] = it
+	if it.Usable {
+		p.AssignBelt(it.ID)
+	}
+	return true
+}
+
+// AssignBelt puts the item with id in the first free belt slot. A slot
+// whose item is no longer carried counts as free. It returns false if the
+// item is already on the belt or the belt is full.
+func (p *Player) AssignBelt(id string) bool {
+	free := -1
+	for i, b := range p.Belt {
+		if b == id {
+			return false
+		}
+		if (b == "" || p.Inventory == nil || !p.Inventory.HasItem(b)) && free < 0 {
+			free = i
+		}
+	}
+	if free < 0 {
+		return false
+	}
+	p.Belt[free] = id
+	return true
+}
+
+// BeltItem returns the inventory stack behind belt slot i, or nil if the
+// slot is empty or none are left.
+func (p *Player) BeltItem(i int) *items.Item {
+	if i < 0 || i >= len(p.Belt) || p.Belt[i] == "" || p.Inventory == nil {
+		return nil
+	}
+	for y := 0; y < p.Inventory.Height; y++ {
+		for x := 0; x < p.Inventory.Width; x++ {
+			if it := p.Inventory.Grid[y][x]; it != nil && it.ID == p.Belt[i] {
+				return it
+			}
+		}
+	}
+	return nil
+}
+
+// ConsumeItem uses up one item with id from the inventory, clearing its belt
+// slot once the last one is gone. It returns false if there was none.
+func (p *Player) ConsumeItem(id string) bool {
+	if p.Inventory == nil || !p.Inventory.RemoveItem(id) {
+		return false
+	}
+	if !p.Inventory.HasItem(id) {
+		for i, b := range p.Belt {
+			if b == id {
+				p.Belt[i] = ""
+			}
+		}
+	}
 	return true
 }
@@ -44,6 +44,10 @@ type Monster struct {
 	RecalcCooldown   int
 	PathTargetX      int // player position when path was computed
 	PathTargetY      int
+	Flow             *pathing.FlowField // shared field toward the player; set by the game each tick
+	Doors            DoorPolicy         // how it gets past closed doors
+	PendingDoors     []DoorRequest      // doors to open or bash, processed by the game loop
+	doorTick         int                // ticks since the last blow on a door
 
 	// Combat
 	Behavior       MonsterBehavior
@@ -66,8 +70,12 @@ type Monster struct {
 	Siblings           []*Monster           // for swarm coordination
 	PendingProjectiles []*MonsterProjectile // ranged attacks to be processed by game loop
 	PendingSpells      []PendingSpellCast   // spell casts to be processed by game loop
+	PendingHits        []int                // melee damage dealt to the player, for the game's item effects
 	Effects            EffectHolder         // active buffs/debuffs
 	OnHitEffect        *StatusEffect        // if non-nil, applied to player on melee hit
+	Perception         Perception           // what the monster knows of the player
+	Affixes            []Affix              // elite affixes rolled at spawn
+	affix              affixState
 }
 
 const (
@@ -122,6 +130,10 @@ func (m *Monster) Update(player *Player, level *levels.Level) {
 			m.IsDead = true
 		}
 	})
+	m.updateAffixes(player, level)
+	if s, ok := m.Behavior.(sensing); ok {
+		m.perceive(player, level, s.sightRadius())
+	}
 	if m.Behavior != nil {
 		m.Behavior.Update(m, player, level)
 	}
@@ -132,7 +144,7 @@ func (m *Monster) Update(player *Player, level *levels.Level) {
 func (m *Monster) UpdateMovement() {
 	if m.Moving {
 		m.InterpTicks++
-		t := float64(m.InterpTicks) / float64(m.MovementDuration)
+		t := float64(m.InterpTicks) * m.Effects.SpeedModifier() / float64(m.MovementDuration)
 		if t > 1 {
 			t = 1
 		}
@@ -160,7 +172,7 @@ func (m *Monster) BasicChaseLogic(p *Player, level *levels.Level) {
 	// Smooth interpolation update
 	if m.Moving {
 		m.InterpTicks++
-		t := float64(m.InterpTicks) / float64(m.MovementDuration)
+		t := float64(m.InterpTicks) * m.Effects.SpeedModifier() / float64(m.MovementDuration)
 		if t > 1 {
 			t = 1
 		}
@@ -177,6 +189,13 @@ func (m *Monster) BasicChaseLogic(p *Player, level *levels.Level) {
 		return
 	}
 
+	// Follow the shared flow field when it leads to the player; the game
+	// keeps it current, so there is no path to recompute.
+	if m.followFlow(p, level) {
+		m.Path = nil
+		return
+	}
+
 	if m.RecalcCooldown > 0 {
 		m.RecalcCooldown--
 	}
@@ -191,7 +210,7 @@ func (m *Monster) BasicChaseLogic(p *Player, level *levels.Level) {
 	}
 
 	needRecalc := len(m.Path) == 0 ||
-		!level.IsWalkable(m.Path[0].X, m.Path[0].Y) ||
+		!m.walkable(level, m.Path[0].X, m.Path[0].Y) ||
 		(playerMoved && m.RecalcCooldown <= 0)
 
 	if needRecalc {
@@ -206,7 +225,7 @@ func (m *Monster) BasicChaseLogic(p *Player, level *levels.Level) {
 		found := false
 		for _, target := range adjTargets {
 			if level.IsWalkable(target.X, target.Y) {
-				m.Path = pathing.AStar(level, m.TileX, m.TileY, target.X, target.Y)
+				m.Path = m.pathTo(level, target.X, target.Y)
 				found = true
 				break
 			}
@@ -223,15 +242,7 @@ func (m *Monster) BasicChaseLogic(p *Player, level *levels.Level) {
 		}
 	}
 	// Move to next tile
-	if len(m.Path) > 0 {
-		next := m.Path[0]
-		if level.IsWalkable(next.X, next.Y) {
-			m.MoveTo(next.X, next.Y)
-			m.Path = m.Path[1:]
-		} else {
-			m.Path = nil
-		}
-	}
+	m.followPath(level)
 }
 
 func (m *Monster) MoveTo(x, y int) {
@@ -255,9 +266,11 @@ func (m *Monster) CombatCheck(player *Player) {
 		!m.Moving &&
 		IsAdjacent(m.TileX, m.TileY, player.TileX, player.TileY) {
 		m.AttackTick++
-		if m.AttackTick >= m.AttackRate {
+		if m.AttackTick >= m.attackRate() {
 			dmg := int(float64(m.Damage) * m.Effects.DamageModifier())
 			player.TakeDamage(dmg)
+			m.PendingHits = append(m.PendingHits, dmg)
+			m.leech(dmg)
 			// Apply on-hit status effect to the player if defined.
 			if m.OnHitEffect != nil {
 				clone := *m.OnHitEffect
@@ -295,6 +308,9 @@ func (m *Monster) Draw(screen *ebiten.Image, tileSize int, camX, camY, camScale,
 	op.GeoM.Scale(camScale, camScale)
 	op.GeoM.Translate(cx, cy)
 
+	if r, g, b, ok := m.AffixTint(); ok {
+		op.ColorScale.Scale(r, g, b, 1)
+	}
 	if m.FlashTicksLeft > 0 {
 		op.ColorScale.Scale(1, 1, 1, 0.7) // Brighter flash
 	}
@@ -335,6 +351,7 @@ func (m *Monster) UpdateFlashStatus() {
 }
 
 func (m *Monster) TakeDamage(dmg int, markers *[]HitMarker, damageNumbers *[]DamageNumber) bool {
+	dmg = m.Effects.AbsorbDamage(dmg)
 	m.HP -= dmg
 	if m.HP <= 0 {
 		m.IsDead = true
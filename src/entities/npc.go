@@ -33,6 +33,7 @@ type NPC struct {
 	IsMajor       bool
 	Phase         int
 	DialogueID    string
+	Merchant      bool // opens the shop instead of a dialogue
 	Interactable  bool
 	InteractRange float64 // tile distance for interaction (default 1.5)
 
@@ -1,9 +1,6 @@
 package entities
 
-import (
-	"dungeoneer/levels"
-	"dungeoneer/pathing"
-)
+import "dungeoneer/levels"
 
 // PatrolWaypoint is a position in a patrol route.
 type PatrolWaypoint struct {
@@ -29,22 +26,21 @@ func NewPatrolBehavior(triggerRadius int) *PatrolBehavior {
 	}
 }
 
+func (pb *PatrolBehavior) sightRadius() int { return pb.TriggerRadius }
+
 func (pb *PatrolBehavior) Update(m *Monster, p *Player, level *levels.Level) {
 	if m.IsDead || m.Moving {
 		return
 	}
 
-	// Trigger check.
-	dx := m.TileX - p.TileX
-	dy := m.TileY - p.TileY
-	if !pb.Triggered && dx*dx+dy*dy <= pb.TriggerRadius*pb.TriggerRadius {
-		pb.Triggered = true
-	}
-
+	pb.Triggered = m.Noticed()
 	if pb.Triggered {
 		m.BasicChaseLogic(p, level)
 		return
 	}
+	if m.Investigating() {
+		return
+	}
 
 	// Patrol between waypoints.
 	if len(pb.Waypoints) == 0 {
@@ -64,21 +60,12 @@ func (pb *PatrolBehavior) Update(m *Monster, p *Player, level *levels.Level) {
 
 	// Walk toward current waypoint.
 	if len(m.Path) == 0 || m.RecalcCooldown <= 0 {
-		m.Path = pathing.AStar(level, m.TileX, m.TileY, wp.X, wp.Y)
+		m.Path = m.pathTo(level, wp.X, wp.Y)
 		m.RecalcCooldown = 30
 		if len(m.Path) > 0 && m.Path[0].X == m.TileX && m.Path[0].Y == m.TileY {
 			m.Path = m.Path[1:]
 		}
 	}
 	m.RecalcCooldown--
-
-	if len(m.Path) > 0 {
-		next := m.Path[0]
-		if level.IsWalkable(next.X, next.Y) {
-			m.MoveTo(next.X, next.Y)
-			m.Path = m.Path[1:]
-		} else {
-			m.Path = nil
-		}
-	}
+	m.followPath(level)
 }
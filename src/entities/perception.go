@@ -0,0 +1,228 @@
+package entities
+
+import (
+	"dungeoneer/fov"
+	"dungeoneer/levels"
+	"math"
+)
+
+// Awareness is how much a monster knows about the player.
+type Awareness uint8
+
+const (
+	AwarenessIdle       Awareness = iota // hasn't noticed anything
+	AwarenessSuspicious                  // glimpsed or heard something; stands and looks
+	AwarenessAlert                       // knows where the player is and engages
+	AwarenessSearching                   // lost the player; checks where they were last
+)
+
+const (
+	// Suspicion gained per tick while the player is in sight, from right next
+	// to the monster down to the edge of its sight. A player crossing the
+	// edge of a monster's view gets a couple of seconds to duck out of it.
+	sightGainNear = 1.0 / 15
+	sightGainFar  = 1.0 / 120
+	// suspicionDecay is lost per tick while nothing is sensed.
+	suspicionDecay = 1.0 / 240
+	// loseSightTicks is how long an alert monster keeps chasing after the
+	// player breaks line of sight before it goes to search for them.
+	loseSightTicks = 180
+	// searchTicks is how long a search lasts before the monster gives up.
+	searchTicks = 360
+	// lookAroundTicks is how often a searching monster turns on the spot.
+	lookAroundTicks = 45
+)
+
+// Perception is a monster's idle → suspicious → alert → searching state.
+type Perception struct {
+	State        Awareness
+	Suspicion    float64 // 0..1; the monster turns alert at 1
+	LastX, LastY int     // where the player was last seen or heard
+	Timer        int     // ticks since the player was last sensed
+}
+
+// sensing is implemented by behaviors whose monsters have to notice the
+// player before engaging them.
+type sensing interface {
+	sightRadius() int
+}
+
+// Senses reports whether m has to notice the player before engaging them.
+// Bosses and other always-engaged monsters don't.
+func (m *Monster) Senses() bool {
+	_, ok := m.Behavior.(sensing)
+	return ok
+}
+
+// Noticed reports whether m is alert to the player.
+func (m *Monster) Noticed() bool {
+	return m.Perception.State == AwarenessAlert
+}
+
+// Investigating reports whether m is suspicious or searching. Perception is
+// then in charge of m and a behavior should skip its idle routine.
+func (m *Monster) Investigating() bool {
+	s := m.Perception.State
+	return s == AwarenessSuspicious || s == AwarenessSearching
+}
+
+// Alert makes m alert with the player last known at (x, y).
+func (m *Monster) Alert(x, y int) {
+	pc := &m.Perception
+	pc.State = AwarenessAlert
+	pc.Suspicion = 1
+	pc.LastX, pc.LastY = x, y
+	pc.Timer = 0
+}
+
+// Hear tells m about a noise at (x, y). loudness is the suspicion it adds;
+// 1 or more alerts m outright. An alert monster just keeps chasing and a
+// searching one goes to look at the new spot.
+func (m *Monster) Hear(x, y int, loudness float64) {
+	if m.IsDead || !m.Senses() {
+		return
+	}
+	pc := &m.Perception
+	switch pc.State {
+	case AwarenessAlert:
+		pc.LastX, pc.LastY = x, y
+		pc.Timer = 0
+	case AwarenessSearching:
+		pc.LastX, pc.LastY = x, y
+		pc.Timer = 0
+		m.Path = nil
+	default:
+		pc.Suspicion += loudness
+		if pc.Suspicion >= 1 {
+			m.Alert(x, y)
+			return
+		}
+		pc.State = AwarenessSuspicious
+		pc.LastX, pc.LastY = x, y
+		pc.Timer = 0
+		m.faceToward(x)
+	}
+}
+
+// CanSee reports whether the player is within sight tiles of m and in line
+// of sight. Alert and searching monsters look further, and a player in a
+// dark room is only seen at half the distance.
+func (m *Monster) CanSee(p *Player, level *levels.Level, sight int) bool {
+	if p == nil || p.IsDead || level == nil {
+		return false
+	}
+	sight = m.sightRange(p, level, sight)
+	dx, dy := p.TileX-m.TileX, p.TileY-m.TileY
+	if dx*dx+dy*dy > sight*sight {
+		return false
+	}
+	return fov.HasLineOfSight(level, m.TileX, m.TileY, p.TileX, p.TileY)
+}
+
+func (m *Monster) sightRange(p *Player, level *levels.Level, sight int) int {
+	if s := m.Perception.State; s == AwarenessAlert || s == AwarenessSearching {
+		sight = sight * 3 / 2
+	}
+	if r := level.RoomAt(p.TileX, p.TileY); r != nil && r.HasTag(levels.TagDark) {
+		sight /= 2
+	}
+	return max(sight, 1)
+}
+
+// perceive runs m's senses for one tick. sight is the behavior's trigger
+// radius.
+func (m *Monster) perceive(p *Player, level *levels.Level, sight int) {
+	if m.IsDead {
+		return
+	}
+	pc := &m.Perception
+	sees := m.CanSee(p, level, sight)
+	if sees {
+		pc.LastX, pc.LastY = p.TileX, p.TileY
+		pc.Timer = 0
+	} else {
+		pc.Timer++
+	}
+
+	switch pc.State {
+	case AwarenessIdle, AwarenessSuspicious:
+		if sees {
+			pc.Suspicion += m.sightGain(p, level, sight)
+			if pc.Suspicion >= 1 {
+				m.Alert(p.TileX, p.TileY)
+				return
+			}
+			pc.State = AwarenessSuspicious
+		} else {
+			pc.Suspicion -= suspicionDecay
+			if pc.Suspicion <= 0 {
+				pc.Suspicion = 0
+				pc.State = AwarenessIdle
+			}
+		}
+		if pc.State == AwarenessSuspicious && !m.Moving {
+			m.faceToward(pc.LastX)
+		}
+	case AwarenessAlert:
+		if pc.Timer >= loseSightTicks {
+			pc.State = AwarenessSearching
+			pc.Timer = 0
+			m.Path = nil
+		}
+	case AwarenessSearching:
+		if sees {
+			m.Alert(p.TileX, p.TileY)
+			return
+		}
+		if pc.Timer >= searchTicks {
+			*pc = Perception{}
+			m.Path = nil
+			return
+		}
+		m.search(level)
+	}
+}
+
+// sightGain is the suspicion m gains this tick from seeing the player, more
+// the closer they are.
+func (m *Monster) sightGain(p *Player, level *levels.Level, sight int) float64 {
+	sight = m.sightRange(p, level, sight)
+	dist := math.Hypot(float64(p.TileX-m.TileX), float64(p.TileY-m.TileY))
+	closeness := math.Max(0, 1-dist/float64(sight))
+	return sightGainFar + (sightGainNear-sightGainFar)*closeness
+}
+
+// search walks m to where it last sensed the player, then has it look
+// around until the search runs out.
+func (m *Monster) search(level *levels.Level) {
+	if m.Moving {
+		return
+	}
+	pc := &m.Perception
+	if m.TileX == pc.LastX && m.TileY == pc.LastY {
+		if pc.Timer%lookAroundTicks == 0 {
+			m.LeftFacing = !m.LeftFacing
+		}
+		return
+	}
+	if len(m.Path) == 0 || m.RecalcCooldown <= 0 {
+		m.Path = m.pathTo(level, pc.LastX, pc.LastY)
+		m.RecalcCooldown = 30
+		if len(m.Path) == 0 {
+			// Nowhere to go; search from here.
+			pc.LastX, pc.LastY = m.TileX, m.TileY
+			return
+		}
+	}
+	m.RecalcCooldown--
+	m.followPath(level)
+}
+
+// faceToward turns m's sprite toward tile column x.
+func (m *Monster) faceToward(x int) {
+	if x < m.TileX {
+		m.LeftFacing = true
+	} else if x > m.TileX {
+		m.LeftFacing = false
+	}
+}
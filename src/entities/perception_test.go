@@ -0,0 +1,86 @@
+package entities
+
+import (
+	"testing"
+
+	"dungeoneer/levels"
+)
+
+// openRoom returns a w×h level of walkable floor ringed by walls.
+func openRoom(w, h int) *levels.Level {
+	l := levels.NewEmptyLevel(w, h)
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			l.Tiles[y][x].IsWalkable = true
+		}
+	}
+	return l
+}
+
+func newSentry(x, y int) *Monster {
+	return &Monster{
+		Name: "Sentry", TileX: x, TileY: y,
+		InterpX: float64(x), InterpY: float64(y),
+		HP: 5, MaxHP: 5, MovementDuration: 10, AttackRate: 30,
+		Behavior: NewAmbushBehavior(6),
+	}
+}
+
+func TestMonsterNoticesPlayerInSight(t *testing.T) {
+	l := openRoom(16, 5)
+	m := newSentry(2, 2)
+	p := &Player{TileX: 6, TileY: 2}
+
+	m.Update(p, l)
+	if m.Perception.State != AwarenessSuspicious {
+		t.Fatalf("after one tick in sight: state %d, want suspicious", m.Perception.State)
+	}
+	for i := 0; i < 120 && !m.Noticed(); i++ {
+		m.Update(p, l)
+	}
+	if !m.Noticed() {
+		t.Fatal("monster never noticed a player standing in plain sight")
+	}
+}
+
+func TestWallsHidePlayer(t *testing.T) {
+	l := openRoom(16, 5)
+	for y := 0; y < l.H; y++ {
+		l.Tiles[y][4].IsWalkable = false
+	}
+	m := newSentry(2, 2)
+	p := &Player{TileX: 6, TileY: 2}
+
+	for i := 0; i < 300; i++ {
+		m.Update(p, l)
+	}
+	if m.Perception.State != AwarenessIdle {
+		t.Fatalf("monster sensed a player behind a wall: state %d", m.Perception.State)
+	}
+}
+
+func TestLostPlayerIsSearchedForThenForgotten(t *testing.T) {
+	l := openRoom(16, 5)
+	m := newSentry(2, 2)
+	p := &Player{TileX: 14, TileY: 3, IsDead: true} // out of sight for good
+
+	m.Hear(8, 2, 1)
+	if !m.Noticed() {
+		t.Fatal("a loud noise did not alert the monster")
+	}
+	for i := 0; i < loseSightTicks; i++ {
+		m.Update(p, l)
+	}
+	if m.Perception.State != AwarenessSearching {
+		t.Fatalf("state %d after losing the player, want searching", m.Perception.State)
+	}
+	for i := 0; i < searchTicks && m.Perception.State == AwarenessSearching; i++ {
+		m.Update(p, l)
+	}
+	if m.TileX != 8 || m.TileY != 2 {
+		t.Fatalf("search ended at (%d,%d), want the noise at (8,2)", m.TileX, m.TileY)
+	}
+	if m.Perception.State != AwarenessIdle {
+		t.Fatalf("state %d after the search ran out, want idle", m.Perception.State)
+	}
+}
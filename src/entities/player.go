@@ -101,6 +101,10 @@ type Player struct {
 	SpellSlots []string
 	Abilities  map[string]bool
 
+	// Belt holds the item IDs on the quick-use belt, keys 7-0. The items
+	// themselves stay in the inventory; an empty slot is "".
+	Belt [constants.BeltSlots]string
+
 	// Melee combo state.
 	ComboHit   int     // current combo hit (0, 1, 2)
 	ComboTimer float64 // time remaining in combo window (resets on hit)
@@ -333,9 +337,12 @@ func (p *Player) Update(level *levels.Level, dt float64) {
 		}
 	}
 
+	// Slow and haste scale how far the player moves this frame.
+	speedMod := p.Effects.SpeedModifier()
+
 	// For pathing, let the controller interpolate positions
 	if p.MoveController.Mode == movement.PathingMode {
-		p.MoveController.Update(dt)
+		p.MoveController.Update(dt * speedMod)
 	}
 
 	// If PathingMode, check validity of next node and flip sprite direction
@@ -355,8 +362,8 @@ func (p *Player) Update(level *levels.Level, dt float64) {
 
 	// If moving by velocity, resolve collisions each frame
 	if p.MoveController.Mode == movement.VelocityMode {
-		vx := p.MoveController.VelocityX * dt
-		vy := p.MoveController.VelocityY * dt
+		vx := p.MoveController.VelocityX * dt * speedMod
+		vy := p.MoveController.VelocityY * dt * speedMod
 
 		// Clamp displacement to avoid tunneling
 		maxStep := 0.25
@@ -531,30 +538,68 @@ func (p *Player) TakeDamage(dmg int) {
 	}
 }
 
-// getEquipmentStatModifiers sums stat bonuses from equipped items.
+// getEquipmentStatModifiers sums stat bonuses from equipped items, their
+// rolled affixes and the set bonuses they unlock.
 func (p *Player) getEquipmentStatModifiers() StatModifiers {
 	mod := StatModifiers{}
 	for _, it := range p.Equipment {
 		if it == nil {
 			continue
 		}
-		if v, ok := it.Stats["Strength"]; ok {
-			mod.StrengthMod += v
-		}
-		if v, ok := it.Stats["Dexterity"]; ok {
-			mod.DexterityMod += v
-		}
-		if v, ok := it.Stats["Vitality"]; ok {
-			mod.VitalityMod += v
-		}
-		if v, ok := it.Stats["Intelligence"]; ok {
-			mod.IntelligenceMod += v
+		mod.add(it.Stats)
+		mod.add(it.AffixStats())
+	}
+	for _, b := range p.SetBonuses() {
+		mod.add(b.Stats)
+	}
+	return mod
+}
+
+// add sums stats, keyed as on ItemTemplate.Stats, into mod.
+func (mod *StatModifiers) add(stats map[string]int) {
+	if v, ok := stats["Strength"]; ok {
+		mod.StrengthMod += v
+	}
+	if v, ok := stats["Dexterity"]; ok {
+		mod.DexterityMod += v
+	}
+	if v, ok := stats["Vitality"]; ok {
+		mod.VitalityMod += v
+	}
+	if v, ok := stats["Intelligence"]; ok {
+		mod.IntelligenceMod += v
+	}
+	if v, ok := stats["Luck"]; ok {
+		mod.LuckMod += v
+	}
+}
+
+// SetBonuses returns the set bonuses unlocked by the items the player is
+// wearing.
+func (p *Player) SetBonuses() []items.SetBonus {
+	worn := p.EquippedItems()
+	ids := make([]string, len(worn))
+	for i, it := range worn {
+		ids[i] = it.ID
+	}
+	return items.ActiveSetBonuses(ids)
+}
+
+// ItemEffects returns the procs of the player's equipped items followed by
+// those of unlocked set bonuses.
+func (p *Player) ItemEffects() []*items.ItemEffect {
+	var effs []*items.ItemEffect
+	for _, it := range p.EquippedItems() {
+		if it.Effect != nil {
+			effs = append(effs, it.Effect)
 		}
-		if v, ok := it.Stats["Luck"]; ok {
-			mod.LuckMod += v
+	}
+	for _, b := range p.SetBonuses() {
+		if b.Effect != nil {
+			effs = append(effs, b.Effect)
 		}
 	}
-	return mod
+	return effs
 }
 
 // EffectiveStats returns the player's stats including equipment and temporary modifiers.
@@ -600,12 +645,45 @@ func (p *Player) HasAbility(id string) bool {
 	return p.Abilities[id]
 }
 
-// RefreshAbilities rebuilds SpellSlots and Abilities from currently equipped items.
+// RefreshAbilities rebuilds SpellSlots and Abilities from currently equipped
+// items and the set bonuses they unlock.
 // Call this whenever equipment changes.
 func (p *Player) RefreshAbilities() {
 	p.Abilities = map[string]bool{}
 	p.SpellSlots = nil
 
+	for _, it := range p.EquippedItems() {
+		p.grantAbility(it.GrantsAbility, it.AbilitySlot)
+	}
+	for _, b := range p.SetBonuses() {
+		p.grantAbility(b.GrantsAbility, b.AbilitySlot)
+	}
+}
+
+// grantAbility gives the player ability id, adding spells to the spell bar.
+func (p *Player) grantAbility(id string, slot items.AbilitySlotType) {
+	if id == "" {
+		return
+	}
+	p.Abilities[id] = true
+	if slot == items.AbilitySlotSpell {
+		// Add to spell bar if not already present (prevent duplicates).
+		found := false
+		for _, s := range p.SpellSlots {
+			if s == id {
+				found = true
+				break
+			}
+		}
+		if !found && len(p.SpellSlots) < 6 {
+			p.SpellSlots = append(p.SpellSlots, id)
+		}
+	}
+}
+
+// EquippedItems returns the items the player is wearing, in canonical slot
+// order followed by any extra slots sorted by name.
+func (p *Player) EquippedItems() []*items.Item {
 	slots := make([]string, 0, len(equipmentSlotOrder))
 	seen := make(map[string]bool, len(equipmentSlotOrder))
 	for _, slot := range equipmentSlotOrder {
@@ -621,26 +699,13 @@ func (p *Player) RefreshAbilities() {
 	sort.Strings(extra)
 	slots = append(slots, extra...)
 
+	var worn []*items.Item
 	for _, slot := range slots {
-		it := p.Equipment[slot]
-		if it == nil || it.GrantsAbility == "" {
-			continue
-		}
-		p.Abilities[it.GrantsAbility] = true
-		if it.AbilitySlot == items.AbilitySlotSpell {
-			// Add to spell bar if not already present (prevent duplicates).
-			found := false
-			for _, s := range p.SpellSlots {
-				if s == it.GrantsAbility {
-					found = true
-					break
-				}
-			}
-			if !found && len(p.SpellSlots) < 6 {
-				p.SpellSlots = append(p.SpellSlots, it.GrantsAbility)
-			}
+		if it := p.Equipment[slot]; it != nil {
+			worn = append(worn, it)
 		}
 	}
+	return worn
 }
 
 // ClearAbilities removes all learned abilities and spell slots.
@@ -25,6 +25,7 @@ type PlayerSave struct {
 	EXP       int                       `json:"exp"`
 	Points    int                       `json:"points"`
 	Gold      int                       `json:"gold"`
+	Belt      []string                  `json:"belt,omitempty"`
 }
 
 // ToSaveData converts the player to a serializable form.
@@ -43,6 +44,7 @@ func (p *Player) ToSaveData() PlayerSave {
 		Gold:      p.Gold,
 		Inventory: p.Inventory.ToSaveData(),
 		Equipment: items.SerializeEquipment(p.Equipment),
+		Belt:      p.Belt[:],
 	}
 }
 
@@ -88,6 +90,8 @@ func LoadPlayer(data PlayerSave) *Player {
 		Caster:         spells.NewCaster(),
 	}
 
+	copy(p.Belt[:], data.Belt)
+
 	mc.OnStep = func(x, y int) {
 		p.TileX = x
 		p.TileY = y
@@ -0,0 +1,74 @@
+package entities
+
+import (
+	"testing"
+
+	"dungeoneer/items"
+)
+
+func TestSetBonusesFollowPiecesWorn(t *testing.T) {
+	for _, id := range []string{"test_helm", "test_boots", "test_ring"} {
+		items.RegisterItem(&items.ItemTemplate{ID: id, Name: id, Equippable: true})
+	}
+	items.RegisterSet(&items.ItemSet{
+		ID: "test_set", Name: "Test Set",
+		Members: []string{"test_helm", "test_boots", "test_ring"},
+		Bonuses: []items.SetBonus{
+			{Pieces: 2, Stats: map[string]int{"Vitality": 4}},
+			{Pieces: 3, GrantsAbility: "fireball", AbilitySlot: items.AbilitySlotSpell,
+				Effect: &items.ItemEffect{Trigger: items.TriggerOnHit, Type: items.EffectBurn, MagnitudePct: 50}},
+		},
+	})
+	defer delete(items.Sets, "test_set")
+
+	p := &Player{Equipment: NewEquipmentSlots()}
+	refresh := func() {
+		p.RecalculateStats()
+		p.RefreshAbilities()
+	}
+	refresh()
+	baseHP := p.MaxHP
+
+	p.Equipment["Head"] = items.NewItem("test_helm")
+	p.Equipment["Ring1"] = items.NewItem("test_helm") // a second copy is not a second piece
+	refresh()
+	if p.MaxHP != baseHP {
+		t.Fatalf("one distinct piece raised MaxHP to %d", p.MaxHP)
+	}
+
+	p.Equipment["Feet"] = items.NewItem("test_boots")
+	refresh()
+	if p.MaxHP != baseHP+4*5 {
+		t.Fatalf("MaxHP with two pieces = %d, want %d", p.MaxHP, baseHP+20)
+	}
+	if p.HasAbility("fireball") || len(p.ItemEffects()) != 0 {
+		t.Fatal("the three-piece bonus came on with two pieces")
+	}
+
+	p.Equipment["Ring2"] = items.NewItem("test_ring")
+	refresh()
+	if !p.HasAbility("fireball") || len(p.SpellSlots) != 1 {
+		t.Fatalf("full set did not grant its spell: slots %v", p.SpellSlots)
+	}
+	if effs := p.ItemEffects(); len(effs) != 1 || effs[0].Type != items.EffectBurn {
+		t.Fatalf("full set procs = %v, want the burn", effs)
+	}
+
+	p.Equipment["Feet"] = nil
+	refresh()
+	if p.HasAbility("fireball") || p.MaxHP != baseHP+20 {
+		t.Fatal("taking a piece off did not drop the three-piece bonus")
+	}
+}
+
+func TestRolledAffixesAddToStats(t *testing.T) {
+	items.RegisterItem(&items.ItemTemplate{ID: "test_band", Name: "Band", Equippable: true, Stats: map[string]int{"Vitality": 1}})
+	p := &Player{Equipment: NewEquipmentSlots()}
+	band := items.NewItem("test_band")
+	band.Affixes = []items.ItemAffix{{ID: "sturdy", Value: 2}, {ID: "of_the_ox", Value: 3}}
+	p.Equipment["Ring1"] = band
+
+	if got := p.EffectiveStats(); got.Vitality != 3 || got.Strength != 3 {
+		t.Fatalf("stats with a rolled band = %+v, want Vitality 3 and Strength 3", got)
+	}
+}
@@ -26,6 +26,8 @@ func NewRangedBehavior(triggerRadius int) *RangedBehavior {
 	}
 }
 
+func (r *RangedBehavior) sightRadius() int { return r.TriggerRadius }
+
 func (r *RangedBehavior) Update(m *Monster, p *Player, level *levels.Level) {
 	if m.IsDead || m.Moving {
 		return
@@ -36,13 +38,9 @@ func (r *RangedBehavior) Update(m *Monster, p *Player, level *levels.Level) {
 	distSq := dx*dx + dy*dy
 	dist := math.Sqrt(float64(distSq))
 
-	// Trigger check.
+	r.Triggered = m.Noticed()
 	if !r.Triggered {
-		if distSq <= r.TriggerRadius*r.TriggerRadius {
-			r.Triggered = true
-		} else {
-			return
-		}
+		return
 	}
 
 	r.ShootCounter++
@@ -2,8 +2,8 @@ package entities
 
 import (
 	"dungeoneer/levels"
+	"dungeoneer/simrand"
 	"dungeoneer/sprites"
-	"math/rand"
 )
 
 type RoamingWanderBehavior struct {
@@ -44,17 +44,17 @@ func NewRoamingMonster(ss *sprites.SpriteSheet) []*Monster {
 	}
 }
 
+func (r *RoamingWanderBehavior) sightRadius() int { return r.TriggerRadius }
+
 func (r *RoamingWanderBehavior) Update(m *Monster, p *Player, level *levels.Level) {
 	if m.IsDead || m.Moving {
 		return
 	}
 
+	r.Triggered = m.Noticed()
 	if !r.Triggered {
-		dx := m.TileX - p.TileX
-		dy := m.TileY - p.TileY
-		if dx*dx+dy*dy <= r.TriggerRadius*r.TriggerRadius {
-			r.Triggered = true
-			return // wait 1 frame before chasing
+		if m.Investigating() {
+			return
 		}
 
 		// Roaming logic
@@ -67,7 +67,7 @@ func (r *RoamingWanderBehavior) Update(m *Monster, p *Player, level *levels.Leve
 		directions := []struct{ X, Y int }{
 			{0, -1}, {0, 1}, {-1, 0}, {1, 0},
 		}
-		rand.Shuffle(len(directions), func(i, j int) {
+		simrand.Shuffle(len(directions), func(i, j int) {
 			directions[i], directions[j] = directions[j], directions[i]
 		})
 		for _, d := range directions {
@@ -2,8 +2,8 @@ package entities
 
 import (
 	"dungeoneer/levels"
+	"dungeoneer/simrand"
 	"math"
-	"math/rand/v2"
 )
 
 // SwarmBehavior makes monsters cluster together and attack as a group.
@@ -20,6 +20,8 @@ func NewSwarmBehavior(triggerRadius int) *SwarmBehavior {
 	}
 }
 
+func (s *SwarmBehavior) sightRadius() int { return s.TriggerRadius }
+
 func (s *SwarmBehavior) Update(m *Monster, p *Player, level *levels.Level) {
 	if m.IsDead || m.Moving {
 		return
@@ -29,21 +31,15 @@ func (s *SwarmBehavior) Update(m *Monster, p *Player, level *levels.Level) {
 	m.AttackTick++
 	m.BobOffset = math.Sin(float64(m.TickCount)*0.15) * 1.5
 
-	dx := m.TileX - p.TileX
-	dy := m.TileY - p.TileY
-
-	// Self-trigger on proximity.
-	if !s.Triggered && dx*dx+dy*dy <= s.TriggerRadius*s.TriggerRadius {
-		s.Triggered = true
-	}
-
-	// Propagate trigger to siblings.
+	// Trigger on noticing the player, or when a sibling has.
+	s.Triggered = m.Noticed()
 	if !s.Triggered {
 		for _, sib := range m.Siblings {
-			if sib.IsDead {
+			if sib.IsDead || !sib.Noticed() {
 				continue
 			}
-			if sb, ok := sib.Behavior.(*SwarmBehavior); ok && sb.Triggered {
+			if _, ok := sib.Behavior.(*SwarmBehavior); ok {
+				m.Alert(sib.Perception.LastX, sib.Perception.LastY)
 				s.Triggered = true
 				break
 			}
@@ -51,6 +47,9 @@ func (s *SwarmBehavior) Update(m *Monster, p *Player, level *levels.Level) {
 	}
 
 	if !s.Triggered {
+		if m.Investigating() {
+			return
+		}
 		// Wander slowly near group centroid.
 		s.WanderCounter++
 		if s.WanderCounter < 15 {
@@ -61,15 +60,19 @@ func (s *SwarmBehavior) Update(m *Monster, p *Player, level *levels.Level) {
 		return
 	}
 
-	// Triggered: chase player, biased toward siblings.
-	s.chaseWithGroupBias(m, p, level)
+	// Triggered: chase the player down the flow field, spreading out so
+	// siblings don't stack; without a field, step greedily toward the
+	// player with a bias toward siblings.
+	if !m.followFlow(p, level) {
+		s.chaseWithGroupBias(m, p, level)
+	}
 	m.CombatCheck(p)
 }
 
 // wanderNearGroup moves randomly but stays close to living siblings.
 func (s *SwarmBehavior) wanderNearGroup(m *Monster, level *levels.Level) {
 	dirs := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
-	rand.Shuffle(len(dirs), func(i, j int) { dirs[i], dirs[j] = dirs[j], dirs[i] })
+	simrand.Shuffle(len(dirs), func(i, j int) { dirs[i], dirs[j] = dirs[j], dirs[i] })
 	for _, d := range dirs {
 		nx, ny := m.TileX+d[0], m.TileY+d[1]
 		if level.IsWalkable(nx, ny) {
@@ -80,7 +83,8 @@ func (s *SwarmBehavior) wanderNearGroup(m *Monster, level *levels.Level) {
 }
 
 // chaseWithGroupBias picks the adjacent tile that minimises distance to the
-// player while keeping the monster within a few tiles of siblings.
+// player while keeping the monster within a few tiles of siblings. Closed
+// doors are dealt with as the monster's DoorPolicy says.
 func (s *SwarmBehavior) chaseWithGroupBias(m *Monster, p *Player, level *levels.Level) {
 	// Compute centroid of living siblings.
 	cx, cy := float64(m.TileX), float64(m.TileY)
@@ -100,7 +104,7 @@ func (s *SwarmBehavior) chaseWithGroupBias(m *Monster, p *Player, level *levels.
 	bestX, bestY := m.TileX, m.TileY
 	for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
 		nx, ny := m.TileX+d[0], m.TileY+d[1]
-		if !level.IsWalkable(nx, ny) {
+		if !m.walkable(level, nx, ny) {
 			continue
 		}
 		// Weighted score: mostly player distance, slight bias toward centroid.
@@ -114,6 +118,6 @@ func (s *SwarmBehavior) chaseWithGroupBias(m *Monster, p *Player, level *levels.
 		}
 	}
 	if bestX != m.TileX || bestY != m.TileY {
-		m.MoveTo(bestX, bestY)
+		m.stepOnto(level, bestX, bestY)
 	}
 }
@@ -0,0 +1,18 @@
+package entities
+
+// Trap is a live trap on the floor, built from a levels.EntityTrap entity.
+// Traps are hidden until they go off; the game draws them once Revealed.
+type Trap struct {
+	TileX, TileY int
+	Kind         string  // levels.TrapPlate, levels.TrapDart, ...
+	Wire         int     // fires with every trap sharing it; 0 = none
+	Timer        float64 // seconds until re-armed, or until a collapsing floor gives way
+	Pressed      bool    // the player was on the tile last frame
+	Revealed     bool
+	Spent        bool // one-shot traps that have gone off
+}
+
+// Armed reports whether the trap can go off.
+func (t *Trap) Armed() bool {
+	return !t.Spent && t.Timer <= 0
+}
@@ -201,3 +201,22 @@ func TraceLineToTiles(x1, y1, x2, y2 float64) []Point {
 
 	return points
 }
+
+// HasLineOfSight reports whether the centre of tile (x2, y2) can be seen from
+// the centre of (x1, y1): no unwalkable tile lies on the line between them.
+// The end tiles themselves don't block, so a wall can be seen.
+func HasLineOfSight(level *levels.Level, x1, y1, x2, y2 int) bool {
+	pts := TraceLineToTiles(float64(x1)+0.5, float64(y1)+0.5, float64(x2)+0.5, float64(y2)+0.5)
+	for _, p := range pts {
+		if p.X == x1 && p.Y == y1 {
+			continue
+		}
+		if p.X == x2 && p.Y == y2 {
+			return true
+		}
+		if !level.IsWalkable(p.X, p.Y) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package game
+
+import (
+	"dungeoneer/entities"
+	"dungeoneer/items"
+	"dungeoneer/spells"
+	"image/color"
+	"math"
+	"math/rand/v2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	// maxAffixes caps the affixes one monster can roll.
+	maxAffixes = 3
+	// championChance is the chance, at full difficulty, that a monster other
+	// than an elite or a swarmling spawns with a single affix.
+	championChance = 0.15
+	// explosionRadius is how far from an explosive monster its death burst
+	// reaches, in tiles; the burst deals explosionDamage times its damage.
+	explosionRadius = 1.5
+	explosionDamage = 2
+)
+
+// rollAffixes picks the elite affixes for a monster of role spawning at the
+// given difficulty (0–1). Elites always get one and up to maxAffixes late in
+// a run; other monsters sometimes become champions with one. Swarms never
+// get any.
+func rollAffixes(role string, difficulty float64, rng *rand.Rand) []entities.Affix {
+	n := 0
+	switch {
+	case role == "swarm":
+		return nil
+	case role == "elite":
+		n = 1 + int(difficulty*float64(maxAffixes))
+	case rng.Float64() < championChance*difficulty:
+		n = 1
+	}
+	n = min(n, maxAffixes)
+	if n == 0 {
+		return nil
+	}
+	affixes := make([]entities.Affix, 0, n)
+	for _, i := range rng.Perm(len(entities.AffixOrder))[:n] {
+		affixes = append(affixes, entities.AffixOrder[i])
+	}
+	return affixes
+}
+
+// explodeMonster sets off an explosive monster's death burst.
+func (g *Game) explodeMonster(m *entities.Monster) {
+	info := spells.SpellInfo{Name: "fireball", Level: 1}
+	burst := spells.NewFireball(info, m.InterpX, m.InterpY, m.InterpX, m.InterpY, g.fireballSprites, g.spriteSheet.FireBurst)
+	burst.MonsterCast = true
+	burst.Impact = true
+	g.ActiveSpells = append(g.ActiveSpells, burst)
+
+	if g.player == nil || g.player.IsDead {
+		return
+	}
+	dx := g.player.MoveController.InterpX - m.InterpX
+	dy := g.player.MoveController.InterpY - m.InterpY
+	if math.Hypot(dx, dy) <= explosionRadius {
+		g.player.TakeDamage(m.Damage * explosionDamage)
+	}
+}
+
+// rollAffixLoot gives an affixed monster one extra loot roll per affix on
+// top of its usual drop.
+func (g *Game) rollAffixLoot(m *entities.Monster) {
+	if len(m.Affixes) == 0 || g.FloorCtx == nil || g.FloorCtx.BiomeConfig == nil {
+		return
+	}
+	table := g.floorLootTable()
+	for range m.Affixes {
+		result := items.RollLoot(table, g.FloorCtx.FloorNumber, g.FloorCtx.RNG.Loot)
+		if result == nil {
+			continue
+		}
+		if it := result.Item(); it != nil {
+			g.spawnDrop(m, it)
+		}
+	}
+}
+
+// drawEliteNames labels visible affixed monsters with their full name, just
+// above the health bar.
+func (g *Game) drawEliteNames(target *ebiten.Image, scale, cx, cy float64) {
+	for _, m := range g.Monsters {
+		if m.IsDead || len(m.Affixes) == 0 || !g.isTileVisible(m.TileX, m.TileY) {
+			continue
+		}
+		r, gr, b, _ := m.AffixTint()
+		clr := color.NRGBA{uint8(255 * r), uint8(255 * gr), uint8(255 * b), 255}
+		xi, yi := g.cartesianToIso(m.InterpX, m.InterpY)
+		drawX := (xi+35-g.camX)*scale + cx - float64(len(m.Name)*7)/2
+		drawY := (yi-14+g.camY)*scale + cy
+		text.Draw(target, m.Name, basicfont.Face7x13, int(drawX), int(drawY), clr)
+	}
+}
@@ -0,0 +1,32 @@
+package game
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestRollAffixesScalesWithDifficulty(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	if got := rollAffixes("swarm", 1, rng); len(got) != 0 {
+		t.Fatalf("swarm rolled affixes %v", got)
+	}
+	if got := rollAffixes("elite", 0, rng); len(got) != 1 {
+		t.Fatalf("floor-one elite rolled %d affixes, want 1", len(got))
+	}
+	got := rollAffixes("elite", 1, rng)
+	if len(got) != maxAffixes {
+		t.Fatalf("late elite rolled %d affixes, want %d", len(got), maxAffixes)
+	}
+	seen := map[string]bool{}
+	for _, a := range got {
+		if seen[string(a)] {
+			t.Fatalf("affix %q rolled twice", a)
+		}
+		seen[string(a)] = true
+	}
+	for i := 0; i < 100; i++ {
+		if got := rollAffixes("melee", 0, rng); len(got) != 0 {
+			t.Fatalf("melee rolled affixes %v at difficulty 0", got)
+		}
+	}
+}
@@ -9,38 +9,69 @@ import (
 
 // EnemyDef describes a single enemy archetype within a biome's pool.
 type EnemyDef struct {
-	ID         string // unique key, e.g. "crypt_melee"
-	Name       string // display name, e.g. "Grey Knight"
-	Role       string // "melee", "ranged", "elite", "swarm", "caster", "ambush"
-	SpriteID   string // maps to a SpriteSheet field via SpriteMap
-	BaseHP     int
-	BaseDamage int
-	BaseSpeed  int // MovementDuration in ticks (lower = faster)
-	AttackRate int // ticks between attacks
-	Behavior   string // "roaming", "ambush", "patrol", "ranged", "swarm"
+	ID         string `json:"id"`     // unique key, e.g. "crypt_melee"
+	Name       string `json:"name"`   // display name, e.g. "Grey Knight"
+	Role       string `json:"role"`   // "melee", "ranged", "elite", "swarm", "caster", "ambush"
+	SpriteID   string `json:"sprite"` // maps to a SpriteSheet field via SpriteMap
+	BaseHP     int    `json:"hp"`
+	BaseDamage int    `json:"damage"`
+	BaseSpeed  int    `json:"speed"`           // MovementDuration in ticks (lower = faster)
+	AttackRate int    `json:"attack_rate"`     // ticks between attacks
+	Behavior   string `json:"behavior"`        // "roaming", "ambush", "patrol", "ranged", "swarm", "caster"
+	Doors      string `json:"doors,omitempty"` // "block", "open", "bash" or "phase"; defaults by behavior
 }
 
 // GenParamOverrides allows a biome to override specific generation parameters.
 // Nil pointer fields mean "use default".
 type GenParamOverrides struct {
-	RoomCountMin   *int
-	RoomCountMax   *int
-	RoomWMin       *int
-	RoomWMax       *int
-	CorridorWidth  *int
-	DoorLockChance *float64
-	CoverageTarget *float64
+	RoomCountMin   *int     `json:"room_count_min,omitempty"`
+	RoomCountMax   *int     `json:"room_count_max,omitempty"`
+	RoomWMin       *int     `json:"room_w_min,omitempty"`
+	RoomWMax       *int     `json:"room_w_max,omitempty"`
+	CorridorWidth  *int     `json:"corridor_width,omitempty"`
+	DoorLockChance *float64 `json:"door_lock_chance,omitempty"`
+	CoverageTarget *float64 `json:"coverage_target,omitempty"`
+}
+
+// SublayerDef describes an extra layer (a crypt beneath, a gallery above) that
+// a floor of the biome may get, reached by stairs from the main layer.
+type SublayerDef struct {
+	Name   string  `json:"name"`             // shown when the player arrives
+	Below  bool    `json:"below"`            // stairs lead down to it rather than up
+	Chance float64 `json:"chance"`           // per eligible floor
+	Layout string  `json:"layout,omitempty"` // LayoutRooms (default) or LayoutCave
+	Flavor string  `json:"flavor,omitempty"` // wall and floor flavor; defaults to the biome's
+	Size   int     `json:"size,omitempty"`   // width and height in tiles; defaults to sublayerSize
+}
+
+// HazardDef asks for pools of a hazard tile on the biome's floors.
+type HazardDef struct {
+	Type  string `json:"type"`  // tiles.Hazards name: "lava", "water", "spikes", "gas"
+	Pools int    `json:"pools"` // pools per floor
+	Size  int    `json:"size"`  // tiles per pool
 }
 
 // BiomeConfig defines the visual, mechanical, and thematic identity of a biome.
+// Biomes are loaded from JSON files in the biomes directory at startup; see
+// LoadBiomes.
 type BiomeConfig struct {
-	ID           string
-	Name         string
-	WallFlavor   string
-	FloorFlavor  string
-	GenOverrides *GenParamOverrides
-	EnemyPool    []EnemyDef
-	LootTable    *items.LootTableDef // nil = auto-generate from registry
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	WallFlavor   string             `json:"wall_flavor"`
+	FloorFlavor  string             `json:"floor_flavor"`
+	Music        string             `json:"music,omitempty"`   // music track tag
+	Ambient      []string           `json:"ambient,omitempty"` // ambient sound tags
+	Layout       string             `json:"layout,omitempty"`  // LayoutRooms (default) or LayoutCave
+	GenOverrides *GenParamOverrides `json:"gen_overrides,omitempty"`
+	Sublayers    []SublayerDef      `json:"sublayers,omitempty"` // extra layers a floor may get; see rollSublayers
+	Hazards      []HazardDef        `json:"hazards,omitempty"`   // hazard pools sprinkled over each floor
+	EnemyPool    []EnemyDef         `json:"enemies"`
+
+	// LootSupplement holds extra loot entries that boost thematic ability
+	// items for the biome. They are merged on top of the default table so
+	// ability items matching the theme appear more often while remaining
+	// available elsewhere.
+	LootSupplement []items.LootEntry `json:"loot_supplement,omitempty"`
 }
 
 // EnemyByRole returns the first EnemyDef matching the given role, or nil.
@@ -53,88 +84,8 @@ func (bc *BiomeConfig) EnemyByRole(role string) *EnemyDef {
 	return nil
 }
 
-// biomeLootSupplement returns extra loot entries that boost thematic ability items
-// for a biome. These are merged on top of the default table so ability items
-// matching the biome theme appear more often while remaining available elsewhere.
-func biomeLootSupplement(entries ...items.LootEntry) *items.LootTableDef {
-	return &items.LootTableDef{Entries: entries}
-}
-
-// BiomeConfigs maps each Biome constant to its configuration.
-var BiomeConfigs = map[Biome]*BiomeConfig{
-	BiomeCrypt: {
-		ID: "crypt", Name: "Crypt",
-		WallFlavor: "crypt", FloorFlavor: "crypt",
-		// Dark arcane theme: fire, chaos, and grapple feel at home here.
-		LootTable: biomeLootSupplement(
-			items.LootEntry{ItemID: "item_2_24", Weight: 2.0, MinFloor: 1, Rarity: items.RarityUncommon}, // Fireball Emblem
-			items.LootEntry{ItemID: "item_0_3", Weight: 2.0, MinFloor: 1, Rarity: items.RarityUncommon},  // Chaos Emblem
-			items.LootEntry{ItemID: "item_1_12", Weight: 1.5, MinFloor: 2, Rarity: items.RarityUncommon}, // Grips of the Buried Flame → grapple
-		),
-		EnemyPool: []EnemyDef{
-			{ID: "crypt_melee", Name: "Grey Knight", Role: "melee", SpriteID: "GreyKnight", BaseHP: 30, BaseDamage: 8, BaseSpeed: 30, AttackRate: 45, Behavior: "roaming"},
-			{ID: "crypt_ranged", Name: "Sorcerer", Role: "ranged", SpriteID: "Sorcerer", BaseHP: 20, BaseDamage: 6, BaseSpeed: 35, AttackRate: 60, Behavior: "ranged"},
-			{ID: "crypt_elite", Name: "Demon Knight", Role: "elite", SpriteID: "DemonKnight", BaseHP: 80, BaseDamage: 15, BaseSpeed: 25, AttackRate: 40, Behavior: "roaming"},
-			{ID: "crypt_swarm", Name: "Apparition", Role: "swarm", SpriteID: "Apparition", BaseHP: 8, BaseDamage: 3, BaseSpeed: 20, AttackRate: 30, Behavior: "swarm"},
-			{ID: "crypt_caster", Name: "Death", Role: "caster", SpriteID: "Death", BaseHP: 25, BaseDamage: 10, BaseSpeed: 35, AttackRate: 70, Behavior: "ranged"},
-			{ID: "crypt_ambush", Name: "Chimera", Role: "ambush", SpriteID: "Chimera", BaseHP: 40, BaseDamage: 12, BaseSpeed: 25, AttackRate: 40, Behavior: "ambush"},
-		},
-	},
-	BiomeMoss: {
-		ID: "moss", Name: "Moss",
-		WallFlavor: "moss", FloorFlavor: "moss",
-		// Ancient nature theme: fractal spells and lightning fit the primal wilds.
-		LootTable: biomeLootSupplement(
-			items.LootEntry{ItemID: "item_2_63", Weight: 2.0, MinFloor: 1, Rarity: items.RarityUncommon}, // Verdant Tome → fractal_bloom
-			items.LootEntry{ItemID: "item_2_55", Weight: 2.0, MinFloor: 2, Rarity: items.RarityUncommon}, // Necromancer's Tome → fractal_canopy
-			items.LootEntry{ItemID: "item_0_26", Weight: 1.5, MinFloor: 2, Rarity: items.RarityUncommon}, // Rage Emblem → lightning
-		),
-		EnemyPool: []EnemyDef{
-			{ID: "moss_melee", Name: "Caveman", Role: "melee", SpriteID: "Caveman", BaseHP: 35, BaseDamage: 9, BaseSpeed: 28, AttackRate: 45, Behavior: "roaming"},
-			{ID: "moss_ranged", Name: "Oracle", Role: "ranged", SpriteID: "Oracle", BaseHP: 22, BaseDamage: 7, BaseSpeed: 32, AttackRate: 55, Behavior: "ranged"},
-			{ID: "moss_elite", Name: "Minotaur", Role: "elite", SpriteID: "Minotaur", BaseHP: 100, BaseDamage: 18, BaseSpeed: 22, AttackRate: 50, Behavior: "patrol"},
-			{ID: "moss_swarm", Name: "Blue Wisp", Role: "swarm", SpriteID: "BlueMan", BaseHP: 6, BaseDamage: 2, BaseSpeed: 18, AttackRate: 25, Behavior: "swarm"},
-			{ID: "moss_caster", Name: "Absolem", Role: "caster", SpriteID: "Absolem", BaseHP: 28, BaseDamage: 9, BaseSpeed: 35, AttackRate: 65, Behavior: "ranged"},
-			{ID: "moss_ambush", Name: "Manticore", Role: "ambush", SpriteID: "Manticore", BaseHP: 45, BaseDamage: 14, BaseSpeed: 22, AttackRate: 40, Behavior: "ambush"},
-		},
-	},
-	BiomeGallery: {
-		ID: "gallery", Name: "Gallery",
-		WallFlavor: "gallery", FloorFlavor: "gallery",
-		// Cursed aristocratic theme: dark power and blink suit the haunted halls.
-		LootTable: biomeLootSupplement(
-			items.LootEntry{ItemID: "item_0_35", Weight: 2.0, MinFloor: 2, Rarity: items.RarityRare},     // Azazel's Pentagram → lightning_storm
-			items.LootEntry{ItemID: "item_0_3", Weight: 1.5, MinFloor: 1, Rarity: items.RarityUncommon},  // Chaos Emblem → chaos_ray
-			items.LootEntry{ItemID: "item_2_35", Weight: 1.5, MinFloor: 1, Rarity: items.RarityUncommon}, // Haste Carriers → blink
-		),
-		EnemyPool: []EnemyDef{
-			{ID: "gallery_melee", Name: "Red Champion", Role: "melee", SpriteID: "RedChampion", BaseHP: 32, BaseDamage: 10, BaseSpeed: 28, AttackRate: 42, Behavior: "roaming"},
-			{ID: "gallery_ranged", Name: "Duchess", Role: "ranged", SpriteID: "Duchess", BaseHP: 18, BaseDamage: 7, BaseSpeed: 33, AttackRate: 55, Behavior: "ranged"},
-			{ID: "gallery_elite", Name: "Blue Champion", Role: "elite", SpriteID: "BlueChampion", BaseHP: 90, BaseDamage: 16, BaseSpeed: 24, AttackRate: 45, Behavior: "patrol"},
-			{ID: "gallery_swarm", Name: "Tortured Soul", Role: "swarm", SpriteID: "TorturedSoul", BaseHP: 7, BaseDamage: 3, BaseSpeed: 20, AttackRate: 28, Behavior: "swarm"},
-			{ID: "gallery_caster", Name: "Celestial", Role: "caster", SpriteID: "Celestial", BaseHP: 24, BaseDamage: 11, BaseSpeed: 36, AttackRate: 68, Behavior: "ranged"},
-			{ID: "gallery_ambush", Name: "Griffon", Role: "ambush", SpriteID: "Griffon", BaseHP: 38, BaseDamage: 13, BaseSpeed: 20, AttackRate: 38, Behavior: "ambush"},
-		},
-	},
-	BiomeBrick: {
-		ID: "brick", Name: "Brick",
-		WallFlavor: "brick", FloorFlavor: "brick",
-		// Demonic hellish theme: fire and storm dominate the deeper infernal halls.
-		LootTable: biomeLootSupplement(
-			items.LootEntry{ItemID: "item_2_24", Weight: 1.5, MinFloor: 1, Rarity: items.RarityUncommon}, // Fireball Emblem
-			items.LootEntry{ItemID: "item_0_26", Weight: 2.0, MinFloor: 1, Rarity: items.RarityUncommon}, // Rage Emblem → lightning
-			items.LootEntry{ItemID: "item_0_35", Weight: 1.5, MinFloor: 3, Rarity: items.RarityRare},     // Azazel's Pentagram → lightning_storm
-		),
-		EnemyPool: []EnemyDef{
-			{ID: "brick_melee", Name: "Sentinel", Role: "melee", SpriteID: "Sentinel", BaseHP: 28, BaseDamage: 8, BaseSpeed: 30, AttackRate: 45, Behavior: "roaming"},
-			{ID: "brick_ranged", Name: "Jester", Role: "ranged", SpriteID: "Jester", BaseHP: 20, BaseDamage: 6, BaseSpeed: 30, AttackRate: 50, Behavior: "ranged"},
-			{ID: "brick_elite", Name: "Cyclops", Role: "elite", SpriteID: "Cyclops", BaseHP: 95, BaseDamage: 20, BaseSpeed: 28, AttackRate: 55, Behavior: "roaming"},
-			{ID: "brick_swarm", Name: "Lesser Demon", Role: "swarm", SpriteID: "LesserDemon", BaseHP: 8, BaseDamage: 4, BaseSpeed: 22, AttackRate: 30, Behavior: "swarm"},
-			{ID: "brick_caster", Name: "Greater Demon", Role: "caster", SpriteID: "GreaterDemon", BaseHP: 30, BaseDamage: 12, BaseSpeed: 34, AttackRate: 65, Behavior: "ranged"},
-			{ID: "brick_ambush", Name: "Two Headed Ogre", Role: "ambush", SpriteID: "TwoHeadedOgre", BaseHP: 50, BaseDamage: 15, BaseSpeed: 28, AttackRate: 45, Behavior: "ambush"},
-		},
-	},
-}
+// BiomeConfigs maps each loaded biome to its configuration.
+var BiomeConfigs = map[Biome]*BiomeConfig{}
 
 // SpriteMap builds a lookup from sprite name to image for the entity system.
 func BuildSpriteMap(ss *sprites.SpriteSheet) map[string]*ebiten.Image {
@@ -0,0 +1,220 @@
+package game
+
+import (
+	"dungeoneer/entities"
+	"dungeoneer/items"
+	"dungeoneer/sprites"
+	"dungeoneer/tiles"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// biomeDir holds one JSON file per biome.
+const biomeDir = "biomes"
+
+// Roles and behaviors the encounter system knows how to spawn.
+var (
+	validEnemyRoles     = []string{"melee", "ranged", "elite", "swarm", "caster", "ambush"}
+	validEnemyBehaviors = []string{"roaming", "ambush", "patrol", "ranged", "swarm", "caster"}
+	validRarities       = []string{items.RarityCommon, items.RarityUncommon, items.RarityRare, items.RarityLegendary}
+	validLayouts        = []string{LayoutRooms, LayoutCave}
+)
+
+// LoadBiomes reads every .json file in dir, validates it and registers it in
+// BiomeConfigs. Invalid files are reported and skipped so one bad biome does
+// not stop the game from starting. spriteMap must come from BuildSpriteMap
+// and items must already be loaded, since enemy sprites and loot item IDs are
+// checked against them.
+//
+// If no biome loads, a bare Crypt biome is registered so runs still work,
+// spawning through the legacy path.
+func LoadBiomes(dir string, spriteMap map[string]*ebiten.Image) error {
+	BiomeConfigs = map[Biome]*BiomeConfig{}
+	availableBiomes = nil
+	defer func() {
+		if len(availableBiomes) == 0 {
+			BiomeConfigs[BiomeCrypt] = &BiomeConfig{ID: string(BiomeCrypt), Name: "Crypt", WallFlavor: "crypt", FloorFlavor: "crypt"}
+			availableBiomes = []Biome{BiomeCrypt}
+		}
+	}()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("biomes: readdir %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		bc, err := loadBiome(path)
+		if err != nil {
+			fmt.Printf("biomes: %v\n", err)
+			continue
+		}
+		if errs := validateBiome(bc, spriteMap); len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Printf("biomes: %s: %v\n", path, err)
+			}
+			continue
+		}
+		id := Biome(bc.ID)
+		if _, dup := BiomeConfigs[id]; dup {
+			fmt.Printf("biomes: %s: duplicate biome id %q\n", path, bc.ID)
+			continue
+		}
+		BiomeConfigs[id] = bc
+		availableBiomes = append(availableBiomes, id)
+	}
+	// Fixed order so a run seed picks the same biome sequence everywhere.
+	sort.Slice(availableBiomes, func(i, j int) bool { return availableBiomes[i] < availableBiomes[j] })
+	return nil
+}
+
+func loadBiome(path string) (*BiomeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	var bc BiomeConfig
+	if err := json.Unmarshal(data, &bc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if bc.FloorFlavor == "" {
+		bc.FloorFlavor = bc.WallFlavor
+	}
+	return &bc, nil
+}
+
+// validateBiome checks a biome against the registered sprites and items and
+// returns every problem found.
+func validateBiome(bc *BiomeConfig, spriteMap map[string]*ebiten.Image) []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if bc.ID == "" {
+		fail("missing id")
+	}
+	if bc.Name == "" {
+		fail("missing name")
+	}
+	if !contains(sprites.WallFlavors, bc.WallFlavor) {
+		fail("unknown wall flavor %q", bc.WallFlavor)
+	}
+	if !contains(sprites.WallFlavors, bc.FloorFlavor) {
+		fail("unknown floor flavor %q", bc.FloorFlavor)
+	}
+	if bc.Layout != "" && !contains(validLayouts, bc.Layout) {
+		fail("unknown layout %q", bc.Layout)
+	}
+	for _, s := range bc.Sublayers {
+		if s.Name == "" {
+			fail("sublayer: missing name")
+		}
+		if s.Chance <= 0 || s.Chance > 1 {
+			fail("sublayer %q: chance must be in (0, 1]", s.Name)
+		}
+		if s.Layout != "" && !contains(validLayouts, s.Layout) {
+			fail("sublayer %q: unknown layout %q", s.Name, s.Layout)
+		}
+		if s.Flavor != "" && !contains(sprites.WallFlavors, s.Flavor) {
+			fail("sublayer %q: unknown flavor %q", s.Name, s.Flavor)
+		}
+		if s.Size != 0 && (s.Size < minSublayerSize || s.Size > 64) {
+			fail("sublayer %q: size must be between %d and 64", s.Name, minSublayerSize)
+		}
+	}
+	for _, h := range bc.Hazards {
+		if _, ok := tiles.HazardByName(h.Type); !ok {
+			fail("unknown hazard %q", h.Type)
+		}
+		if h.Pools <= 0 || h.Size <= 0 {
+			fail("hazard %q: pools and size must be positive", h.Type)
+		}
+	}
+
+	if len(bc.EnemyPool) == 0 {
+		fail("empty enemy pool")
+	} else if bc.EnemyByRole("melee") == nil {
+		// Encounter slots fall back to melee when their role is missing.
+		fail("enemy pool has no melee enemy")
+	}
+	seen := map[string]bool{}
+	for _, e := range bc.EnemyPool {
+		if e.ID == "" {
+			fail("enemy %q: missing id", e.Name)
+		} else if seen[e.ID] {
+			fail("enemy %q: duplicate id", e.ID)
+		}
+		seen[e.ID] = true
+		if !contains(validEnemyRoles, e.Role) {
+			fail("enemy %q: unknown role %q", e.ID, e.Role)
+		}
+		if !contains(validEnemyBehaviors, e.Behavior) {
+			fail("enemy %q: unknown behavior %q", e.ID, e.Behavior)
+		}
+		if _, ok := entities.DoorPolicyByName(e.Doors); e.Doors != "" && !ok {
+			fail("enemy %q: unknown door policy %q", e.ID, e.Doors)
+		}
+		if img, ok := spriteMap[e.SpriteID]; !ok || img == nil {
+			fail("enemy %q: unknown sprite %q", e.ID, e.SpriteID)
+		}
+		if e.BaseHP <= 0 || e.BaseDamage < 0 || e.BaseSpeed <= 0 || e.AttackRate <= 0 {
+			fail("enemy %q: hp, speed and attack_rate must be positive", e.ID)
+		}
+	}
+
+	for _, le := range bc.LootSupplement {
+		if _, ok := items.Registry[le.ItemID]; !ok {
+			fail("loot: unknown item %q", le.ItemID)
+		}
+		if le.Weight <= 0 {
+			fail("loot %q: weight must be positive", le.ItemID)
+		}
+		if le.Rarity != "" && !contains(validRarities, le.Rarity) {
+			fail("loot %q: unknown rarity %q", le.ItemID, le.Rarity)
+		}
+	}
+
+	if o := bc.GenOverrides; o != nil {
+		positive := map[string]*int{
+			"room_count_min": o.RoomCountMin, "room_count_max": o.RoomCountMax,
+			"room_w_min": o.RoomWMin, "room_w_max": o.RoomWMax, "corridor_width": o.CorridorWidth,
+		}
+		for name, v := range positive {
+			if v != nil && *v <= 0 {
+				fail("gen_overrides: %s must be positive", name)
+			}
+		}
+		if o.RoomCountMin != nil && o.RoomCountMax != nil && *o.RoomCountMin > *o.RoomCountMax {
+			fail("gen_overrides: room_count_min > room_count_max")
+		}
+		if o.RoomWMin != nil && o.RoomWMax != nil && *o.RoomWMin > *o.RoomWMax {
+			fail("gen_overrides: room_w_min > room_w_max")
+		}
+		if o.DoorLockChance != nil && (*o.DoorLockChance < 0 || *o.DoorLockChance > 1) {
+			fail("gen_overrides: door_lock_chance must be within 0-1")
+		}
+		if o.CoverageTarget != nil && (*o.CoverageTarget < 0.1 || *o.CoverageTarget > 0.9) {
+			fail("gen_overrides: coverage_target must be within 0.1-0.9")
+		}
+	}
+	return errs
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -36,6 +36,9 @@ func (g *Game) setupBossFloor(lvl *levels.Level) {
 	bestArea := 0
 	for i := range lvl.Rooms {
 		r := &lvl.Rooms[i]
+		if r.Prefab != "" {
+			continue // vaults keep their authored layout
+		}
 		area := r.W * r.H
 		if area > bestArea {
 			bestArea = area
@@ -145,7 +148,7 @@ func (g *Game) onBossDefeated() {
 				g.Meta.NPCMeta[npcID] = &NPCMetaState{}
 			}
 			g.Meta.NPCMeta[npcID].DefeatCount++
-			SaveMeta(g.Meta)
+			g.saveMeta()
 		}
 		g.unsealBossRoom()
 		g.ExitEntity = entities.NewExitEntity(bx, by, g.spriteSheet.Portal, "Portal")
@@ -0,0 +1,197 @@
+package game
+
+import (
+	"dungeoneer/controls"
+	"dungeoneer/hud"
+	"dungeoneer/items"
+	"dungeoneer/levels"
+	"dungeoneer/simrand"
+	"dungeoneer/spells"
+	"dungeoneer/tiles"
+	"math"
+)
+
+// beltActions are the quick-use belt keys, in slot order.
+var beltActions = []controls.ActionID{
+	controls.ActionBelt1, controls.ActionBelt2, controls.ActionBelt3, controls.ActionBelt4,
+}
+
+// handleBelt uses the belt slot whose key was pressed.
+func (g *Game) handleBelt() {
+	for i, action := range beltActions {
+		if g.isActionJustPressed(action) {
+			g.useBeltSlot(i)
+		}
+	}
+}
+
+// useBeltSlot uses one of the items in belt slot i.
+func (g *Game) useBeltSlot(i int) {
+	if g.player == nil || g.player.IsDead {
+		return
+	}
+	it := g.player.BeltItem(i)
+	if it == nil {
+		return
+	}
+	if g.useConsumable(it) {
+		g.player.ConsumeItem(it.ID)
+	}
+}
+
+// useConsumable applies it through its OnUse hook, reporting whether it took
+// effect and so should be used up. It hints at why when it doesn't.
+func (g *Game) useConsumable(it *items.Item) bool {
+	if it.OnUse == nil {
+		g.ShowHint("Nothing happens")
+		return false
+	}
+	return it.OnUse(useContext{g})
+}
+
+// useContext is the game as the consumables' OnUse hooks see it. Bombs are
+// thrown at the tile under the cursor.
+type useContext struct{ g *Game }
+
+func (c useContext) RestoreHealth(pct int) bool {
+	p := c.g.player
+	healed := min(max(1, p.MaxHP*pct/100), p.MaxHP-p.HP)
+	if healed <= 0 {
+		c.g.ShowHint("Already at full health")
+		return false
+	}
+	p.HP += healed
+	c.g.addHealNumber(healed, "")
+	return true
+}
+
+func (c useContext) RestoreMana(pct int) bool {
+	p := c.g.player
+	restored := min(max(1, p.MaxMana*pct/100), p.MaxMana-p.Mana)
+	if restored <= 0 {
+		c.g.ShowHint("Already at full mana")
+		return false
+	}
+	p.Mana += restored
+	c.g.addHealNumber(restored, "mana")
+	return true
+}
+
+func (c useContext) Teleport(minDistance int) bool { return c.g.teleportPlayer(minDistance) }
+func (c useContext) RevealFloor() bool             { return c.g.revealFloor() }
+func (c useContext) IdentifyGear() bool            { return c.g.identifyGear() }
+func (c useContext) ThrowFireBomb(reach, damage, noiseRadius int) bool {
+	return c.g.throwBomb(c.g.hoverTileX, c.g.hoverTileY, reach, damage, noiseRadius)
+}
+
+// teleportPlayer moves the player to a random safe tile they could walk to,
+// preferring one at least minDistance away. Hazards, doors and anything
+// behind a locked door or cracked wall are never picked.
+func (g *Game) teleportPlayer(minDistance int) bool {
+	lvl := g.currentLevel
+	if lvl == nil {
+		return false
+	}
+	var near, far [][2]int
+	for _, pt := range levels.Reachable(lvl, g.player.TileX, g.player.TileY) {
+		x, y := pt.X, pt.Y
+		if !lvl.IsWalkable(x, y) || (x == g.player.TileX && y == g.player.TileY) {
+			continue
+		}
+		if t := lvl.Tile(x, y); t.HasTag(tiles.TagDoor) || t.Hazard != tiles.HazardNone {
+			continue
+		}
+		if math.Hypot(float64(x-g.player.TileX), float64(y-g.player.TileY)) >= float64(minDistance) {
+			far = append(far, [2]int{x, y})
+		} else {
+			near = append(near, [2]int{x, y})
+		}
+	}
+	if len(far) == 0 {
+		far = near
+	}
+	if len(far) == 0 {
+		g.ShowHint("Nowhere to go")
+		return false
+	}
+	dest := far[simrand.IntN(len(far))]
+	ox, oy := g.player.MoveController.InterpX, g.player.MoveController.InterpY
+	g.placePlayerAt(dest[0], dest[1])
+	g.ActiveSpells = append(g.ActiveSpells, spells.NewBlinkEffect(ox, oy, float64(dest[0]), float64(dest[1])))
+	return true
+}
+
+// revealFloor marks every tile of the floor as seen.
+func (g *Game) revealFloor() bool {
+	revealed := false
+	for y := range g.SeenTiles {
+		for x := range g.SeenTiles[y] {
+			if !g.SeenTiles[y][x] {
+				g.SeenTiles[y][x] = true
+				revealed = true
+			}
+		}
+	}
+	if !revealed {
+		g.ShowHint("This floor holds no more secrets")
+	}
+	return revealed
+}
+
+// identifyGear identifies every unidentified item the player carries or
+// wears.
+func (g *Game) identifyGear() bool {
+	p := g.player
+	identified := false
+	if inv := p.Inventory; inv != nil {
+		for y := 0; y < inv.Height; y++ {
+			for x := 0; x < inv.Width; x++ {
+				if it := inv.Grid[y][x]; it != nil && it.Identify() {
+					identified = true
+				}
+			}
+		}
+	}
+	for _, it := range p.EquippedItems() {
+		if it.Identify() {
+			identified = true
+		}
+	}
+	if !identified {
+		g.ShowHint("Nothing to identify")
+		return false
+	}
+	p.RecalculateStats()
+	return true
+}
+
+// throwBomb bursts a fire bomb dealing damage on tile (tx, ty), which must be
+// within reach and in sight of the player.
+func (g *Game) throwBomb(tx, ty, reach, damage, noiseRadius int) bool {
+	p := g.player
+	if math.Hypot(float64(tx-p.TileX), float64(ty-p.TileY)) > float64(reach) {
+		g.ShowHint("Too far to throw")
+		return false
+	}
+	if !g.hasLineOfSight(p.TileX, p.TileY, tx, ty) {
+		g.ShowHint("Can't throw there")
+		return false
+	}
+	info := spells.SpellInfo{Name: "firebomb", Level: 2, Damage: damage}
+	fb := spells.NewFireball(info, float64(tx), float64(ty), float64(tx), float64(ty), g.fireballSprites, g.spriteSheet.FireBurst2)
+	fb.Impact = true
+	g.applyFireballDamage(fb, tx, ty)
+	g.ActiveSpells = append(g.ActiveSpells, fb)
+	g.emitNoise(tx, ty, noiseRadius)
+	return true
+}
+
+// syncHUDBelt copies the belt's items and counts to the HUD.
+func (g *Game) syncHUDBelt() {
+	for i := range g.HUD.Belt {
+		g.HUD.Belt[i] = hud.BeltSlot{}
+		if it := g.player.BeltItem(i); it != nil {
+			g.HUD.Belt[i] = hud.BeltSlot{Icon: it.Icon, Count: it.Count}
+		}
+	}
+}
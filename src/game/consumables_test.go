@@ -0,0 +1,107 @@
+package game
+
+import (
+	"dungeoneer/controls"
+	"dungeoneer/entities"
+	"dungeoneer/items"
+	"dungeoneer/levels"
+	"dungeoneer/tiles"
+	"image"
+	"testing"
+)
+
+func TestBeltPotionHealsAndUsesOneFromTheStack(t *testing.T) {
+	s := newTestSim(t, 5)
+	p := s.Player()
+	potions := items.NewItem(items.HealthPotionID)
+	potions.Count = 2
+	if !s.Game.AddItemToPlayer(potions) {
+		t.Fatal("no room for the potions")
+	}
+	if p.Belt[0] != items.HealthPotionID {
+		t.Fatalf("belt = %v, want the potions in the first slot", p.Belt)
+	}
+
+	p.HP = 1
+	press := InputFrame{Pressed: []controls.ActionID{controls.ActionBelt1}}
+	if err := s.Run(press); err != nil {
+		t.Fatal(err)
+	}
+	if p.HP <= 1 {
+		t.Fatal("drinking a healing potion did not heal")
+	}
+	if it := p.BeltItem(0); it == nil || it.Count != 1 {
+		t.Fatal("drinking a potion did not take exactly one from the stack")
+	}
+
+	p.HP = 1
+	if err := s.Run(InputFrame{}, press); err != nil {
+		t.Fatal(err)
+	}
+	if p.Inventory.HasItem(items.HealthPotionID) || p.Belt[0] != "" {
+		t.Fatal("the last potion stayed in the inventory or on the belt")
+	}
+}
+
+func TestFireBombBurnsMonstersAroundTarget(t *testing.T) {
+	s := newTestSim(t, 7)
+	g := s.Game
+	mx, my := openNeighbour(t, s)
+	dummy := newDummy(mx, my, 100)
+	g.Monsters = []*entities.Monster{dummy}
+
+	bomb := items.NewItem(items.FireBombID)
+	g.hoverTileX, g.hoverTileY = mx, my
+	if !g.useConsumable(bomb) {
+		t.Fatal("could not throw a bomb next to the player")
+	}
+	if dummy.HP != dummy.MaxHP-2*items.BombDamage {
+		t.Fatalf("dummy HP after a bomb = %d, want %d", dummy.HP, dummy.MaxHP-2*items.BombDamage)
+	}
+	g.hoverTileX = mx + items.BombRange*2
+	if g.useConsumable(bomb) {
+		t.Fatal("threw a bomb out of range")
+	}
+}
+
+func TestBeltFreesSlotsOfItemsNoLongerCarried(t *testing.T) {
+	s := newTestSim(t, 5)
+	p := s.Player()
+	for i := range p.Belt {
+		p.Belt[i] = items.ManaPotionID // long since drunk or dropped
+	}
+	if !s.Game.AddItemToPlayer(items.NewItem(items.HealthPotionID)) {
+		t.Fatal("no room for the potion")
+	}
+	if p.Belt[0] != items.HealthPotionID {
+		t.Fatalf("belt = %v, want the potion in the first stale slot", p.Belt)
+	}
+
+	s.Game.returnToHub()
+	if belt := s.Player().Belt; belt != [len(belt)]string{} {
+		t.Fatalf("belt = %v after returning to the hub, want it empty", belt)
+	}
+}
+
+func TestTeleportLandsOnReachableSafeTile(t *testing.T) {
+	s := newTestSim(t, 11)
+	g := s.Game
+	p := s.Player()
+	sx, sy := p.TileX, p.TileY
+	reachable := map[image.Point]bool{}
+	for _, pt := range levels.Reachable(s.Level(), sx, sy) {
+		reachable[pt] = true
+	}
+	for i := 0; i < 20; i++ {
+		s.Teleport(sx, sy)
+		if !g.teleportPlayer(items.TeleportMinDistance) {
+			t.Fatal("teleport found nowhere to go")
+		}
+		if !reachable[image.Pt(p.TileX, p.TileY)] {
+			t.Fatalf("teleported to (%d,%d), which can't be walked to", p.TileX, p.TileY)
+		}
+		if tile := s.Level().Tile(p.TileX, p.TileY); tile.Hazard != tiles.HazardNone {
+			t.Fatalf("teleported onto a hazard at (%d,%d)", p.TileX, p.TileY)
+		}
+	}
+}
@@ -29,8 +29,12 @@ func (g *Game) drawDamageNumbers(target *ebiten.Image, scale, cx, cy float64) {
 
 		alpha := 1.0 - float64(d.Ticks)/float64(d.MaxTicks)
 		clr := color.NRGBA{255, 255, 0, uint8(alpha * 255)}
-
 		msg := fmt.Sprintf("%d", d.Value)
+		if d.Label != "" {
+			// Item procs stand out from plain hits.
+			clr = color.NRGBA{255, 140, 40, uint8(alpha * 255)}
+			msg += " " + d.Label
+		}
 		text.Draw(target, msg, basicfont.Face7x13, int(drawX), int(drawY), clr)
 	}
 }
@@ -56,6 +60,9 @@ func (g *Game) drawHealNumbers(target *ebiten.Image, scale, cx, cy float64) {
 		clr := color.NRGBA{0, 255, 0, uint8(alpha * 255)} // Green!
 
 		msg := fmt.Sprintf("+%d", h.Value)
+		if h.Label != "" {
+			msg += " " + h.Label
+		}
 		text.Draw(target, msg, basicfont.Face7x13, int(drawX), int(drawY), clr)
 	}
 }
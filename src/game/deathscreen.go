@@ -6,13 +6,12 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 // updateDeathScreen handles input on the death summary screen.
 func (g *Game) updateDeathScreen() error {
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+	if g.input.KeyJustPressed(ebiten.KeyEnter) || g.input.KeyJustPressed(ebiten.KeySpace) {
 		g.returnToHub()
 	}
 	return nil
@@ -20,7 +19,7 @@ func (g *Game) updateDeathScreen() error {
 
 // updateVictoryScreen handles input on the victory summary screen.
 func (g *Game) updateVictoryScreen() error {
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+	if g.input.KeyJustPressed(ebiten.KeyEnter) || g.input.KeyJustPressed(ebiten.KeySpace) {
 		g.returnToHub()
 	}
 	return nil
@@ -52,6 +51,7 @@ func (g *Game) drawDeathScreen(screen *ebiten.Image) {
 			fmt.Sprintf("Monsters Slain:   %d", g.RunState.KillCount),
 			fmt.Sprintf("Gold Earned:      %d (lost)", g.RunState.GoldEarned),
 			fmt.Sprintf("Remnants Earned:  %d", g.RunState.RemnantEarned),
+			fmt.Sprintf("Run Seed:         %s", FormatSeed(g.RunState.Seed)),
 			"",
 			fmt.Sprintf("Total Remnants:   %d", g.Meta.Remnants),
 			fmt.Sprintf("Total Runs:       %d", g.Meta.RunCount),
@@ -92,6 +92,7 @@ func (g *Game) drawVictoryScreen(screen *ebiten.Image) {
 			fmt.Sprintf("Monsters Slain:   %d", g.RunState.KillCount),
 			fmt.Sprintf("Gold Earned:      %d", g.RunState.GoldEarned),
 			fmt.Sprintf("Remnants Earned:  %d (x2 Victory Bonus!)", g.RunState.RemnantEarned),
+			fmt.Sprintf("Run Seed:         %s", FormatSeed(g.RunState.Seed)),
 			"",
 			fmt.Sprintf("Total Remnants:   %d", g.Meta.Remnants),
 			fmt.Sprintf("Total Runs:       %d", g.Meta.RunCount),
@@ -3,12 +3,12 @@ package game
 import (
 	"dungeoneer/leveleditor"
 	"dungeoneer/levels"
+	"dungeoneer/pathing"
 	"dungeoneer/tiles"
 	"fmt"
 	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 func notNil(t *tiles.Tile) bool {
@@ -19,7 +19,7 @@ func (g *Game) DebugLevelEditor() {
 	if g.editor == nil || !g.editor.Active {
 		return
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+	if g.input.KeyJustPressed(ebiten.KeyF5) {
 		err := leveleditor.SaveLevelToFile(g.currentLevel, "test_level.json")
 		if err != nil {
 			fmt.Println("Save failed:", err)
@@ -28,7 +28,7 @@ func (g *Game) DebugLevelEditor() {
 		}
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyF6) {
+	if g.input.KeyJustPressed(ebiten.KeyF6) {
 		level, err := leveleditor.LoadLevelFromFile("test_level.json")
 		if err != nil {
 			fmt.Println("Load failed:", err)
@@ -47,7 +47,7 @@ func (g *Game) DebugLevelEditor() {
 	}
 
 	// Tile painting
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) &&
+	if g.input.MousePressed(ebiten.MouseButtonLeft) &&
 		!g.editor.JustSelectedSprite && !g.editor.JustSelectedEntity && !g.editor.IsMenuOpen() {
 		if g.editor.EntityMode == leveleditor.ModeDelete {
 			// Delete mode - remove entities from tiles
@@ -60,11 +60,12 @@ func (g *Game) DebugLevelEditor() {
 		} else if g.editor.SelectedID != "" {
 			// Sprite mode - place sprites
 			g.editor.PlaceSelectedSpriteAt(g.hoverTileX, g.hoverTileY)
+			pathing.InvalidateCache()
 		}
 	}
 
 	// Middle click - remove top sprite from tile (works in all modes)
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle) {
+	if g.input.MousePressed(ebiten.MouseButtonMiddle) {
 		tx, ty := g.hoverTileX, g.hoverTileY
 		if g.isValidTile(tx, ty) {
 			tile := g.currentLevel.Tile(tx, ty)
@@ -75,6 +76,9 @@ func (g *Game) DebugLevelEditor() {
 				last := tile.Sprites[len(tile.Sprites)-1]
 				meta := leveleditor.SpriteRegistry[last.ID]
 				tile.IsWalkable = meta.IsWalkable
+				if leveleditor.SpriteRegistry[removed.ID].Hazard == tile.Hazard {
+					tile.Hazard = meta.Hazard
+				}
 				if tile.HasTag(tiles.TagDoor) && (strings.Contains(strings.ToLower(removed.ID), "door_locked") ||
 					strings.Contains(strings.ToLower(removed.ID), "door_unlocked")) {
 					// If no door sprites remain, clear door state.
@@ -92,6 +96,7 @@ func (g *Game) DebugLevelEditor() {
 						tile.DoorSpriteID = ""
 					}
 				}
+				pathing.InvalidateCache()
 			}
 		}
 	}
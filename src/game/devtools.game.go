@@ -114,6 +114,11 @@ func (g *Game) buildDevEntries() []ui.DevEntry {
 			IsActive: func() bool { return g.ShowInteractionRadii },
 			Toggle:   func() { g.ShowInteractionRadii = !g.ShowInteractionRadii },
 		},
+		{
+			Label:    "Reveal Traps",
+			IsActive: func() bool { return g.ShowTraps },
+			Toggle:   func() { g.ShowTraps = !g.ShowTraps },
+		},
 
 		// ── Level Generation ───────────────────────────────────────────────
 		{Label: "LEVEL GENERATION", IsHeader: true},
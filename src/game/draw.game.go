@@ -58,6 +58,10 @@ func (g *Game) Draw(screen *ebiten.Image) {
 			g.GenerateMenu.Draw(screen)
 		} else if g.ProcGenMenu != nil && g.ProcGenMenu.IsVisible() {
 			g.ProcGenMenu.Draw(screen)
+		} else if g.ShopMenu != nil && g.ShopMenu.IsVisible() {
+			g.ShopMenu.Draw(screen)
+		} else if g.SeedPrompt != nil && g.SeedPrompt.IsVisible() {
+			g.SeedPrompt.Draw(screen)
 		} else {
 			g.PauseMenu.Draw(screen)
 			if g.SavePrompt != nil && g.SavePrompt.IsVisible() {
@@ -192,9 +196,12 @@ func (g *Game) drawPlaying(screen *ebiten.Image, cx, cy float64) {
 	g.drawHitMarkers(target, scale, cx, cy)
 	g.drawDamageNumbers(target, scale, cx, cy)
 	g.drawHealNumbers(target, scale, cx, cy)
+	g.drawEliteNames(target, scale, cx, cy)
+	g.drawAlertIndicators(target, scale, cx, cy)
 	g.drawGrapple(target, scale, cx, cy)
 	g.drawBossChainPull(target, scale, cx, cy)
 	g.drawThroatDebug(target, scale, cx, cy)
+	g.drawTrapDebug(target, scale, cx, cy)
 	g.drawCombatDebugOverlays(target, scale, cx, cy)
 	g.drawWallDebugOverlay(target, scale, cx, cy)
 
@@ -655,6 +662,34 @@ func (g *Game) drawThroatDebug(target *ebiten.Image, scale, cx, cy float64) {
 	}
 }
 
+// trapDebugColors tints each trap kind in the trap overlay.
+var trapDebugColors = map[string][3]float32{
+	levels.TrapPlate:    {0.9, 0.9, 0.2},
+	levels.TrapDart:     {1.0, 0.5, 0.1},
+	levels.TrapSpikes:   {1.0, 0.2, 0.2},
+	levels.TrapCollapse: {0.6, 0.4, 0.2},
+	levels.TrapAlarm:    {0.7, 0.3, 1.0},
+}
+
+// drawTrapDebug highlights every trap, hidden or not, while the trap overlay
+// or the level editor is on. Spent traps are drawn faded.
+func (g *Game) drawTrapDebug(target *ebiten.Image, scale, cx, cy float64) {
+	if !g.ShowTraps && (g.editor == nil || !g.editor.Active) {
+		return
+	}
+	for _, t := range g.Traps {
+		c := trapDebugColors[t.Kind]
+		alpha := float32(0.7)
+		if t.Spent {
+			alpha = 0.25
+		}
+		xi, yi := g.cartesianToIso(float64(t.TileX), float64(t.TileY))
+		op := g.getDrawOp(xi, yi, scale, cx, cy)
+		op.ColorScale.Scale(c[0], c[1], c[2], alpha)
+		target.DrawImage(g.highlightImage, op)
+	}
+}
+
 func (g *Game) drawPathPreview(target *ebiten.Image, scale, cx, cy float64) {
 	if g.player == nil {
 		return
@@ -0,0 +1,187 @@
+package game
+
+import (
+	"dungeoneer/levels"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// encounterDir holds JSON files, each an array of EncounterTemplates.
+const encounterDir = "encounters"
+
+var (
+	validSlotPositions = []string{"room_center", "room_back", "room_front", "room_edges", "room_scattered"}
+	validRoomTags      = []levels.RoomTag{
+		levels.TagSpawn, levels.TagExit, levels.TagBossArena, levels.TagSanctuary, levels.TagTreasure,
+		levels.TagGuardPost, levels.TagBarracks, levels.TagAmbush, levels.TagCrossroads, levels.TagDeadEnd,
+		levels.TagSecret, levels.TagCommon, levels.TagDecorated, levels.TagLoot, levels.TagCleared, levels.TagDark,
+		levels.TagOptional,
+	}
+)
+
+// smallestRoom is the smallest room of each size class the generator carves
+// (rooms are at least 6x6), used to check that a template fits MinRoomSize.
+var smallestRoom = map[levels.RoomSize][2]int{
+	levels.RoomSmall:  {6, 6},
+	levels.RoomMedium: {7, 7},
+	levels.RoomLarge:  {10, 10},
+}
+
+// LoadEncounters reads every .json file in dir, validates each template and
+// replaces encounterTemplates with the valid ones, sorted by ID so selection
+// is stable for a given run seed. Biomes must be loaded first so per-biome
+// pools can be checked. Invalid templates are reported and skipped; with no
+// templates at all, floors fall back to the legacy spawner.
+func LoadEncounters(dir string) error {
+	encounterTemplates = nil
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("encounters: readdir %s: %w", dir, err)
+	}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("encounters: read %s: %v\n", path, err)
+			continue
+		}
+		var templates []EncounterTemplate
+		if err := json.Unmarshal(data, &templates); err != nil {
+			fmt.Printf("encounters: parse %s: %v\n", path, err)
+			continue
+		}
+		for _, t := range templates {
+			errs := ValidateEncounter(t)
+			if seen[t.ID] {
+				errs = append(errs, fmt.Errorf("duplicate id"))
+			}
+			if len(errs) > 0 {
+				for _, err := range errs {
+					fmt.Printf("encounters: %s: %s: %v\n", path, t.ID, err)
+				}
+				continue
+			}
+			seen[t.ID] = true
+			encounterTemplates = append(encounterTemplates, t)
+		}
+	}
+	sort.Slice(encounterTemplates, func(i, j int) bool { return encounterTemplates[i].ID < encounterTemplates[j].ID })
+	return nil
+}
+
+// ValidateEncounter checks a template's fields and that every enemy it
+// spawns can be placed by resolvePosition in the smallest room allowed by its
+// MinRoomSize.
+func ValidateEncounter(t EncounterTemplate) []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if t.ID == "" {
+		fail("missing id")
+	}
+	if t.Weight <= 0 {
+		fail("weight must be positive")
+	}
+	if t.MinFloor < 1 {
+		fail("min_floor must be at least 1")
+	}
+	if t.MaxFloor != 0 && t.MaxFloor < t.MinFloor {
+		fail("max_floor %d is below min_floor %d", t.MaxFloor, t.MinFloor)
+	}
+	if _, ok := smallestRoom[t.MinRoomSize]; !ok {
+		fail("unknown min_room_size %q", t.MinRoomSize)
+	}
+	for _, b := range t.Biomes {
+		if _, ok := BiomeConfigs[b]; !ok {
+			fail("unknown biome %q", b)
+		}
+	}
+	for _, tag := range t.RoomTags {
+		if !containsTag(validRoomTags, tag) {
+			fail("unknown room tag %q", tag)
+		}
+	}
+	if len(t.Enemies) == 0 {
+		fail("no enemies")
+	}
+	for i, s := range t.Enemies {
+		if !contains(validEnemyRoles, s.Role) {
+			fail("slot %d: unknown role %q", i, s.Role)
+		}
+		if !contains(validSlotPositions, s.Position) {
+			fail("slot %d: unknown position %q", i, s.Position)
+		}
+		if s.Behavior != "" && !contains(validEnemyBehaviors, s.Behavior) {
+			fail("slot %d: unknown behavior %q", i, s.Behavior)
+		}
+		if s.Count < 0 {
+			fail("slot %d: negative count", i)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if placed, want := fitTemplate(t); placed < want {
+		size := smallestRoom[t.MinRoomSize]
+		fail("only %d of %d enemies fit a %dx%d %s room", placed, want, size[0], size[1], t.MinRoomSize)
+	}
+	return errs
+}
+
+// fitTemplate places the template into an open room of the smallest size its
+// MinRoomSize allows and returns how many enemies were placed. Placement is
+// random, so the worst of several attempts is reported.
+func fitTemplate(t EncounterTemplate) (placed, want int) {
+	size := smallestRoom[t.MinRoomSize]
+	w, h := size[0], size[1]
+	lvl := levels.NewEmptyLevel(w+2, h+2)
+	for y := 1; y <= h; y++ {
+		for x := 1; x <= w; x++ {
+			lvl.Tiles[y][x].IsWalkable = true
+		}
+	}
+	room := &levels.Room{X: 1, Y: 1, W: w, H: h, CenterX: 1 + w/2, CenterY: 1 + h/2, Size: levels.ClassifyRoomSize(w, h)}
+
+	want = totalTemplateEnemies(t)
+	placed = want
+	for attempt := uint64(0); attempt < 8; attempt++ {
+		rng := rand.New(rand.NewPCG(attempt, attempt^0x5eed))
+		occupied := map[[2]int]bool{}
+		n := 0
+		for _, slot := range t.Enemies {
+			for j := 0; j < slotEnemyCount(slot); j++ {
+				x, y, ok := resolvePosition(room, slot.Position, lvl, occupied, rng)
+				if !ok {
+					continue
+				}
+				occupied[[2]int{x, y}] = true
+				n++
+			}
+		}
+		if n < placed {
+			placed = n
+		}
+	}
+	return placed, want
+}
+
+func containsTag(list []levels.RoomTag, tag levels.RoomTag) bool {
+	for _, v := range list {
+		if v == tag {
+			return true
+		}
+	}
+	return false
+}
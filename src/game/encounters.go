@@ -8,86 +8,37 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
-// EncounterTemplate defines a reusable enemy placement pattern.
+// EncounterTemplate defines a reusable enemy placement pattern. Templates are
+// loaded from JSON files in the encounters directory; see LoadEncounters.
 type EncounterTemplate struct {
-	ID          string
-	MinFloor    int
-	MaxFloor    int             // 0 = no limit
-	MinRoomSize levels.RoomSize // minimum room size to fit this encounter
-	Enemies     []EnemySlot
-	Weight      float64 // selection probability weight
+	ID          string           `json:"id"`
+	Biomes      []Biome          `json:"biomes,omitempty"` // empty = every biome
+	MinFloor    int              `json:"min_floor"`
+	MaxFloor    int              `json:"max_floor,omitempty"` // 0 = no limit
+	MinRoomSize levels.RoomSize  `json:"min_room_size"`       // minimum room size to fit this encounter
+	RoomTags    []levels.RoomTag `json:"room_tags,omitempty"` // room must carry one of these; empty = any room
+	Enemies     []EnemySlot      `json:"enemies"`
+	Weight      float64          `json:"weight"` // selection probability weight
 }
 
 // EnemySlot describes one or more enemies within an encounter template.
 type EnemySlot struct {
-	Role     string // "melee", "ranged", "elite", "swarm", "caster", "ambush"
-	Position string // "room_center", "room_back", "room_front", "room_edges", "room_scattered"
-	Behavior string // override: "ambush", "roaming", "patrol", "ranged", "swarm", "" = use EnemyDef default
-	Count    int    // for multi-spawn slots (default 1)
+	Role     string `json:"role"`               // "melee", "ranged", "elite", "swarm", "caster", "ambush"
+	Position string `json:"position"`           // "room_center", "room_back", "room_front", "room_edges", "room_scattered"
+	Behavior string `json:"behavior,omitempty"` // override: "ambush", "roaming", "patrol", "ranged", "swarm", "" = use EnemyDef default
+	Count    int    `json:"count,omitempty"`    // for multi-spawn slots (default 1)
 }
 
-// encounterTemplates defines the built-in encounter patterns.
-var encounterTemplates = []EncounterTemplate{
-	{
-		ID: "solo_guardian", MinFloor: 1, MinRoomSize: levels.RoomSmall, Weight: 3.0,
-		Enemies: []EnemySlot{
-			{Role: "melee", Position: "room_center", Count: 1},
-		},
-	},
-	{
-		ID: "ambush_pair", MinFloor: 1, MinRoomSize: levels.RoomSmall, Weight: 2.0,
-		Enemies: []EnemySlot{
-			{Role: "melee", Position: "room_edges", Behavior: "ambush", Count: 2},
-		},
-	},
-	{
-		ID: "firing_line", MinFloor: 1, MinRoomSize: levels.RoomMedium, Weight: 2.5,
-		Enemies: []EnemySlot{
-			{Role: "ranged", Position: "room_back", Count: 1},
-			{Role: "melee", Position: "room_front", Count: 2},
-		},
-	},
-	{
-		ID: "corridor_patrol", MinFloor: 1, MinRoomSize: levels.RoomSmall, Weight: 1.5,
-		Enemies: []EnemySlot{
-			{Role: "melee", Position: "room_center", Behavior: "patrol", Count: 1},
-		},
-	},
-	{
-		ID: "swarm_room", MinFloor: 2, MinRoomSize: levels.RoomMedium, Weight: 2.0,
-		Enemies: []EnemySlot{
-			{Role: "swarm", Position: "room_scattered", Count: 5},
-		},
-	},
-	{
-		ID: "elite_and_adds", MinFloor: 3, MinRoomSize: levels.RoomLarge, Weight: 1.0,
-		Enemies: []EnemySlot{
-			{Role: "elite", Position: "room_center", Count: 1},
-			{Role: "melee", Position: "room_edges", Count: 2},
-		},
-	},
-	{
-		ID: "caster_den", MinFloor: 2, MinRoomSize: levels.RoomMedium, Weight: 2.0,
-		Enemies: []EnemySlot{
-			{Role: "caster", Position: "room_back", Behavior: "caster", Count: 1},
-			{Role: "melee", Position: "room_front", Count: 1},
-		},
-	},
-	{
-		ID: "ranged_nest", MinFloor: 1, MinRoomSize: levels.RoomSmall, Weight: 1.5,
-		Enemies: []EnemySlot{
-			{Role: "ranged", Position: "room_center", Count: 2},
-		},
-	},
-}
+// encounterTemplates holds every loaded encounter pattern.
+var encounterTemplates []EncounterTemplate
 
 // enemyBudget returns the max enemies for a floor.
-func enemyBudget(floorNumber int) int {
+func enemyBudget(floorNumber int, rng *rand.Rand) int {
 	b := 8 + floorNumber*3
 	if b > 25 {
 		b = 25
 	}
-	return b + rand.IntN(4)
+	return b + rng.IntN(4)
 }
 
 // slotEnemyCount returns the actual count for a slot (defaults to 1).
@@ -107,12 +58,10 @@ func totalTemplateEnemies(t EncounterTemplate) int {
 	return n
 }
 
-// eligibleTemplates filters templates by floor number and room size.
-func eligibleTemplates(floor int, roomSize levels.RoomSize) []EncounterTemplate {
-	sizeRank := map[levels.RoomSize]int{
-		levels.RoomSmall: 0, levels.RoomMedium: 1, levels.RoomLarge: 2,
-	}
-	roomRank := sizeRank[roomSize]
+// eligibleTemplates filters templates by floor number, biome, room size and
+// room tags.
+func eligibleTemplates(floor int, biome Biome, room *levels.Room) []EncounterTemplate {
+	roomRank := roomSizeRank[room.Size]
 
 	var out []EncounterTemplate
 	for _, t := range encounterTemplates {
@@ -122,7 +71,13 @@ func eligibleTemplates(floor int, roomSize levels.RoomSize) []EncounterTemplate
 		if t.MaxFloor > 0 && floor > t.MaxFloor {
 			continue
 		}
-		if sizeRank[t.MinRoomSize] > roomRank {
+		if roomSizeRank[t.MinRoomSize] > roomRank {
+			continue
+		}
+		if len(t.Biomes) > 0 && !containsBiome(t.Biomes, biome) {
+			continue
+		}
+		if len(t.RoomTags) > 0 && !roomHasAnyTag(room, t.RoomTags) {
 			continue
 		}
 		out = append(out, t)
@@ -130,8 +85,30 @@ func eligibleTemplates(floor int, roomSize levels.RoomSize) []EncounterTemplate
 	return out
 }
 
+var roomSizeRank = map[levels.RoomSize]int{
+	levels.RoomSmall: 0, levels.RoomMedium: 1, levels.RoomLarge: 2,
+}
+
+func containsBiome(list []Biome, b Biome) bool {
+	for _, v := range list {
+		if v == b {
+			return true
+		}
+	}
+	return false
+}
+
+func roomHasAnyTag(room *levels.Room, tags []levels.RoomTag) bool {
+	for _, t := range tags {
+		if room.HasTag(t) {
+			return true
+		}
+	}
+	return false
+}
+
 // pickTemplate does weighted random selection from eligible templates.
-func pickTemplate(templates []EncounterTemplate) *EncounterTemplate {
+func pickTemplate(templates []EncounterTemplate, rng *rand.Rand) *EncounterTemplate {
 	if len(templates) == 0 {
 		return nil
 	}
@@ -139,7 +116,7 @@ func pickTemplate(templates []EncounterTemplate) *EncounterTemplate {
 	for _, t := range templates {
 		total += t.Weight
 	}
-	r := rand.Float64() * total
+	r := rng.Float64() * total
 	for i := range templates {
 		r -= templates[i].Weight
 		if r <= 0 {
@@ -151,13 +128,13 @@ func pickTemplate(templates []EncounterTemplate) *EncounterTemplate {
 
 // resolvePosition picks a walkable tile within the room for a position string.
 // occupied tracks used tiles to prevent stacking.
-func resolvePosition(room *levels.Room, pos string, level *levels.Level, occupied map[[2]int]bool) (int, int, bool) {
+func resolvePosition(room *levels.Room, pos string, level *levels.Level, occupied map[[2]int]bool, rng *rand.Rand) (int, int, bool) {
 	// Helper: find a random walkable, unoccupied tile in a rect region.
 	findIn := func(x0, y0, x1, y1 int) (int, int, bool) {
 		candidates := [][2]int{}
 		for y := y0; y < y1; y++ {
 			for x := x0; x < x1; x++ {
-				if level.IsWalkable(x, y) && !occupied[[2]int{x, y}] {
+				if level.IsSafe(x, y) && !occupied[[2]int{x, y}] {
 					candidates = append(candidates, [2]int{x, y})
 				}
 			}
@@ -165,7 +142,7 @@ func resolvePosition(room *levels.Room, pos string, level *levels.Level, occupie
 		if len(candidates) == 0 {
 			return 0, 0, false
 		}
-		c := candidates[rand.IntN(len(candidates))]
+		c := candidates[rng.IntN(len(candidates))]
 		return c[0], c[1], true
 	}
 
@@ -173,7 +150,7 @@ func resolvePosition(room *levels.Room, pos string, level *levels.Level, occupie
 	switch pos {
 	case "room_center":
 		// Try center, then nearby.
-		if level.IsWalkable(cx, cy) && !occupied[[2]int{cx, cy}] {
+		if level.IsSafe(cx, cy) && !occupied[[2]int{cx, cy}] {
 			return cx, cy, true
 		}
 		return findIn(cx-2, cy-2, cx+3, cy+3)
@@ -193,7 +170,7 @@ func resolvePosition(room *levels.Room, pos string, level *levels.Level, occupie
 		for y := room.Y; y < room.Y+room.H; y++ {
 			for x := room.X; x < room.X+room.W; x++ {
 				if (x == room.X || x == room.X+room.W-1 || y == room.Y || y == room.Y+room.H-1) &&
-					level.IsWalkable(x, y) && !occupied[[2]int{x, y}] {
+					level.IsSafe(x, y) && !occupied[[2]int{x, y}] {
 					candidates = append(candidates, [2]int{x, y})
 				}
 			}
@@ -201,7 +178,7 @@ func resolvePosition(room *levels.Room, pos string, level *levels.Level, occupie
 		if len(candidates) == 0 {
 			return findIn(room.X, room.Y, room.X+room.W, room.Y+room.H)
 		}
-		c := candidates[rand.IntN(len(candidates))]
+		c := candidates[rng.IntN(len(candidates))]
 		return c[0], c[1], true
 
 	case "room_scattered":
@@ -230,15 +207,34 @@ func makeBehavior(behavior string) entities.MonsterBehavior {
 	}
 }
 
+// doorPolicy returns how a monster gets past closed doors: doors if it names
+// a policy, otherwise the default for its behavior. By default swarms crowd
+// up behind a closed door, though the biomes let some ghostly swarms phase
+// through, and ambushers, the big beasts, break it down.
+func doorPolicy(doors, behavior string) entities.DoorPolicy {
+	if d, ok := entities.DoorPolicyByName(doors); ok {
+		return d
+	}
+	switch behavior {
+	case "swarm":
+		return entities.DoorsBlock
+	case "ambush":
+		return entities.DoorsBash
+	default:
+		return entities.DoorsOpen
+	}
+}
+
 // spawnEncounterMonsters places monsters using the encounter template system.
 func (g *Game) spawnEncounterMonsters(ctx FloorContext) {
-	if ctx.BiomeConfig == nil || len(g.currentLevel.Rooms) == 0 {
-		// Fallback to legacy spawner if no biome config or rooms.
+	if ctx.BiomeConfig == nil || len(ctx.BiomeConfig.EnemyPool) == 0 ||
+		len(encounterTemplates) == 0 || len(g.currentLevel.Rooms) == 0 {
+		// Fallback to legacy spawner if no biome config, templates or rooms.
 		g.spawnFloorMonsters(ctx)
 		return
 	}
 
-	budget := enemyBudget(ctx.FloorNumber)
+	budget := enemyBudget(ctx.FloorNumber, ctx.RNG.Encounters)
 	occupied := map[[2]int]bool{
 		{g.player.TileX, g.player.TileY}: true,
 	}
@@ -256,7 +252,7 @@ func (g *Game) spawnEncounterMonsters(ctx FloorContext) {
 	// Shuffle rooms so placement varies each run.
 	rooms := make([]levels.Room, len(g.currentLevel.Rooms))
 	copy(rooms, g.currentLevel.Rooms)
-	rand.Shuffle(len(rooms), func(i, j int) { rooms[i], rooms[j] = rooms[j], rooms[i] })
+	ctx.RNG.Encounters.Shuffle(len(rooms), func(i, j int) { rooms[i], rooms[j] = rooms[j], rooms[i] })
 
 	// Skip the room containing the player spawn.
 	playerRoom := g.currentLevel.RoomAt(g.player.TileX, g.player.TileY)
@@ -273,8 +269,8 @@ func (g *Game) spawnEncounterMonsters(ctx FloorContext) {
 			continue // sanctuary / NPC rooms are monster-free
 		}
 
-		eligible := eligibleTemplates(ctx.FloorNumber, room.Size)
-		tmpl := pickTemplate(eligible)
+		eligible := eligibleTemplates(ctx.FloorNumber, ctx.Biome, room)
+		tmpl := pickTemplate(eligible, ctx.RNG.Encounters)
 		if tmpl == nil {
 			continue
 		}
@@ -312,7 +308,7 @@ func (g *Game) spawnTemplateInRoom(tmpl *EncounterTemplate, room *levels.Room, c
 		}
 
 		for j := 0; j < count; j++ {
-			x, y, ok := resolvePosition(room, slot.Position, g.currentLevel, occupied)
+			x, y, ok := resolvePosition(room, slot.Position, g.currentLevel, occupied, ctx.RNG.Encounters)
 			if !ok {
 				continue
 			}
@@ -341,10 +337,15 @@ func (g *Game) spawnTemplateInRoom(tmpl *EncounterTemplate, room *levels.Room, c
 				HitRadius:        entities.DefaultMonsterHitRadius,
 				AttackRate:       enemyDef.AttackRate,
 				Behavior:         makeBehavior(behaviorStr),
+				Doors:            doorPolicy(enemyDef.Doors, behaviorStr),
 				Level:            ctx.FloorNumber,
 				Role:             slot.Role,
 			}
 
+			for _, a := range rollAffixes(slot.Role, ctx.Difficulty, ctx.RNG.Encounters) {
+				m.AddAffix(a)
+			}
+
 			// Set patrol waypoints for patrol behavior.
 			if pb, ok := m.Behavior.(*entities.PatrolBehavior); ok {
 				pb.Waypoints = []entities.PatrolWaypoint{
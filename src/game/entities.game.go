@@ -3,6 +3,7 @@ package game
 import (
 	"dungeoneer/entities"
 	"dungeoneer/spells"
+	"dungeoneer/tiles"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -21,6 +22,16 @@ func (g *Game) updateMonsterProjectiles() {
 			g.spawnMonsterSpell(sc)
 		}
 		m.PendingSpells = m.PendingSpells[:0]
+		// Drain door requests (monsters opening or bashing doors).
+		for _, r := range m.PendingDoors {
+			g.handleMonsterDoor(r)
+		}
+		m.PendingDoors = m.PendingDoors[:0]
+		// Drain melee blows landed on the player, for on-damaged effects.
+		for _, dmg := range m.PendingHits {
+			g.playerStruck(m, dmg)
+		}
+		m.PendingHits = m.PendingHits[:0]
 	}
 
 	// Update and check collisions.
@@ -29,6 +40,7 @@ func (g *Game) updateMonsterProjectiles() {
 		p.Update(g.currentLevel)
 		if !p.Finished && !g.player.IsDead && p.HitsPlayer(g.player.TileX, g.player.TileY) {
 			g.player.TakeDamage(p.Damage)
+			g.playerStruck(nil, p.Damage)
 			p.Finished = true
 		}
 		if !p.Finished {
@@ -42,6 +54,35 @@ func (g *Game) updateMonsterProjectiles() {
 	g.MonsterProjectiles = alive
 }
 
+// doorBashHits is how many blows a closed door takes before it breaks open.
+const doorBashHits = 4
+
+// handleMonsterDoor opens the door a monster asked for, or lands its blow on
+// it. Only closed doors give way; a locked one stays shut.
+func (g *Game) handleMonsterDoor(r entities.DoorRequest) {
+	tile := g.currentLevel.Tile(r.X, r.Y)
+	if tile == nil || !tile.HasTag(tiles.TagDoor) || tile.DoorState != 2 {
+		return
+	}
+	if r.Bash {
+		if g.doorBlows == nil {
+			g.doorBlows = map[*tiles.Tile]int{}
+		}
+		g.doorBlows[tile]++
+		g.HitMarkers = append(g.HitMarkers, entities.HitMarker{
+			X: float64(r.X), Y: float64(r.Y), MaxTicks: 30,
+		})
+		if g.doorBlows[tile] < doorBashHits {
+			return
+		}
+		delete(g.doorBlows, tile)
+		if g.isTileVisible(r.X, r.Y) {
+			g.ShowHint("A door bursts open!")
+		}
+	}
+	g.swingDoor(tile)
+}
+
 func (g *Game) drawMonsterProjectiles(target *ebiten.Image, scale, cx, cy float64) {
 	tileSize := g.currentLevel.TileSize
 	for _, p := range g.MonsterProjectiles {
@@ -57,6 +98,8 @@ func (g *Game) spawnMonsterSpell(sc entities.PendingSpellCast) {
 		fb := spells.NewFireball(info, sc.OriginX, sc.OriginY, sc.TargetX, sc.TargetY, g.fireballSprites, g.spriteSheet.FireBurst)
 		fb.MonsterCast = true
 		g.ActiveSpells = append(g.ActiveSpells, fb)
+	case "blink":
+		g.ActiveSpells = append(g.ActiveSpells, spells.NewBlinkEffect(sc.OriginX, sc.OriginY, sc.TargetX, sc.TargetY))
 	}
 }
 
@@ -37,6 +37,7 @@ type Game struct {
 	LoadPlayerMenu *ui.LoadPlayerMenu
 	SaveLevelMenu  *ui.SaveLevelMenu
 	SavePrompt     *ui.TextInputMenu
+	SeedPrompt     *ui.TextInputMenu
 	GenerateMenu   *ui.GenerateLevelMenu
 	ProcGenMenu    *ui.ProcGenMenu
 	LinkPrompt     *ui.LayerPrompt
@@ -77,6 +78,14 @@ type Game struct {
 	ControlsMenu    *ui.ControlsMenu
 
 	Controls *controls.Controls
+	input    InputSource // gameplay input; Ebiten devices unless running headless
+
+	RecordReplays bool            // write a replay of every run to replays/
+	recorder      *replayRecorder // non-nil while a run is being recorded
+	replayInput   *ScriptedInput  // frames left to play back
+	replayEvents  []ReplayEvent   // trades left to play back
+	replayFrame   int             // frames played back so far
+	replaying     bool            // session is a replay; nothing is saved
 
 	ActiveSpells      []spells.Spell
 	ActiveSpray       *spells.ArcaneSpray // currently channeled spray (nil if none)
@@ -99,6 +108,7 @@ type Game struct {
 	ShowDoorDebug            bool
 	ShowHitboxes             bool
 	ShowInteractionRadii     bool
+	ShowTraps                bool
 
 	// Visibility tracking
 	// visibleTick[y][x] stores the gameTick when a tile was last hit by a ray.
@@ -112,6 +122,7 @@ type Game struct {
 
 	// cooldown timer to prevent immediate re-triggering of stair links
 	layerSwitchCooldown float64
+	layerStates         map[*levels.Level]*layerState // generated floors: layers the player is not on
 
 	hintTimer int
 	hint      string
@@ -130,14 +141,24 @@ type Game struct {
 	BossRoom           *levels.Room // arena room on boss floor
 
 	// Phase 3
-	NPCs          []*entities.NPC
-	DialoguePanel *ui.DialoguePanel
+	NPCs           []*entities.NPC
+	DialoguePanel  *ui.DialoguePanel
+	ShopMenu       *ui.ShopMenu
+	shopStock      []*items.Item // merchant wares, rolled once per floor
+	shopStockRun   int           // Meta.RunCount the wares were rolled for
+	shopStockFloor int           // floor the wares were rolled for, 0 in the hub
 
 	// Phase 4F
 	Chests []*entities.Chest
+	Traps  []*entities.Trap
 
 	// Boss floor announcement overlay: counts down from 240 when a boss floor is entered.
 	bossFloorAnnouncement int
+
+	// flow leads monsters to the player; see updateMonsters.
+	flow pathing.FlowField
+	// doorBlows counts the blows landed on each door being bashed in.
+	doorBlows map[*tiles.Tile]int
 }
 
 const (
@@ -169,7 +190,24 @@ const (
 	StateVictoryScreen
 )
 
+// NewGame creates the game with its menus and dev tools, starting in the
+// main menu with the level editor active.
 func NewGame() (*Game, error) {
+	g, err := newGameState()
+	if err != nil {
+		return nil, err
+	}
+	if err := g.initMenus(); err != nil {
+		return nil, err
+	}
+	g.editor.Active = true // or toggle with key
+	return g, nil
+}
+
+// newGameState loads the game data and builds the world, player and HUD
+// state that Update runs on. It creates no menus or dev tools; a Game
+// without them runs the simulation but can't be navigated by a player.
+func newGameState() (*Game, error) {
 	ss, err := sprites.LoadSpriteSheet(constants.DefaultTileSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load sprite sheet: %s", err)
@@ -197,6 +235,18 @@ func NewGame() (*Game, error) {
 	// Load dialogue trees from JSON files (non-fatal if directory missing).
 	_ = dialogue.LoadAll("dialogues")
 
+	// Load biome definitions; falls back to a bare Crypt biome on failure.
+	if err := LoadBiomes(biomeDir, BuildSpriteMap(ss)); err != nil {
+		fmt.Println(err)
+	}
+	// Encounter templates are validated against the loaded biomes.
+	if err := LoadEncounters(encounterDir); err != nil {
+		fmt.Println(err)
+	}
+	if err := LoadPrefabs(prefabDir); err != nil {
+		fmt.Println(err)
+	}
+
 	g := &Game{
 		currentWorld:    world,
 		currentLevel:    l,
@@ -222,22 +272,46 @@ func NewGame() (*Game, error) {
 		camSmooth:       0.1,
 		SpellDebug:      true,
 		Controls:        controls.New(),
+		RecordReplays:   true,
 	}
+	g.input = ebitenInput{ctrl: g.Controls}
 	// Load saved control bindings if they exist
 	if err := g.Controls.LoadBindings(); err == nil {
 		fmt.Println("Loaded saved control bindings")
 	}
-	g.DevMenu = ui.NewDevMenu(640, 480, g.player, g.ShowHint)
-	g.DevTools = ui.NewDevOverlay(640, 480, g.buildDevEntries())
-	g.ControlsMenu = ui.NewControlsMenu(640, 480, g.Controls, func() {})
 	g.editor.OnLayerChange = g.editorLayerChanged
 	g.editor.OnStairPlaced = g.stairPlaced
 	g.SpriteMap = BuildSpriteMap(ss)
 	g.spawnEntitiesFromLevel()
 	g.lastPlayerTileX, g.lastPlayerTileY = g.player.TileX, g.player.TileY
+
+	g.visibleTick = make([][]int, g.currentLevel.H)
+	g.SeenTiles = make([][]bool, g.currentLevel.H)
+	for y := range g.visibleTick {
+		g.visibleTick[y] = make([]int, g.currentLevel.W)
+		g.SeenTiles[y] = make([]bool, g.currentLevel.W)
+	}
+
+	// Load meta progression
+	g.Meta = LoadMeta()
+
+	g.HUD = hud.New()
+	g.ShowHUD = true
+	panelRect := image.Rect(g.w/2-150, g.h/2-150, g.w/2+150, g.h/2+150)
+	g.HeroPanel = ui.NewHeroPanel(panelRect, g.player)
+	g.InventoryScreen = ui.NewInventoryScreen()
+
+	return g, nil
+}
+
+// initMenus creates the main, pause and editor menus and the dev tools.
+func (g *Game) initMenus() error {
+	g.DevMenu = ui.NewDevMenu(640, 480, g.player, g.ShowHint)
+	g.DevTools = ui.NewDevOverlay(640, 480, g.buildDevEntries())
+	g.ControlsMenu = ui.NewControlsMenu(640, 480, g.Controls, func() {})
 	mm, err := ui.NewMainMenu()
 	if err != nil {
-		return nil, fmt.Errorf("failed create new main menu: %s", err)
+		return fmt.Errorf("failed create new main menu: %s", err)
 	}
 	// Main Menu
 	g.Menu = mm
@@ -298,7 +372,7 @@ func NewGame() (*Game, error) {
 	})
 	g.GenerateMenu = ui.NewGenerateLevelMenu(g.w, g.h,
 		func() {
-			newLevel := levels.CreateNewBlankLevel(64, 64, g.currentLevel.TileSize, ss)
+			newLevel := levels.CreateNewBlankLevel(64, 64, g.currentLevel.TileSize, g.spriteSheet)
 			newWorld := levels.NewLayeredLevel(newLevel)
 			g.currentWorld = newWorld
 			g.currentLevel = newLevel
@@ -314,8 +388,14 @@ func NewGame() (*Game, error) {
 	// Pause Menu — use 640x480 as the initial size; Layout() will resize correctly
 	// on the first tick. We must not use l.W/l.H here (those are tile counts, not pixels).
 	pm := ui.NewPauseMenu(640, 480, g.Controls, ui.PauseMenuCallbacks{
-		OnResume:     func() { g.resumeGame() },
-		OnExit:       func() { os.Exit(0) },
+		OnResume: func() { g.resumeGame() },
+		OnExit: func() {
+			if err := g.SaveRun(); err != nil {
+				fmt.Println("Error saving run:", err)
+			}
+			g.finishRecording()
+			os.Exit(0)
+		},
 		OnLoadLevel:  func() { menumanager.Manager().Open(g.LoadLevelMenu) },
 		OnLoadPlayer: func() { menumanager.Manager().Open(g.LoadPlayerMenu) },
 		OnGenerate:   func() { menumanager.Manager().Open(g.GenerateMenu) },
@@ -368,26 +448,7 @@ func NewGame() (*Game, error) {
 	})
 	g.PauseMenu = pm
 	menumanager.Init(pm)
-
-	g.visibleTick = make([][]int, g.currentLevel.H)
-	g.SeenTiles = make([][]bool, g.currentLevel.H)
-	for y := range g.visibleTick {
-		g.visibleTick[y] = make([]int, g.currentLevel.W)
-		g.SeenTiles[y] = make([]bool, g.currentLevel.W)
-	}
-
-	// Load meta progression
-	g.Meta = LoadMeta()
-
-	g.editor.Active = true // or toggle with key
-
-	g.HUD = hud.New()
-	g.ShowHUD = true
-	panelRect := image.Rect(g.w/2-150, g.h/2-150, g.w/2+150, g.h/2+150)
-	g.HeroPanel = ui.NewHeroPanel(panelRect, g.player)
-	g.InventoryScreen = ui.NewInventoryScreen()
-
-	return g, nil
+	return nil
 }
 
 // setPlayer replaces the active player and rebinds UI components that hold player pointers.
@@ -435,7 +496,7 @@ func (g *Game) AddItemToPlayer(it *items.Item) bool {
 	if g.player == nil {
 		return false
 	}
-	if g.player.Inventory.AddItem(it) {
+	if g.player.AddToInventory(it) {
 		return true
 	}
 	g.ShowHint("Inventory full")
@@ -487,6 +548,7 @@ func (g *Game) cartesianToIso(x, y float64) (float64, float64) {
 // switchLayer activates the given layer and moves the player to the entry tile.
 func (g *Game) switchLayer(index int, entry levels.Point) {
 	orig := entry
+	from, left := g.currentLevel, g.stashLayer()
 	g.currentWorld.SwitchToLayer(index, entry)
 	g.currentLevel = g.currentWorld.ActiveLayer()
 	if g.currentLevel == nil {
@@ -523,13 +585,29 @@ func (g *Game) switchLayer(index int, entry levels.Point) {
 		g.editor.SetActiveLayerSilently(index)
 	}
 	g.layerSwitchCooldown = 1.0
-	g.UpdateSeenTiles(*g.currentLevel)
-	g.spawnEntitiesFromLevel()
+	if st, ok := g.layerStates[g.currentLevel]; ok {
+		// Generated floor: park the layer we left and pick this one up
+		// where the player last saw it.
+		delete(g.layerStates, g.currentLevel)
+		g.layerStates[from] = left
+		g.unstashLayer(st)
+		g.ActiveSpells = nil
+		if g.FloorCtx != nil {
+			if name := g.FloorCtx.sublayerName(index); name != "" {
+				g.ShowHint(name)
+			}
+		}
+	} else {
+		g.UpdateSeenTiles(*g.currentLevel)
+		g.spawnEntitiesFromLevel()
+	}
 	g.cachedRays = nil
+	g.RaycastWalls = fov.LevelToWalls(g.currentLevel)
+	fov.InvalidateCache()
 	g.camX, g.camY = 0, 0
 }
 func (g *Game) screenToTile() (int, int) {
-	cx, cy := ebiten.CursorPosition()
+	cx, cy := g.input.CursorPosition()
 	worldX := float64(cx)/g.camScale + g.camX
 	worldY := float64(cy)/g.camScale + g.camY
 	tileX := int(worldX) / g.currentLevel.TileSize
@@ -553,6 +631,7 @@ func (g *Game) UpdateSeenTiles(level levels.Level) {
 func (g *Game) spawnEntitiesFromLevel() {
 	g.Monsters = []*entities.Monster{}
 	g.ItemDrops = []*entities.ItemDrop{}
+	g.Traps = []*entities.Trap{}
 	for _, ent := range g.currentLevel.Entities {
 		// skip invalid coordinates to avoid crashes
 		if ent.X < 0 || ent.Y < 0 || ent.X >= g.currentLevel.W || ent.Y >= g.currentLevel.H {
@@ -571,6 +650,11 @@ func (g *Game) spawnEntitiesFromLevel() {
 				it := &items.Item{ItemTemplate: tmpl, Count: 1}
 				g.ItemDrops = append(g.ItemDrops, &entities.ItemDrop{TileX: ent.X, TileY: ent.Y, Item: *it})
 			}
+		case levels.EntityKey:
+			it := items.NewItem(items.KeyID(ent.LockID))
+			g.ItemDrops = append(g.ItemDrops, &entities.ItemDrop{TileX: ent.X, TileY: ent.Y, Item: *it})
+		case levels.EntityTrap:
+			g.Traps = append(g.Traps, g.newTrap(ent))
 		}
 	}
 }
@@ -638,6 +722,20 @@ func (g *Game) isoToCartesian(x, y float64) (float64, float64) {
 }
 
 func (g *Game) Update() error {
+	if src, ok := g.input.(tickedInput); ok {
+		src.Advance()
+	}
+	switch {
+	case g.recorder != nil:
+		return g.recordTick()
+	case g.replayInput != nil:
+		return g.replayTick()
+	}
+	return g.update()
+}
+
+// update runs one fixed timestep of whichever screen is active.
+func (g *Game) update() error {
 	if g.hintTimer > 0 {
 		g.hintTimer--
 	}
@@ -703,7 +801,9 @@ func (g *Game) updateCameraFollow() {
 }
 
 func (g *Game) updateMainMenu() error {
-	g.Menu.Update()
+	if g.Menu != nil {
+		g.Menu.Update()
+	}
 	g.handleMainMenuInput()
 	return nil
 }
@@ -719,6 +819,8 @@ func (g *Game) updatePlaying() error {
 	if g.isPaused {
 		if g.SavePrompt != nil && g.SavePrompt.IsVisible() {
 			g.SavePrompt.Update()
+		} else if g.SeedPrompt != nil && g.SeedPrompt.IsVisible() {
+			g.SeedPrompt.Update()
 		} else if g.LoadLevelMenu != nil && g.LoadLevelMenu.Menu.IsVisible() {
 			g.LoadLevelMenu.Update()
 		} else if g.LoadPlayerMenu != nil && g.LoadPlayerMenu.Menu.IsVisible() {
@@ -727,7 +829,9 @@ func (g *Game) updatePlaying() error {
 			g.GenerateMenu.Update()
 		} else if g.ProcGenMenu != nil && g.ProcGenMenu.IsVisible() {
 			g.ProcGenMenu.Update()
-		} else {
+		} else if g.ShopMenu != nil && g.ShopMenu.IsVisible() {
+			g.ShopMenu.Update()
+		} else if g.PauseMenu != nil {
 			g.PauseMenu.Update()
 		}
 		return nil
@@ -798,10 +902,13 @@ func (g *Game) updatePlaying() error {
 		g.player.PathPreview = path
 		prevX, prevY := g.lastPlayerTileX, g.lastPlayerTileY
 		g.player.Update(g.currentLevel, g.DeltaTime)
-		if g.player.TileX != prevX || g.player.TileY != prevY {
+		entered := g.player.TileX != prevX || g.player.TileY != prevY
+		if entered {
 			g.pickupItemsAt(g.player.TileX, g.player.TileY)
 			g.lastPlayerTileX, g.lastPlayerTileY = g.player.TileX, g.player.TileY
 		}
+		g.applyPlayerHazard(entered)
+		g.updateTraps(g.DeltaTime)
 		g.updateCameraFollow()
 
 		// Dev cheats.
@@ -830,9 +937,10 @@ func (g *Game) updatePlaying() error {
 			g.HUD.ExpNeeded = progression.EXPToLevel(g.player.Level)
 			g.HUD.Gold = g.player.Gold
 			g.syncHUDSpellSlots()
+			g.syncHUDBelt()
 		}
 		if g.HeroPanel != nil {
-			g.HeroPanel.Update()
+			g.HeroPanel.Update(g.panelInput())
 		}
 		// Check layer links
 		if g.layerSwitchCooldown <= 0 {
@@ -847,9 +955,7 @@ func (g *Game) updatePlaying() error {
 	}
 
 	// Monsters
-	for _, m := range g.Monsters {
-		m.Update(g.player, g.currentLevel)
-	}
+	g.updateMonsters()
 
 	// Monster projectiles
 	g.updateMonsterProjectiles()
@@ -925,7 +1031,7 @@ func (g *Game) updatePlaying() error {
 			hy := int((isoY+g.camY)*g.camScale + float64(g.h/2) - 32)
 			g.ShowHintAt("[E] Enter the Dungeon", hx, hy)
 			if g.isActionJustPressed(controls.ActionInteract) {
-				g.StartRun()
+				g.openSeedPrompt()
 			}
 		}
 	}
@@ -993,6 +1099,9 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 	if g.ProcGenMenu != nil {
 		g.ProcGenMenu.SetRect(newRect)
 	}
+	if g.ShopMenu != nil {
+		g.ShopMenu.SetRect(image.Rect(g.w/2-210, g.h/2-240, g.w/2+210, g.h/2+240))
+	}
 
 	if g.HeroPanel != nil {
 		panel := image.Rect(g.w/2-150, g.h/2-150, g.w/2+150, g.h/2+150)
@@ -1070,9 +1179,8 @@ func (g *Game) openDoor(x, y int) bool {
 
 	// Open the door (only unlocked doors can be opened)
 	if tile.DoorState == 2 {
-		tile.DoorState = 1
-		tile.IsWalkable = true
-		g.setDoorSprite(tile, false)
+		g.swingDoor(tile)
+		g.emitNoise(x, y, doorNoiseRadius)
 
 		g.ShowHint("Door opened")
 		return true
@@ -1081,6 +1189,14 @@ func (g *Game) openDoor(x, y int) bool {
 	return false
 }
 
+// swingDoor opens a closed door tile. It is how both the player and
+// monsters open doors.
+func (g *Game) swingDoor(tile *tiles.Tile) {
+	tile.DoorState = 1
+	tile.IsWalkable = true
+	g.setDoorSprite(tile, false)
+}
+
 // unlockDoor attempts to unlock a locked door and open it.
 // Returns true if the door was unlocked/opened.
 func (g *Game) unlockDoor(x, y int) bool {
@@ -1094,6 +1210,14 @@ func (g *Game) unlockDoor(x, y int) bool {
 	if tile.DoorState != 3 {
 		return false
 	}
+	// Doors in the floor's lock graph need their key; doors without a
+	// lock record (hand-made levels) open as before.
+	if lk := g.currentLevel.LockByDoor(x, y); lk != nil && g.player != nil && g.player.Inventory != nil {
+		if !g.player.Inventory.RemoveItem(items.KeyID(lk.ID)) {
+			g.ShowHint("The door is sealed. Find its sigil.")
+			return false
+		}
+	}
 	tile.DoorState = 2 // unlock (closed)
 	tile.IsWalkable = false
 	g.setDoorSprite(tile, true)
@@ -9,22 +9,22 @@ import (
 	"dungeoneer/pathing"
 	"dungeoneer/spells"
 	"dungeoneer/tiles"
+	"fmt"
 	"math"
 	"os"
 	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 // isActionPressed returns true while the bound key is held down.
 func (g *Game) isActionPressed(action controls.ActionID) bool {
-	return ebiten.IsKeyPressed(g.Controls.GetBinding(action).Primary)
+	return g.input.ActionPressed(action)
 }
 
 // isActionJustPressed returns true on the first frame the bound key is pressed.
 func (g *Game) isActionJustPressed(action controls.ActionID) bool {
-	return inpututil.IsKeyJustPressed(g.Controls.GetBinding(action).Primary)
+	return g.input.ActionJustPressed(action)
 }
 
 func (g *Game) handleMainMenuInput() {
@@ -33,15 +33,16 @@ func (g *Game) handleMainMenuInput() {
 	}
 
 	// Mouse hover and click handling
-	mx, my := ebiten.CursorPosition()
+	mx, my := g.input.CursorPosition()
 	for i, r := range g.Menu.EntryRects {
 		if mx >= r.Min.X && mx <= r.Max.X && my >= r.Min.Y && my <= r.Max.Y {
 			g.Menu.SelectedIndex = i
-			if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			if g.input.MouseJustPressed(ebiten.MouseButtonLeft) {
 				switch g.Menu.Options[i] {
 				case "Continue":
-					g.loadHub()
+					g.continueGame()
 				case "New Game":
+					DeleteRunSave()
 					g.loadHub()
 				case "Load Game":
 					// TODO: Show load game menu
@@ -57,11 +58,12 @@ func (g *Game) handleMainMenuInput() {
 	}
 
 	// Confirm selection
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+	if g.input.KeyJustPressed(ebiten.KeyEnter) || g.input.KeyJustPressed(ebiten.KeySpace) {
 		switch g.Menu.Options[g.Menu.SelectedIndex] {
 		case "Continue":
-			g.loadHub()
+			g.continueGame()
 		case "New Game":
+			DeleteRunSave()
 			g.loadHub()
 		case "Load Game":
 			// TODO: Show load game menu
@@ -73,8 +75,21 @@ func (g *Game) handleMainMenuInput() {
 	}
 }
 
+// continueGame resumes a saved mid-run floor if one exists, otherwise it
+// drops the player into the hub.
+func (g *Game) continueGame() {
+	if HasRunSave() {
+		err := g.ContinueRun()
+		if err == nil {
+			return
+		}
+		fmt.Println("Error loading run save:", err)
+	}
+	g.loadHub()
+}
+
 func (g *Game) handlePause() {
-	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+	if g.input.KeyJustPressed(ebiten.KeyEscape) {
 		// Close editor palettes first
 		if g.editor.PaletteOpen {
 			g.editor.TogglePalette()
@@ -96,12 +111,12 @@ func (g *Game) resumeGame() {
 
 func (g *Game) handleZoom() {
 	var scrollY float64
-	if ebiten.IsKeyPressed(ebiten.KeyPageDown) {
+	if g.input.KeyPressed(ebiten.KeyPageDown) {
 		scrollY = -0.25
-	} else if ebiten.IsKeyPressed(ebiten.KeyPageUp) {
+	} else if g.input.KeyPressed(ebiten.KeyPageUp) {
 		scrollY = 0.25
 	} else {
-		_, scrollY = ebiten.Wheel()
+		_, scrollY = g.input.Wheel()
 		scrollY = math.Max(-1, math.Min(1, scrollY))
 	}
 	g.camScaleTo += scrollY * (g.camScaleTo / 7)
@@ -117,24 +132,24 @@ func (g *Game) handleZoom() {
 
 func (g *Game) handlePan() {
 	pan := 7.0 / g.camScale
-	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+	if g.input.KeyPressed(ebiten.KeyArrowLeft) {
 		g.camX -= pan
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+	if g.input.KeyPressed(ebiten.KeyArrowRight) {
 		g.camX += pan
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
+	if g.input.KeyPressed(ebiten.KeyArrowDown) {
 		g.camY -= pan
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
+	if g.input.KeyPressed(ebiten.KeyArrowUp) {
 		g.camY += pan
 	}
 
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButton3) {
+	if g.input.MousePressed(ebiten.MouseButton3) {
 		if g.mousePanX == math.MinInt32 {
-			g.mousePanX, g.mousePanY = ebiten.CursorPosition()
+			g.mousePanX, g.mousePanY = g.input.CursorPosition()
 		} else {
-			x, y := ebiten.CursorPosition()
+			x, y := g.input.CursorPosition()
 			dx := float64(g.mousePanX - x)
 			dy := float64(g.mousePanY - y)
 			g.camX -= dx * (pan / 100)
@@ -153,7 +168,7 @@ func (g *Game) handlePan() {
 
 func (g *Game) handleClicks() {
 	// Handle player movement (right-click)
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+	if g.input.MouseJustPressed(ebiten.MouseButtonRight) {
 		tx, ty := g.hoverTileX, g.hoverTileY
 		if g.player.CanMoveTo(tx, ty, g.currentLevel) {
 			path := pathing.AStar(g.currentLevel, g.player.TileX, g.player.TileY, tx, ty)
@@ -165,11 +180,8 @@ func (g *Game) handleClicks() {
 	}
 
 	// Handle player attacking monster or opening doors
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		mx, my := ebiten.CursorPosition()
-		worldX := (float64(mx)-float64(g.w/2))/g.camScale + g.camX
-		worldY := (float64(my)-float64(g.h/2))/g.camScale - g.camY
-		tx, ty := g.isoToCartesian(worldX, worldY)
+	if g.input.MouseJustPressed(ebiten.MouseButtonLeft) {
+		tx, ty := g.cursorWorld()
 
 		cx := int(math.Floor(tx - 1.5))
 		cy := int(math.Floor(ty - 0.5))
@@ -198,10 +210,7 @@ func (g *Game) handleClicks() {
 }
 
 func (g *Game) handleHoverTile() {
-	mx, my := ebiten.CursorPosition()
-	cx := (float64(mx)-float64(g.w/2))/g.camScale + g.camX
-	cy := (float64(my)-float64(g.h/2))/g.camScale - g.camY
-	tx, ty := g.isoToCartesian(cx, cy)
+	tx, ty := g.cursorWorld()
 	// These offsets align the hover tile with visual center of diamond tiles
 	g.hoverTileX = int(math.Floor(tx - 1.5))
 	g.hoverTileY = int(math.Floor(ty - 0.5))
@@ -235,13 +244,13 @@ func (g *Game) handleLevelHotkeys() {
 		g.ShowHUD = !g.ShowHUD
 	}
 	// F3 — toggle level editor (hardcoded dev shortcut, not in player controls)
-	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+	if g.input.KeyJustPressed(ebiten.KeyF3) {
 		if g.editor != nil {
 			g.editor.Active = !g.editor.Active
 		}
 	}
 	// F12 — open/close dev tools overlay
-	if inpututil.IsKeyJustPressed(ebiten.KeyF12) {
+	if g.input.KeyJustPressed(ebiten.KeyF12) {
 		if g.DevTools != nil {
 			g.DevTools.Toggle()
 		}
@@ -261,7 +270,8 @@ func (g *Game) handleLevelHotkeys() {
 			g.castSpellSlot(i)
 		}
 	}
-	if g.State == StateGameOver && ebiten.IsKeyPressed(ebiten.KeyV) {
+	g.handleBelt()
+	if g.State == StateGameOver && g.input.KeyPressed(ebiten.KeyV) {
 		g.returnToHub()
 	}
 }
@@ -281,30 +291,36 @@ func (g *Game) handleInputPlaying() {
 	g.handlePause()
 	if g.isPaused {
 		// Pause menu navigation handled separately
-		g.PauseMenu.Update()
+		if g.PauseMenu != nil {
+			g.PauseMenu.Update()
+		}
 		return
 	}
 	// Dialogue panel blocks all other input when active
 	if g.DialoguePanel != nil && g.DialoguePanel.Active {
-		g.DialoguePanel.Update()
+		g.DialoguePanel.Update(g.panelInput())
 		return
 	}
 
 	if g.InventoryScreen != nil && g.InventoryScreen.Active {
-		g.InventoryScreen.Update(g.player, g.ShowHint, func(it *items.Item) {
+		g.InventoryScreen.Update(g.panelInput(), g.player, g.ShowHint, func(it *items.Item) {
 			g.spawnItemDrop(it, g.player.TileX, g.player.TileY)
-		})
+		}, g.useConsumable)
 		return
 	}
 	if g.isActionJustPressed(controls.ActionInventory) {
-		g.InventoryScreen.Open()
+		g.InventoryScreen.Open(g.h)
 		return
 	}
 
 	// NPC interaction (E key) — check before other E-key handlers
 	if g.isActionJustPressed(controls.ActionInteract) {
 		if npc := g.findNearbyNPC(); npc != nil {
-			g.openDialogue(npc)
+			if npc.Merchant {
+				g.openShop()
+			} else {
+				g.openDialogue(npc)
+			}
 			return
 		}
 		if chest := g.findNearbyChest(); chest != nil {
@@ -371,7 +387,7 @@ func (g *Game) handleDoorInteract() {
 			g.ShowHintAt("Closed door. Click to open", hx, hy)
 		}
 	}
-	if tile.DoorState == 3 && inpututil.IsKeyJustPressed(ebiten.KeyQ) {
+	if tile.DoorState == 3 && g.input.KeyJustPressed(ebiten.KeyQ) {
 		if g.unlockDoor(g.hoverTileX, g.hoverTileY) {
 			g.ShowHintAt("Door unlocked", hx, hy)
 		}
@@ -457,6 +473,7 @@ func (g *Game) handleDash() {
 		if g.player.Caster.Ready(blinkInfo) {
 			g.player.Caster.PutOnCooldown(blinkInfo)
 			g.handleBlink(px, py, float64(g.hoverTileX), float64(g.hoverTileY))
+			g.playerActed(items.TriggerOnDash, g.player.Damage)
 		}
 		return
 	}
@@ -482,6 +499,10 @@ func (g *Game) handleDash() {
 			dirY = g.player.LastMoveDirY
 		}
 		g.player.StartDash(dirX, dirY)
+		if g.player.IsDashing {
+			g.emitPlayerNoise(dashNoiseRadius)
+			g.playerActed(items.TriggerOnDash, g.player.Damage)
+		}
 	}
 }
 
@@ -0,0 +1,52 @@
+package game
+
+import (
+	"dungeoneer/entities"
+	"dungeoneer/tiles"
+)
+
+// hazardAt returns the hazard type of the tile at (x, y), or tiles.HazardNone.
+func (g *Game) hazardAt(x, y int) uint8 {
+	if t := g.currentLevel.Tile(x, y); t != nil {
+		return t.Hazard
+	}
+	return tiles.HazardNone
+}
+
+// applyPlayerHazard applies the hazard under the player; entered is set on
+// the frame the player steps onto a new tile.
+func (g *Game) applyPlayerHazard(entered bool) {
+	if g.player.IsDead || g.player.IsDashing {
+		return
+	}
+	if dmg := entities.ApplyHazard(&g.player.Effects, g.hazardAt(g.player.TileX, g.player.TileY), entered); dmg > 0 {
+		g.player.TakeDamage(dmg)
+	}
+}
+
+// updateMonsters runs each monster's update and then applies the hazard under
+// it, so monsters chased across lava burn just like the player does. The
+// flow field toward the player is brought up to date first and shared by
+// every chasing monster.
+func (g *Game) updateMonsters() {
+	g.flow.Update(g.currentLevel, g.player.TileX, g.player.TileY)
+	for _, m := range g.Monsters {
+		m.Flow = &g.flow
+		px, py, wasAlive := m.TileX, m.TileY, !m.IsDead
+		m.Update(g.player, g.currentLevel)
+		if m.IsDead {
+			// Burn and poison ticks, from hazards among others, kill
+			// inside Update.
+			if wasAlive {
+				g.tickKilled(m)
+			}
+			continue
+		}
+		entered := m.TileX != px || m.TileY != py
+		if dmg := entities.ApplyHazard(&m.Effects, g.hazardAt(m.TileX, m.TileY), entered); dmg > 0 {
+			if m.TakeDamage(dmg, &g.HitMarkers, &g.DamageNumbers) {
+				g.handleMonsterDeath(m)
+			}
+		}
+	}
+}
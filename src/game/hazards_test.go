@@ -0,0 +1,26 @@
+package game
+
+import (
+	"dungeoneer/entities"
+	"testing"
+)
+
+func TestPoisonTickKillIsAKill(t *testing.T) {
+	s := newTestSim(t, 7)
+	g := s.Game
+	mx, my := openNeighbour(t, s)
+	m := newDummy(mx, my, 1)
+	m.Effects.AddEffect(&entities.StatusEffect{
+		Type: entities.EffectPoison, Duration: 5, TickRate: 0.01, Value: 5, Source: "hazard",
+	})
+	g.Monsters = []*entities.Monster{m}
+	kills := g.RunState.KillCount
+
+	g.updateMonsters()
+	if !m.IsDead {
+		t.Fatal("the poison tick did not kill the monster")
+	}
+	if g.RunState.KillCount != kills+1 {
+		t.Fatalf("kill count = %d, want %d", g.RunState.KillCount, kills+1)
+	}
+}
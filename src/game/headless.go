@@ -0,0 +1,100 @@
+package game
+
+import (
+	"dungeoneer/entities"
+	"dungeoneer/levels"
+	"dungeoneer/menumanager"
+)
+
+// Headless screen size used for hint placement and camera maths. Nothing is
+// drawn, but a few update paths project tiles to screen space.
+const (
+	headlessWidth  = 640
+	headlessHeight = 480
+)
+
+// Sim drives a Game without a window. It never calls Draw; each Step runs
+// exactly one Game.Update at the fixed 60 TPS timestep, reading input from a
+// ScriptedInput instead of the keyboard and mouse.
+//
+// A Sim is built from the simulation state alone: no main, pause or editor
+// menus and no dev tools are created, and none of them are updated. Sprites
+// are still loaded as Ebiten images, which is fine without ebiten.RunGame;
+// no window is ever opened. Ebiten's GLFW backend still connects to X when
+// the package loads on Linux, so until the game package stops importing
+// Ebiten, CI there runs the tests under a virtual framebuffer
+// (xvfb-run go test ./game).
+type Sim struct {
+	Game  *Game
+	Input *ScriptedInput
+	Ticks int
+}
+
+// NewSim creates a menu-less Game wired to scripted input with the editor
+// disabled.
+func NewSim() (*Sim, error) {
+	g, err := newGameState()
+	if err != nil {
+		return nil, err
+	}
+	// Start from an empty menu stack; there is no pause menu to open.
+	menumanager.Init(nil)
+	in := NewScriptedInput()
+	g.input = in
+	g.w, g.h = headlessWidth, headlessHeight
+	g.editor.Active = false
+	g.RecordReplays = false
+	return &Sim{Game: g, Input: in}, nil
+}
+
+// StartRun begins a dungeon run with the given seed and switches the game
+// into the playing state, skipping the main menu and hub.
+func (s *Sim) StartRun(seed int64) {
+	s.Game.State = StatePlaying
+	s.Game.StartRun(seed)
+}
+
+// Step advances the simulation by one tick, consuming one queued input frame.
+func (s *Sim) Step() error {
+	if err := s.Game.Update(); err != nil {
+		return err
+	}
+	s.Ticks++
+	return nil
+}
+
+// StepN advances the simulation by n ticks.
+func (s *Sim) StepN(n int) error {
+	for i := 0; i < n; i++ {
+		if err := s.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run queues the given frames and steps until all of them have been played.
+func (s *Sim) Run(frames ...InputFrame) error {
+	s.Input.Queue(frames...)
+	for s.Input.Pending() > 0 {
+		if err := s.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Player returns the active player.
+func (s *Sim) Player() *entities.Player {
+	return s.Game.player
+}
+
+// Level returns the active level layer.
+func (s *Sim) Level() *levels.Level {
+	return s.Game.currentLevel
+}
+
+// Teleport snaps the player onto the given tile.
+func (s *Sim) Teleport(x, y int) {
+	s.Game.placePlayerAt(x, y)
+}
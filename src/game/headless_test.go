@@ -0,0 +1,460 @@
+package game
+
+import (
+	"dungeoneer/controls"
+	"dungeoneer/entities"
+	"dungeoneer/pathing"
+	"dungeoneer/tiles"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TestMain runs the package from a scratch directory so meta.json and
+// run.json written during simulated runs never touch the working tree. The
+// data directories the game loads at startup are linked in.
+func TestMain(m *testing.M) {
+	src, err := filepath.Abs("..")
+	if err != nil {
+		panic(err)
+	}
+	dir, err := os.MkdirTemp("", "dungeoneer-sim")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	for _, data := range []string{biomeDir, encounterDir, prefabDir, "dialogues"} {
+		if err := os.Symlink(filepath.Join(src, data), data); err != nil {
+			panic(err)
+		}
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func newTestSim(t *testing.T, seed int64) *Sim {
+	t.Helper()
+	s, err := NewSim()
+	if err != nil {
+		t.Fatalf("NewSim: %v", err)
+	}
+	s.StartRun(seed)
+	return s
+}
+
+func leftClick(x, y int) InputFrame {
+	return InputFrame{Aim: AimAtTile(x, y), Clicks: []ebiten.MouseButton{ebiten.MouseButtonLeft}}
+}
+
+// openNeighbour returns a walkable, non-door tile next to the player.
+func openNeighbour(t *testing.T, s *Sim) (int, int) {
+	t.Helper()
+	p := s.Player()
+	lvl := s.Level()
+	for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {-1, -1}, {1, -1}, {-1, 1}} {
+		x, y := p.TileX+d[0], p.TileY+d[1]
+		if !lvl.IsWalkable(x, y) {
+			continue
+		}
+		if tile := lvl.Tile(x, y); tile != nil && tile.HasTag(tiles.TagDoor) {
+			continue
+		}
+		return x, y
+	}
+	t.Fatal("player spawn has no open neighbour")
+	return 0, 0
+}
+
+// useTempReplayDir has the test write its replays to a directory of its own.
+func useTempReplayDir(t *testing.T) {
+	t.Helper()
+	dir := replayDir
+	replayDir = t.TempDir()
+	t.Cleanup(func() { replayDir = dir })
+}
+
+func TestScriptedInputPlaysOneFramePerTick(t *testing.T) {
+	in := NewScriptedInput()
+	in.Queue(
+		InputFrame{Pressed: []controls.ActionID{controls.ActionDash}, Aim: &Aim{X: 4, Y: 5}},
+		InputFrame{Held: []controls.ActionID{controls.ActionMoveLeft}},
+	)
+
+	in.Advance()
+	if !in.ActionJustPressed(controls.ActionDash) || !in.ActionPressed(controls.ActionDash) {
+		t.Fatal("frame 1: dash should be just pressed")
+	}
+	if x, y, ok := in.CursorWorld(); !ok || x != 4 || y != 5 {
+		t.Fatalf("frame 1: cursor = (%v, %v, %v), want (4, 5, true)", x, y, ok)
+	}
+
+	in.Advance()
+	if in.ActionJustPressed(controls.ActionDash) {
+		t.Fatal("frame 2: dash should no longer fire")
+	}
+	if !in.ActionPressed(controls.ActionMoveLeft) || in.ActionJustPressed(controls.ActionMoveLeft) {
+		t.Fatal("frame 2: move left should be held, not just pressed")
+	}
+	if x, y, _ := in.CursorWorld(); x != 4 || y != 5 {
+		t.Fatal("frame 2: cursor should stay at the last aim")
+	}
+
+	in.Advance()
+	if in.ActionPressed(controls.ActionMoveLeft) || in.Pending() != 0 {
+		t.Fatal("empty queue should report no input")
+	}
+}
+
+func TestSameSeedBuildsSameFloor(t *testing.T) {
+	a := newTestSim(t, 1234)
+	b := newTestSim(t, 1234)
+	ga, gb := a.Game, b.Game
+
+	for i := range ga.RunState.Biomes {
+		if ga.RunState.Biomes[i] != gb.RunState.Biomes[i] {
+			t.Fatalf("biome %d: %s vs %s", i, ga.RunState.Biomes[i], gb.RunState.Biomes[i])
+		}
+	}
+	if a.Player().TileX != b.Player().TileX || a.Player().TileY != b.Player().TileY {
+		t.Fatal("spawn tiles differ")
+	}
+	if ga.ExitEntity.TileX != gb.ExitEntity.TileX || ga.ExitEntity.TileY != gb.ExitEntity.TileY {
+		t.Fatal("exit tiles differ")
+	}
+	if len(ga.Monsters) != len(gb.Monsters) {
+		t.Fatalf("monster count: %d vs %d", len(ga.Monsters), len(gb.Monsters))
+	}
+	for i := range ga.Monsters {
+		ma, mb := ga.Monsters[i], gb.Monsters[i]
+		if ma.Name != mb.Name || ma.TileX != mb.TileX || ma.TileY != mb.TileY || ma.HP != mb.HP {
+			t.Fatalf("monster %d: %s@(%d,%d) vs %s@(%d,%d)", i, ma.Name, ma.TileX, ma.TileY, mb.Name, mb.TileX, mb.TileY)
+		}
+	}
+	if len(ga.NPCs) != len(gb.NPCs) {
+		t.Fatalf("npc count: %d vs %d", len(ga.NPCs), len(gb.NPCs))
+	}
+	for i := range ga.NPCs {
+		if ga.NPCs[i].TileX != gb.NPCs[i].TileX || ga.NPCs[i].TileY != gb.NPCs[i].TileY {
+			t.Fatalf("npc %d placed differently", i)
+		}
+	}
+	if len(ga.Chests) != len(gb.Chests) {
+		t.Fatalf("chest count: %d vs %d", len(ga.Chests), len(gb.Chests))
+	}
+	for i := range ga.Chests {
+		if ga.Chests[i].Variant != gb.Chests[i].Variant {
+			t.Fatalf("chest %d: %s vs %s", i, ga.Chests[i].Variant, gb.Chests[i].Variant)
+		}
+	}
+}
+
+func TestRightClickWalksToTile(t *testing.T) {
+	s := newTestSim(t, 99)
+	s.Game.Monsters = nil
+	p := s.Player()
+
+	// Pick a reachable tile a few steps away.
+	tx, ty := -1, -1
+	lvl := s.Level()
+	for y := p.TileY - 8; y <= p.TileY+8 && tx < 0; y++ {
+		for x := p.TileX - 8; x <= p.TileX+8; x++ {
+			if !lvl.IsWalkable(x, y) {
+				continue
+			}
+			path := pathing.AStar(lvl, p.TileX, p.TileY, x, y)
+			if len(path) >= 4 && len(path) <= 8 {
+				tx, ty = x, y
+				break
+			}
+		}
+	}
+	if tx < 0 {
+		t.Fatal("no reachable target near spawn")
+	}
+
+	if err := s.Run(InputFrame{Aim: AimAtTile(tx, ty), Clicks: []ebiten.MouseButton{ebiten.MouseButtonRight}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StepN(300); err != nil {
+		t.Fatal(err)
+	}
+	if p.TileX != tx || p.TileY != ty {
+		t.Fatalf("player at (%d,%d), want (%d,%d)", p.TileX, p.TileY, tx, ty)
+	}
+}
+
+func TestBasicMeleeKillsAdjacentMonster(t *testing.T) {
+	s := newTestSim(t, 7)
+	g := s.Game
+	p := s.Player()
+	p.Abilities = map[string]bool{}
+	p.AttackTick = p.AttackRate
+
+	mx, my := openNeighbour(t, s)
+	dummy := &entities.Monster{
+		Name: "Dummy", TileX: mx, TileY: my,
+		InterpX: float64(mx), InterpY: float64(my),
+		HP: 1, MaxHP: 1, Level: 1, MovementDuration: 30,
+	}
+	g.Monsters = []*entities.Monster{dummy}
+	kills := g.RunState.KillCount
+
+	if err := s.Run(leftClick(mx, my)); err != nil {
+		t.Fatal(err)
+	}
+	if !dummy.IsDead {
+		t.Fatal("dummy survived a basic attack")
+	}
+	if g.RunState.KillCount != kills+1 {
+		t.Fatalf("kill count = %d, want %d", g.RunState.KillCount, kills+1)
+	}
+	if len(g.DamageNumbers) == 0 {
+		t.Fatal("no damage number for the hit")
+	}
+}
+
+func TestArcaneBoltSpendsManaAndResolves(t *testing.T) {
+	s := newTestSim(t, 11)
+	g := s.Game
+	g.Monsters = nil
+	p := s.Player()
+	p.Abilities = map[string]bool{"arcane_bolt": true}
+	p.Mana = p.MaxMana
+
+	mx, my := openNeighbour(t, s)
+	if err := s.Run(leftClick(mx, my)); err != nil {
+		t.Fatal(err)
+	}
+	if p.Mana >= p.MaxMana {
+		t.Fatal("casting arcane bolt did not spend mana")
+	}
+	if len(g.ActiveSpells) == 0 {
+		t.Fatal("no bolt in flight after casting")
+	}
+	if err := s.StepN(240); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.ActiveSpells) != 0 {
+		t.Fatalf("%d spells still active after 4s", len(g.ActiveSpells))
+	}
+}
+
+func TestExitAdvancesFloor(t *testing.T) {
+	s := newTestSim(t, 5)
+	g := s.Game
+	g.Monsters = nil
+	s.Teleport(g.ExitEntity.TileX, g.ExitEntity.TileY)
+
+	if err := s.Run(InputFrame{Pressed: []controls.ActionID{controls.ActionInteract}}); err != nil {
+		t.Fatal(err)
+	}
+	if g.RunState.CurrentFloor != 2 || g.RunState.FloorsCleared != 1 {
+		t.Fatalf("floor = %d cleared = %d, want 2 and 1", g.RunState.CurrentFloor, g.RunState.FloorsCleared)
+	}
+	if g.FloorCtx == nil || g.FloorCtx.FloorNumber != 2 {
+		t.Fatal("floor context not rebuilt for floor 2")
+	}
+}
+
+func TestPlayerDeathEndsRun(t *testing.T) {
+	s := newTestSim(t, 3)
+	g := s.Game
+	s.Player().TakeDamage(s.Player().HP)
+
+	if err := s.Step(); err != nil {
+		t.Fatal(err)
+	}
+	if g.State != StateDeathScreen {
+		t.Fatalf("state = %v, want death screen", g.State)
+	}
+	if g.RunState.Active {
+		t.Fatal("run still active after death")
+	}
+	if HasRunSave() {
+		t.Fatal("run save not cleared on death")
+	}
+}
+
+func TestReplayReproducesRun(t *testing.T) {
+	useTempReplayDir(t)
+	a, err := NewSim()
+	if err != nil {
+		t.Fatalf("NewSim: %v", err)
+	}
+	a.Game.RecordReplays = true
+	a.StartRun(42)
+	mx, my := openNeighbour(t, a)
+
+	frames := []InputFrame{
+		{Aim: AimAtTile(mx, my), Clicks: []ebiten.MouseButton{ebiten.MouseButtonRight}},
+		{Held: []controls.ActionID{controls.ActionMoveLeft}},
+		{Held: []controls.ActionID{controls.ActionMoveLeft}},
+		leftClick(mx, my),
+	}
+	if err := a.Run(frames...); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.StepN(180); err != nil {
+		t.Fatal(err)
+	}
+	a.Game.finishRecording()
+
+	paths, _ := filepath.Glob(filepath.Join(replayDir, "*.json"))
+	if len(paths) != 1 {
+		t.Fatalf("found %d replay files, want 1", len(paths))
+	}
+	b, err := NewSim()
+	if err != nil {
+		t.Fatalf("NewSim: %v", err)
+	}
+	if err := b.Game.StartReplay(paths[0]); err != nil {
+		t.Fatalf("StartReplay: %v", err)
+	}
+	if err := b.StepN(a.Ticks); err != nil {
+		t.Fatal(err)
+	}
+
+	pa, pb := a.Player(), b.Player()
+	if pa.TileX != pb.TileX || pa.TileY != pb.TileY || pa.HP != pb.HP {
+		t.Fatalf("player (%d,%d) hp %d vs replay (%d,%d) hp %d", pa.TileX, pa.TileY, pa.HP, pb.TileX, pb.TileY, pb.HP)
+	}
+	if len(a.Game.Monsters) != len(b.Game.Monsters) {
+		t.Fatalf("monster count: %d vs %d", len(a.Game.Monsters), len(b.Game.Monsters))
+	}
+	for i := range a.Game.Monsters {
+		ma, mb := a.Game.Monsters[i], b.Game.Monsters[i]
+		if ma.TileX != mb.TileX || ma.TileY != mb.TileY || ma.HP != mb.HP {
+			t.Fatalf("monster %d: (%d,%d) hp %d vs replay (%d,%d) hp %d", i, ma.TileX, ma.TileY, ma.HP, mb.TileX, mb.TileY, mb.HP)
+		}
+	}
+	if b.Game.recorder != nil {
+		t.Fatal("replay started a recording of its own")
+	}
+}
+
+func TestReplayReproducesInventoryDrag(t *testing.T) {
+	useTempReplayDir(t)
+	a, err := NewSim()
+	if err != nil {
+		t.Fatalf("NewSim: %v", err)
+	}
+	a.Game.RecordReplays = true
+	a.StartRun(42)
+
+	inv := a.Player().Inventory
+	fx, fy, ex, ey := -1, -1, -1, -1
+	for y := 0; y < inv.Height; y++ {
+		for x := 0; x < inv.Width; x++ {
+			if inv.Grid[y][x] != nil && fx < 0 {
+				fx, fy = x, y
+			} else if inv.Grid[y][x] == nil && ex < 0 {
+				ex, ey = x, y
+			}
+		}
+	}
+	if fx < 0 || ex < 0 {
+		t.Fatal("starting inventory needs an item and a free cell")
+	}
+	moved := inv.Grid[fy][fx]
+
+	// Cells are laid out from the screen's grid origin, pushed down by the
+	// screen's offset for the headless height.
+	scr := a.Game.InventoryScreen
+	cell := func(x, y int) *Cursor {
+		return &Cursor{
+			X: scr.GridOrigin.X + x*scr.CellSize.X + scr.CellSize.X/2,
+			Y: scr.GridOrigin.Y + headlessHeight*30/100 + y*scr.CellSize.Y + scr.CellSize.Y/2,
+		}
+	}
+	left := []ebiten.MouseButton{ebiten.MouseButtonLeft}
+	if err := a.Run(
+		InputFrame{Pressed: []controls.ActionID{controls.ActionInventory}},
+		InputFrame{Cursor: cell(fx, fy), Clicks: left},
+		InputFrame{Cursor: cell(ex, ey), Buttons: left},
+		InputFrame{},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if inv.Grid[ey][ex] != moved || inv.Grid[fy][fx] != nil {
+		t.Fatal("drag did not move the item")
+	}
+	a.Game.finishRecording()
+
+	paths, _ := filepath.Glob(filepath.Join(replayDir, "*.json"))
+	if len(paths) != 1 {
+		t.Fatalf("found %d replay files, want 1", len(paths))
+	}
+	b, err := NewSim()
+	if err != nil {
+		t.Fatalf("NewSim: %v", err)
+	}
+	if err := b.Game.StartReplay(paths[0]); err != nil {
+		t.Fatalf("StartReplay: %v", err)
+	}
+	if err := b.StepN(a.Ticks); err != nil {
+		t.Fatal(err)
+	}
+	got := b.Player().Inventory.Grid
+	if got[ey][ex] == nil || got[ey][ex].ID != moved.ID || got[fy][fx] != nil {
+		t.Fatal("replay did not repeat the inventory drag")
+	}
+}
+
+func TestReplayReproducesTrades(t *testing.T) {
+	useTempReplayDir(t)
+	a, err := NewSim()
+	if err != nil {
+		t.Fatalf("NewSim: %v", err)
+	}
+	a.Game.RecordReplays = true
+	a.StartRun(42)
+
+	inv := a.Player().Inventory
+	fx, fy := -1, -1
+	for y := 0; y < inv.Height && fx < 0; y++ {
+		for x := 0; x < inv.Width; x++ {
+			if it := inv.Grid[y][x]; it != nil && !it.QuestLocked {
+				fx, fy = x, y
+				break
+			}
+		}
+	}
+	if fx < 0 {
+		t.Fatal("starting inventory has nothing to sell")
+	}
+	if err := a.StepN(10); err != nil {
+		t.Fatal(err)
+	}
+	// Trades are made from the shop menu, which pauses the game.
+	if !a.Game.sellItem(fx, fy) {
+		t.Fatal("could not sell the item")
+	}
+	if err := a.StepN(10); err != nil {
+		t.Fatal(err)
+	}
+	a.Game.finishRecording()
+
+	paths, _ := filepath.Glob(filepath.Join(replayDir, "*.json"))
+	if len(paths) != 1 {
+		t.Fatalf("found %d replay files, want 1", len(paths))
+	}
+	b, err := NewSim()
+	if err != nil {
+		t.Fatalf("NewSim: %v", err)
+	}
+	if err := b.Game.StartReplay(paths[0]); err != nil {
+		t.Fatalf("StartReplay: %v", err)
+	}
+	if err := b.StepN(a.Ticks); err != nil {
+		t.Fatal(err)
+	}
+	pa, pb := a.Player(), b.Player()
+	if pa.Gold != pb.Gold || (pb.Inventory.Grid[fy][fx] == nil) != (inv.Grid[fy][fx] == nil) {
+		t.Fatalf("gold %d vs replay %d; the replay did not repeat the sale", pa.Gold, pb.Gold)
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"dungeoneer/entities"
 	"dungeoneer/fov"
 	"dungeoneer/inventory"
+	"dungeoneer/items"
 	"dungeoneer/leveleditor"
 	"dungeoneer/levels"
+	"dungeoneer/menumanager"
+	"dungeoneer/simrand"
 	"dungeoneer/spells"
+	"dungeoneer/ui"
 	"fmt"
-	"math/rand/v2"
+	"image"
 	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -80,6 +84,8 @@ func (g *Game) loadHub() {
 	g.BossRoom = nil
 	g.NPCs = []*entities.NPC{}
 	g.Chests = []*entities.Chest{}
+	g.Traps = []*entities.Trap{}
+	g.shopStock = nil // the hub merchant restocks after every run
 	g.IsInHub = true
 	g.hubPortalX = portalX
 	g.hubPortalY = portalY
@@ -91,6 +97,7 @@ func (g *Game) loadHub() {
 	g.RaycastWalls = fov.LevelToWalls(g.currentLevel)
 	fov.InvalidateCache()
 	g.spawnHubNPCs()
+	g.spawnHubMerchant()
 	g.State = StatePlaying
 }
 
@@ -183,8 +190,9 @@ func (g *Game) resetPlayerForHub() {
 	}
 	g.player.TempModifiers = entities.StatModifiers{}
 
-	// Clear inventory and equipment.
+	// Clear inventory, belt and equipment.
 	g.player.Inventory = inventory.New(inventory.Width, inventory.Height)
+	g.player.Belt = [constants.BeltSlots]string{}
 	for slot := range g.player.Equipment {
 		g.player.Equipment[slot] = nil
 	}
@@ -200,17 +208,52 @@ func (g *Game) resetPlayerForHub() {
 	g.player.Mana = g.player.MaxMana
 }
 
-// StartRun begins a new dungeon run from the hub.
-func (g *Game) StartRun() {
+// StartRun begins a new dungeon run from the hub. The seed drives every
+// random roll in the run; pass NewRunSeed() for a fresh run.
+func (g *Game) StartRun(seed int64) {
 	g.Meta.RunCount++
-	SaveMeta(g.Meta)
-	g.RunState = NewRunState(DefaultRunFloors)
+	g.saveMeta()
+	g.RunState = NewRunState(DefaultRunFloors, seed)
 	g.seedNPCPhaseFlags()
+	g.applyRunUnlocks()
 	g.IsInHub = false
 	g.FullBright = false
+	sim := g.RunState.Stream(0, streamSim)
+	simrand.Seed(sim.Uint64(), sim.Uint64())
+	g.startRecording(nil)
 	g.startFloor(1)
 }
 
+// openSeedPrompt asks for a run seed before entering the dungeon. Leaving the
+// prompt blank starts a random run; the previous run's seed is shown so it
+// can be shared or replayed.
+func (g *Game) openSeedPrompt() {
+	rect := image.Rect(g.w/2-200, g.h/2-100, g.w/2+200, g.h/2+100)
+	g.SeedPrompt = ui.NewTextInputMenu(
+		rect,
+		"Enter the Dungeon",
+		"Run seed (blank for random):",
+		func(text string) {
+			menumanager.Manager().CloseActiveMenu()
+			g.StartRun(ParseSeed(text))
+		},
+		func() {
+			menumanager.Manager().CloseActiveMenu()
+		},
+	)
+	g.SeedPrompt.Validate = func(text string) string {
+		if len(text) > 32 {
+			return "Seed too long (max 32 characters)"
+		}
+		return ""
+	}
+	if g.RunState != nil {
+		g.SeedPrompt.Instructions = append(g.SeedPrompt.Instructions,
+			"Last run seed: "+FormatSeed(g.RunState.Seed))
+	}
+	menumanager.Manager().Open(g.SeedPrompt)
+}
+
 // seedNPCPhaseFlags initialises per-run QuestFlags from MetaSave at run start.
 //
 // Phase intentionally resets to 0 every run — the full questline must be
@@ -240,10 +283,15 @@ func (g *Game) startFloor(floorNum int) {
 	g.RunState.CurrentFloor = floorNum
 	g.FloorCtx = &ctx
 	g.MonsterProjectiles = nil
+	// Keys only open doors on the floor they were found on.
+	if g.player != nil && g.player.Inventory != nil {
+		g.player.Inventory.RemoveType(items.ItemKey)
+	}
 
-	// Generate the level
-	lvl := levels.Generate64x64(ctx.GenParams)
-	newWorld := levels.NewLayeredLevel(lvl)
+	// Generate the floor; sublayers hang off the main layer by stairwells.
+	newWorld := ctx.GenerateFloor()
+	paintStairwells(newWorld)
+	lvl := newWorld.Layers[0]
 	g.currentWorld = newWorld
 	g.currentLevel = lvl
 	g.editor = leveleditor.NewLayeredEditor(newWorld, g.w, g.h)
@@ -254,14 +302,7 @@ func (g *Game) startFloor(floorNum int) {
 
 	// Find spawn and exit using two-pass BFS (guarantees max separation)
 	spawnX, spawnY, exitX, exitY := levels.FindSpawnAndExit(lvl)
-	g.player.TileX = spawnX
-	g.player.TileY = spawnY
-	g.player.MoveController.InterpX = float64(spawnX)
-	g.player.MoveController.InterpY = float64(spawnY)
-	g.player.MoveController.Path = nil
-	g.player.MoveController.Stop()
-	g.player.CollisionBox.X = float64(spawnX)
-	g.player.CollisionBox.Y = float64(spawnY)
+	g.placePlayerAt(spawnX, spawnY)
 	// Fallback: if BFS couldn't separate spawn and exit (degenerate level),
 	// find any walkable tile that isn't the spawn point.
 	if exitX == spawnX && exitY == spawnY || !lvl.IsWalkable(exitX, exitY) {
@@ -274,7 +315,12 @@ func (g *Game) startFloor(floorNum int) {
 			}
 		}
 	}
-	g.ExitEntity = entities.NewExitEntity(exitX, exitY, g.spriteSheet.Portal, "Portal")
+	// On multi-layer floors the exit tile holds the stairs down (or up) and
+	// the portal waits on the last sublayer.
+	g.ExitEntity = nil
+	if len(newWorld.Layers) == 1 {
+		g.ExitEntity = entities.NewExitEntity(exitX, exitY, g.spriteSheet.Portal, "Portal")
+	}
 
 	// Spawn entities from level data
 	g.spawnEntitiesFromLevel()
@@ -303,6 +349,7 @@ func (g *Game) startFloor(floorNum int) {
 	// Spawn chests in treasure rooms
 	g.Chests = []*entities.Chest{}
 	g.spawnFloorChests(ctx)
+	g.populateSublayers(ctx)
 
 	// Reset camera and FOV
 	snapIsoX, snapIsoY := g.cartesianToIso(float64(spawnX), float64(spawnY))
@@ -311,6 +358,23 @@ func (g *Game) startFloor(floorNum int) {
 	g.cachedRays = nil
 	g.RaycastWalls = fov.LevelToWalls(g.currentLevel)
 	fov.InvalidateCache()
+
+	// Autosave at the top of every floor so the run can be continued.
+	if err := g.SaveRun(); err != nil {
+		fmt.Println("Error saving run:", err)
+	}
+}
+
+// placePlayerAt snaps the player onto a tile, cancelling any movement.
+func (g *Game) placePlayerAt(x, y int) {
+	g.player.TileX = x
+	g.player.TileY = y
+	g.player.MoveController.InterpX = float64(x)
+	g.player.MoveController.InterpY = float64(y)
+	g.player.MoveController.Path = nil
+	g.player.MoveController.Stop()
+	g.player.CollisionBox.X = float64(x)
+	g.player.CollisionBox.Y = float64(y)
 }
 
 // advanceFloor moves to the next floor or triggers victory.
@@ -333,7 +397,10 @@ func (g *Game) endRunDeath() {
 	if g.RunState.FloorsCleared > g.Meta.BestFloor {
 		g.Meta.BestFloor = g.RunState.FloorsCleared
 	}
-	SaveMeta(g.Meta)
+	g.saveMeta()
+	if !g.replaying {
+		DeleteRunSave()
+	}
 	g.State = StateDeathScreen
 }
 
@@ -348,7 +415,10 @@ func (g *Game) endRunVictory() {
 	if g.RunState.TotalFloors > g.Meta.BestFloor {
 		g.Meta.BestFloor = g.RunState.TotalFloors
 	}
-	SaveMeta(g.Meta)
+	g.saveMeta()
+	if !g.replaying {
+		DeleteRunSave()
+	}
 	g.State = StateVictoryScreen
 }
 
@@ -369,14 +439,15 @@ func (g *Game) spawnFloorMonsters(ctx FloorContext) {
 
 	// Base monster count scales with floor number
 	baseCount := 3 + ctx.FloorNumber*2
-	count := baseCount + rand.IntN(3)
+	rng := ctx.RNG.Encounters
+	count := baseCount + rng.IntN(3)
 
 	for i := 0; i < count; i++ {
 		// Find a random walkable tile
 		attempts := 0
 		for attempts < 50 {
-			x := rand.IntN(g.currentLevel.W)
-			y := rand.IntN(g.currentLevel.H)
+			x := rng.IntN(g.currentLevel.W)
+			y := rng.IntN(g.currentLevel.H)
 			if !g.currentLevel.IsWalkable(x, y) {
 				attempts++
 				continue
@@ -396,7 +467,7 @@ func (g *Game) spawnFloorMonsters(ctx FloorContext) {
 				continue
 			}
 
-			t := templates[rand.IntN(len(templates))]
+			t := templates[rng.IntN(len(templates))]
 			hpScale := 1.0 + ctx.Difficulty*0.5
 			dmgScale := 1.0 + ctx.Difficulty*0.3
 			baseHP := 8
@@ -416,6 +487,7 @@ func (g *Game) spawnFloorMonsters(ctx FloorContext) {
 				HitRadius:        entities.DefaultMonsterHitRadius,
 				AttackRate:       45,
 				Behavior:         entities.NewRoamingWanderBehavior(5),
+				Doors:            doorPolicy("", "roaming"),
 				Level:            ctx.FloorNumber,
 			}
 			g.Monsters = append(g.Monsters, m)
@@ -0,0 +1,260 @@
+package game
+
+import (
+	"dungeoneer/controls"
+	"dungeoneer/ui"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// InputSource supplies the per-tick input the simulation reads. The default
+// source polls Ebiten's devices; headless runs substitute a ScriptedInput.
+//
+// Gameplay and the in-run panels (dialogue, inventory, hero panel) read the
+// source. Menus shown while paused and the editor palettes still read
+// devices directly.
+type InputSource interface {
+	ActionPressed(action controls.ActionID) bool
+	ActionJustPressed(action controls.ActionID) bool
+	KeyPressed(key ebiten.Key) bool
+	KeyJustPressed(key ebiten.Key) bool
+	MousePressed(button ebiten.MouseButton) bool
+	MouseJustPressed(button ebiten.MouseButton) bool
+	CursorPosition() (int, int)
+	Wheel() (float64, float64)
+}
+
+// panelCursor is implemented by sources that keep the screen cursor and held
+// buttons the in-run panels read apart from gameplay's, so that they can be
+// recorded and scripted.
+type panelCursor interface {
+	PanelCursor() (int, int)
+	PanelMousePressed(button ebiten.MouseButton) bool
+}
+
+// panelSource feeds the in-run panels from the game's input source.
+type panelSource struct {
+	src InputSource
+}
+
+// panelInput returns the input the in-run panels read this tick.
+func (g *Game) panelInput() ui.Input {
+	return panelSource{src: g.input}
+}
+
+func (p panelSource) KeyJustPressed(key ebiten.Key) bool { return p.src.KeyJustPressed(key) }
+
+func (p panelSource) MouseJustPressed(button ebiten.MouseButton) bool {
+	return p.src.MouseJustPressed(button)
+}
+
+func (p panelSource) MousePressed(button ebiten.MouseButton) bool {
+	if pc, ok := p.src.(panelCursor); ok {
+		return pc.PanelMousePressed(button)
+	}
+	return p.src.MousePressed(button)
+}
+
+func (p panelSource) CursorPosition() (int, int) {
+	if pc, ok := p.src.(panelCursor); ok {
+		return pc.PanelCursor()
+	}
+	return p.src.CursorPosition()
+}
+
+// worldCursor is implemented by sources that aim directly in tile space
+// instead of going through screen coordinates and the camera.
+type worldCursor interface {
+	CursorWorld() (x, y float64, ok bool)
+}
+
+// ebitenInput reads the real keyboard and mouse through the player's bindings.
+type ebitenInput struct {
+	ctrl *controls.Controls
+}
+
+func (in ebitenInput) ActionPressed(action controls.ActionID) bool {
+	return ebiten.IsKeyPressed(in.ctrl.GetBinding(action).Primary)
+}
+
+func (in ebitenInput) ActionJustPressed(action controls.ActionID) bool {
+	return inpututil.IsKeyJustPressed(in.ctrl.GetBinding(action).Primary)
+}
+
+func (ebitenInput) KeyPressed(key ebiten.Key) bool     { return ebiten.IsKeyPressed(key) }
+func (ebitenInput) KeyJustPressed(key ebiten.Key) bool { return inpututil.IsKeyJustPressed(key) }
+
+func (ebitenInput) MousePressed(button ebiten.MouseButton) bool {
+	return ebiten.IsMouseButtonPressed(button)
+}
+
+func (ebitenInput) MouseJustPressed(button ebiten.MouseButton) bool {
+	return inpututil.IsMouseButtonJustPressed(button)
+}
+
+func (ebitenInput) CursorPosition() (int, int) { return ebiten.CursorPosition() }
+func (ebitenInput) Wheel() (float64, float64)  { return ebiten.Wheel() }
+
+// InputFrame is one tick of scripted input. Held actions stay down for the
+// frame; Pressed actions, Keys and Clicks fire as "just pressed". Aim, when
+// set, places the cursor at a cartesian tile-space position. Cursor and
+// Buttons are the screen cursor and held mouse buttons the in-run panels
+// read; the cursor stays put until the next frame that sets it.
+type InputFrame struct {
+	Held    []controls.ActionID  `json:"held,omitempty"`
+	Pressed []controls.ActionID  `json:"pressed,omitempty"`
+	Keys    []ebiten.Key         `json:"keys,omitempty"`
+	Clicks  []ebiten.MouseButton `json:"clicks,omitempty"`
+	Aim     *Aim                 `json:"aim,omitempty"`
+	Cursor  *Cursor              `json:"cursor,omitempty"`
+	Buttons []ebiten.MouseButton `json:"buttons,omitempty"`
+}
+
+// Aim is a cursor position in cartesian tile space, as produced by
+// isoToCartesian. Use AimAtTile to target the centre of a tile.
+type Aim struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Cursor is a screen position as the in-run panels see it.
+type Cursor struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// AimAtTile returns the cursor position that resolves to the given hover tile.
+func AimAtTile(tx, ty int) *Aim {
+	return &Aim{X: float64(tx) + 2.0, Y: float64(ty) + 1.0}
+}
+
+// ScriptedInput plays back queued InputFrames, one per tick. When the queue
+// runs dry every query reports no input, and the cursor stays where the last
+// Aim left it.
+type ScriptedInput struct {
+	queue     []InputFrame
+	cur       InputFrame
+	aim       Aim
+	aimed     bool
+	cursor    Cursor
+	cursorSet bool
+}
+
+// NewScriptedInput returns an empty scripted source.
+func NewScriptedInput() *ScriptedInput {
+	return &ScriptedInput{}
+}
+
+// Queue appends frames to be played on subsequent ticks.
+func (s *ScriptedInput) Queue(frames ...InputFrame) {
+	s.queue = append(s.queue, frames...)
+}
+
+// Pending reports how many queued frames have not been played yet.
+func (s *ScriptedInput) Pending() int {
+	return len(s.queue)
+}
+
+// Advance loads the next queued frame. It is called once per tick before
+// the game reads any input.
+func (s *ScriptedInput) Advance() {
+	s.cur = InputFrame{}
+	if len(s.queue) == 0 {
+		return
+	}
+	s.cur = s.queue[0]
+	s.queue = s.queue[1:]
+	if s.cur.Aim != nil {
+		s.aim = *s.cur.Aim
+		s.aimed = true
+	}
+	if s.cur.Cursor != nil {
+		s.cursor = *s.cur.Cursor
+		s.cursorSet = true
+	}
+}
+
+func (s *ScriptedInput) ActionPressed(action controls.ActionID) bool {
+	return containsAction(s.cur.Held, action) || containsAction(s.cur.Pressed, action)
+}
+
+func (s *ScriptedInput) ActionJustPressed(action controls.ActionID) bool {
+	return containsAction(s.cur.Pressed, action)
+}
+
+func (s *ScriptedInput) KeyPressed(key ebiten.Key) bool {
+	return s.KeyJustPressed(key)
+}
+
+func (s *ScriptedInput) KeyJustPressed(key ebiten.Key) bool {
+	for _, k := range s.cur.Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ScriptedInput) MousePressed(button ebiten.MouseButton) bool {
+	return s.MouseJustPressed(button)
+}
+
+func (s *ScriptedInput) MouseJustPressed(button ebiten.MouseButton) bool {
+	for _, b := range s.cur.Clicks {
+		if b == button {
+			return true
+		}
+	}
+	return false
+}
+
+// CursorPosition is unused while aiming in world space; scripted frames
+// never place the cursor on screen-space UI.
+func (s *ScriptedInput) CursorPosition() (int, int) { return math.MinInt32, math.MinInt32 }
+func (s *ScriptedInput) Wheel() (float64, float64)  { return 0, 0 }
+
+func (s *ScriptedInput) CursorWorld() (float64, float64, bool) {
+	return s.aim.X, s.aim.Y, s.aimed
+}
+
+func (s *ScriptedInput) PanelCursor() (int, int) {
+	if !s.cursorSet {
+		return math.MinInt32, math.MinInt32
+	}
+	return s.cursor.X, s.cursor.Y
+}
+
+func (s *ScriptedInput) PanelMousePressed(button ebiten.MouseButton) bool {
+	return slices.Contains(s.cur.Buttons, button) || s.MouseJustPressed(button)
+}
+
+func containsAction(list []controls.ActionID, action controls.ActionID) bool {
+	for _, a := range list {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// cursorWorld returns the cursor position in cartesian tile space, going
+// through the camera unless the input source aims in world space directly.
+func (g *Game) cursorWorld() (float64, float64) {
+	return g.sourceCursorWorld(g.input)
+}
+
+// sourceCursorWorld resolves the cursor of a specific input source.
+func (g *Game) sourceCursorWorld(in InputSource) (float64, float64) {
+	if wc, ok := in.(worldCursor); ok {
+		if x, y, ok := wc.CursorWorld(); ok {
+			return x, y
+		}
+	}
+	mx, my := in.CursorPosition()
+	wx := (float64(mx)-float64(g.w/2))/g.camScale + g.camX
+	wy := (float64(my)-float64(g.h/2))/g.camScale - g.camY
+	return g.isoToCartesian(wx, wy)
+}
@@ -0,0 +1,203 @@
+package game
+
+import (
+	"dungeoneer/entities"
+	"dungeoneer/leveleditor"
+	"dungeoneer/levels"
+	"math/rand/v2"
+)
+
+// Sublayer sizing. Sublayers are smaller than the main layer so a side trip
+// stays a side trip.
+const (
+	sublayerSize    = 40
+	minSublayerSize = 24
+)
+
+// Stair sprites painted on generated stairwells.
+const (
+	stairsDownSprite = "StairsDecending"
+	stairsUpSprite   = "StairsAscending"
+)
+
+// Sublayer is an extra layer rolled for a floor from its biome's SublayerDefs.
+type Sublayer struct {
+	Name      string           `json:"name"`
+	Below     bool             `json:"below"`
+	Layout    string           `json:"layout"`
+	GenParams levels.GenParams `json:"gen_params"`
+}
+
+// rollSublayers rolls each of the biome's sublayers for the floor. A sublayer
+// reuses the floor's parameters scaled down to its size, without vaults and
+// with half the traps and hazard pools.
+func rollSublayers(ctx *FloorContext, rng *rand.Rand) []Sublayer {
+	if ctx.BiomeConfig == nil {
+		return nil
+	}
+	var out []Sublayer
+	for _, def := range ctx.BiomeConfig.Sublayers {
+		if rng.Float64() >= def.Chance {
+			continue
+		}
+		p := ctx.GenParams
+		p.Seed = rng.Int64()
+		p.Width, p.Height = sublayerSize, sublayerSize
+		if def.Size != 0 {
+			p.Width, p.Height = def.Size, def.Size
+		}
+		p.RoomCountMin = max(2, p.RoomCountMin/2)
+		p.RoomCountMax = max(p.RoomCountMin+1, p.RoomCountMax/2)
+		p.FillerRoomsMax /= 2
+		p.Prefabs = nil
+		p.Traps = max(1, p.Traps/2)
+		p.Hazards = nil
+		for _, h := range ctx.GenParams.Hazards {
+			h.Pools = max(1, h.Pools/2)
+			p.Hazards = append(p.Hazards, h)
+		}
+		if def.Flavor != "" {
+			p.WallFlavor, p.FloorFlavor = def.Flavor, def.Flavor
+		}
+		layout := def.Layout
+		if layout == "" {
+			layout = LayoutRooms
+		}
+		out = append(out, Sublayer{Name: def.Name, Below: def.Below, Layout: layout, GenParams: p})
+	}
+	return out
+}
+
+// GenerateFloor builds the main layer and every sublayer. Each sublayer hangs
+// off the main layer by a stairwell: the first takes the spot the exit would
+// have had, the rest are spread out from it. The sublayer's end of the stairs
+// is its spawn point, so its own far end is where the exit goes. Sublayers
+// with no room left for their stairs are dropped from ctx.
+func (ctx *FloorContext) GenerateFloor() *levels.LayeredLevel {
+	main := ctx.GenerateLevel()
+	world := levels.NewLayeredLevel(main)
+	sx, sy, ex, ey := levels.FindSpawnAndExit(main)
+	locks := len(main.Locks)
+	var stairs []levels.Point
+	var placed []Sublayer
+	for _, sub := range ctx.Sublayers {
+		stair := levels.Point{X: ex, Y: ey}
+		if len(stairs) > 0 {
+			var ok bool
+			if stair, ok = levels.FindStairTile(main, sx, sy, stairs, 12); !ok {
+				continue
+			}
+		}
+		l := (&FloorContext{Layout: sub.Layout, GenParams: sub.GenParams}).GenerateLevel()
+		l.OffsetLocks(locks)
+		locks += len(l.Locks)
+		stairs = append(stairs, stair)
+		placed = append(placed, sub)
+		world.AddLayer(l)
+
+		lsx, lsy, _, _ := levels.FindSpawnAndExit(l)
+		down, up := stairsDownSprite, stairsUpSprite
+		if !sub.Below {
+			down, up = up, down
+		}
+		world.LinkLayers(0, stair, len(world.Layers)-1, levels.Point{X: lsx, Y: lsy}, down, up)
+	}
+	ctx.Sublayers = placed
+	return world
+}
+
+// paintStairwells draws each stairwell's trigger sprite on its tile.
+func paintStairwells(world *levels.LayeredLevel) {
+	for _, link := range world.Stairwells {
+		meta, ok := leveleditor.SpriteRegistry[link.TriggerSprite]
+		if !ok || link.FromLayerIndex >= len(world.Layers) {
+			continue
+		}
+		if t := world.Layers[link.FromLayerIndex].Tile(link.FromTile.X, link.FromTile.Y); t != nil {
+			t.AddSpriteByID(link.TriggerSprite, meta.Image)
+			t.IsWalkable = true
+		}
+	}
+}
+
+// layerState is everything a floor layer keeps while the player is on another
+// one, so monsters, loot and explored tiles are as they were left.
+type layerState struct {
+	Monsters  []*entities.Monster
+	ItemDrops []*entities.ItemDrop
+	Chests    []*entities.Chest
+	NPCs      []*entities.NPC
+	Traps     []*entities.Trap
+	Exit      *entities.ExitEntity
+	SeenTiles [][]bool
+}
+
+// stashLayer captures the live entities of the current layer.
+func (g *Game) stashLayer() *layerState {
+	return &layerState{
+		Monsters:  g.Monsters,
+		ItemDrops: g.ItemDrops,
+		Chests:    g.Chests,
+		NPCs:      g.NPCs,
+		Traps:     g.Traps,
+		Exit:      g.ExitEntity,
+		SeenTiles: g.SeenTiles,
+	}
+}
+
+// unstashLayer makes a stashed layer's entities live again. The current level
+// must already be the layer the state belongs to.
+func (g *Game) unstashLayer(st *layerState) {
+	g.Monsters = st.Monsters
+	g.ItemDrops = st.ItemDrops
+	g.Chests = st.Chests
+	g.NPCs = st.NPCs
+	g.Traps = st.Traps
+	g.ExitEntity = st.Exit
+	g.UpdateSeenTiles(*g.currentLevel)
+	if len(st.SeenTiles) == g.currentLevel.H {
+		g.SeenTiles = st.SeenTiles
+	}
+	g.MonsterProjectiles = nil
+}
+
+// populateSublayers spawns each sublayer's keys, monsters and chests the way
+// startFloor does for the main layer, then stashes them until the player
+// takes the stairs. The exit portal goes on the last sublayer.
+func (g *Game) populateSublayers(ctx FloorContext) {
+	g.layerStates = map[*levels.Level]*layerState{}
+	if len(g.currentWorld.Layers) < 2 {
+		return
+	}
+	mainLevel, main := g.currentLevel, g.stashLayer()
+	px, py := g.player.TileX, g.player.TileY
+	last := len(g.currentWorld.Layers) - 1
+	for i, l := range g.currentWorld.Layers[1:] {
+		g.currentLevel = l
+		sx, sy, ex, ey := levels.FindSpawnAndExit(l)
+		g.placePlayerAt(sx, sy)
+		g.ExitEntity = nil
+		if i+1 == last {
+			g.ExitEntity = entities.NewExitEntity(ex, ey, g.spriteSheet.Portal, "Portal")
+		}
+		g.UpdateSeenTiles(*l)
+		g.spawnEntitiesFromLevel()
+		levels.TagRooms(l, sx, sy, ex, ey, false)
+		g.spawnEncounterMonsters(ctx)
+		g.NPCs = []*entities.NPC{}
+		g.Chests = []*entities.Chest{}
+		g.spawnFloorChests(ctx)
+		g.layerStates[l] = g.stashLayer()
+	}
+	g.currentLevel = mainLevel
+	g.unstashLayer(main)
+	g.placePlayerAt(px, py)
+}
+
+// sublayerName returns the name of the sublayer at world layer index, or "".
+func (ctx *FloorContext) sublayerName(index int) string {
+	if index < 1 || index > len(ctx.Sublayers) {
+		return ""
+	}
+	return ctx.Sublayers[index-1].Name
+}
@@ -20,6 +20,7 @@ type MetaSave struct {
 	BestFloor  int                       `json:"best_floor"`
 	TotalKills int                       `json:"total_kills"`
 	NPCMeta    map[string]*NPCMetaState  `json:"npc_meta,omitempty"`
+	Unlocks    map[string]bool           `json:"unlocks,omitempty"` // shop unlocks bought with Remnants
 }
 
 const metaSavePath = "meta.json"
@@ -161,7 +161,7 @@ func (g *Game) openDialogue(npc *entities.NPC) {
 		}
 		if !g.Meta.NPCMeta[npc.ID].Met {
 			g.Meta.NPCMeta[npc.ID].Met = true
-			SaveMeta(g.Meta)
+			g.saveMeta()
 		}
 	}
 
@@ -306,7 +306,7 @@ func (g *Game) execDialogueAction(a dialogue.DialogueAction) {
 			}
 			// Flush accumulated run trust into persistent total.
 			state.TotalTrust += g.RunState.QuestFlags[npcID+"_trust"]
-			SaveMeta(g.Meta)
+			g.saveMeta()
 		}
 	case "add_trust":
 		// a.Flag = NPC id, a.Value = trust delta (can be negative).
@@ -365,7 +365,7 @@ func (g *Game) spawnMajorNPCs(ctx FloorContext) {
 			continue
 		}
 
-		x, y := g.findNPCPlacement(def.Placement, avoid)
+		x, y := g.findNPCPlacement(def.Placement, avoid, ctx.RNG.NPCs)
 		if x < 0 {
 			continue
 		}
@@ -412,7 +412,7 @@ func (g *Game) spawnFloorNPCs(ctx FloorContext) {
 		if t.SpawnMaxFloor > 0 && ctx.FloorNumber > t.SpawnMaxFloor {
 			continue
 		}
-		if t.SpawnChance > 0 && rand.Float64() > t.SpawnChance {
+		if t.SpawnChance > 0 && ctx.RNG.NPCs.Float64() > t.SpawnChance {
 			continue
 		}
 		eligible = append(eligible, t)
@@ -441,7 +441,7 @@ func (g *Game) spawnFloorNPCs(ctx FloorContext) {
 	}
 
 	for _, tmpl := range eligible {
-		x, y := g.findNPCPlacement(tmpl.effectivePlacement(), avoid)
+		x, y := g.findNPCPlacement(tmpl.effectivePlacement(), avoid, ctx.RNG.NPCs)
 		if x < 0 {
 			continue
 		}
@@ -452,7 +452,7 @@ func (g *Game) spawnFloorNPCs(ctx FloorContext) {
 }
 
 // findNPCPlacement finds a tile for the given spawn strategy.
-func (g *Game) findNPCPlacement(strategy SpawnStrategy, avoid map[[2]int]bool) (int, int) {
+func (g *Game) findNPCPlacement(strategy SpawnStrategy, avoid map[[2]int]bool, rng *rand.Rand) (int, int) {
 	lvl := g.currentLevel
 	switch strategy {
 	case SpawnQuest:
@@ -468,7 +468,7 @@ func (g *Game) findNPCPlacement(strategy SpawnStrategy, avoid map[[2]int]bool) (
 		// Common or crossroads rooms.
 		candidates := levels.RoomsByTag(lvl.Rooms, levels.TagCommon)
 		candidates = append(candidates, levels.RoomsByTag(lvl.Rooms, levels.TagCrossroads)...)
-		rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+		rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
 		for _, r := range candidates {
 			if r.HasTag(levels.TagCleared) || r.HasTag(levels.TagBossArena) {
 				continue
@@ -484,7 +484,12 @@ func (g *Game) findNPCPlacement(strategy SpawnStrategy, avoid map[[2]int]bool) (
 		return findCorridorTile(lvl, avoid)
 
 	case SpawnHidden:
-		// Dead-end rooms or dead-end corridor tiles.
+		// Secret rooms, then dead-end rooms or dead-end corridor tiles.
+		for _, r := range levels.RoomsByTag(lvl.Rooms, levels.TagSecret) {
+			if x, y := findWalkableInRoom(lvl, r, avoid); x >= 0 {
+				return x, y
+			}
+		}
 		return findDeadEndTile(lvl, avoid)
 
 	case SpawnEntrance:
@@ -513,10 +518,10 @@ func (g *Game) findNPCPlacement(strategy SpawnStrategy, avoid map[[2]int]bool) (
 	return -1, -1
 }
 
-// findWalkableInRoom finds a walkable tile in the room that isn't in the avoid set.
+// findWalkableInRoom finds a safe walkable tile in the room that isn't in the avoid set.
 func findWalkableInRoom(lvl *levels.Level, r *levels.Room, avoid map[[2]int]bool) (int, int) {
 	// Try center first.
-	if lvl.IsWalkable(r.CenterX, r.CenterY) && !avoid[[2]int{r.CenterX, r.CenterY}] {
+	if lvl.IsSafe(r.CenterX, r.CenterY) && !avoid[[2]int{r.CenterX, r.CenterY}] {
 		return r.CenterX, r.CenterY
 	}
 	// BFS outward from center within the room.
@@ -533,7 +538,7 @@ func findWalkableInRoom(lvl *levels.Level, r *levels.Room, avoid map[[2]int]bool
 				continue
 			}
 			visited[p] = true
-			if lvl.IsWalkable(nx, ny) && !avoid[[2]int{nx, ny}] {
+			if lvl.IsSafe(nx, ny) && !avoid[[2]int{nx, ny}] {
 				return nx, ny
 			}
 			queue = append(queue, p)
@@ -632,24 +637,22 @@ func (g *Game) openChest(c *entities.Chest) {
 		return
 	}
 	table := items.BuildDefaultLootTable(string(g.FloorCtx.Biome))
-	if g.FloorCtx.BiomeConfig != nil && g.FloorCtx.BiomeConfig.LootTable != nil {
-		table.Entries = append(table.Entries, g.FloorCtx.BiomeConfig.LootTable.Entries...)
+	if g.FloorCtx.BiomeConfig != nil {
+		table.Entries = append(table.Entries, g.FloorCtx.BiomeConfig.LootSupplement...)
 	}
-	results := items.RollChestLoot(table, c.Variant, g.FloorCtx.FloorNumber)
+	results := items.RollChestLoot(table, c.Variant, g.FloorCtx.FloorNumber, g.FloorCtx.RNG.Loot)
 	for _, r := range results {
-		tmpl, ok := items.Registry[r.ItemID]
-		if !ok {
-			continue
+		if it := r.Item(); it != nil {
+			g.spawnItemDrop(it, c.TileX, c.TileY)
 		}
-		it := &items.Item{ItemTemplate: tmpl, Count: r.Count}
-		g.spawnItemDrop(it, c.TileX, c.TileY)
 	}
 }
 
-// spawnFloorChests places chests in treasure rooms on the current floor.
-// One chest per treasure room; variant scales with floor depth.
+// spawnFloorChests places chests in treasure and secret rooms on the current
+// floor. One chest per room; variant scales with floor depth.
 func (g *Game) spawnFloorChests(ctx FloorContext) {
 	rooms := levels.RoomsByTag(g.currentLevel.Rooms, levels.TagTreasure)
+	rooms = append(rooms, levels.RoomsByTag(g.currentLevel.Rooms, levels.TagSecret)...)
 	if len(rooms) == 0 {
 		return
 	}
@@ -665,7 +668,10 @@ func (g *Game) spawnFloorChests(ctx FloorContext) {
 			continue
 		}
 		avoid[[2]int{x, y}] = true
-		variant := chestVariantForFloor(ctx.FloorNumber, ctx.TotalFloors)
+		variant := chestVariantForFloor(ctx.FloorNumber, ctx.TotalFloors, ctx.RNG.Chests)
+		if room.Lock != 0 || room.Secret {
+			variant = upgradeChestVariant(variant)
+		}
 		chest := &entities.Chest{
 			TileX:   x,
 			TileY:   y,
@@ -677,9 +683,9 @@ func (g *Game) spawnFloorChests(ctx FloorContext) {
 }
 
 // chestVariantForFloor returns a chest tier appropriate for the given floor.
-func chestVariantForFloor(floor, total int) string {
+func chestVariantForFloor(floor, total int, rng *rand.Rand) string {
 	progress := float64(floor) / float64(max(1, total))
-	roll := rand.Float64()
+	roll := rng.Float64()
 	switch {
 	case progress >= 0.75:
 		if roll < 0.20 {
@@ -703,6 +709,20 @@ func chestVariantForFloor(floor, total int) string {
 	}
 }
 
+// chestTiers lists chest variants from worst to best loot.
+var chestTiers = []string{entities.ChestWooden, entities.ChestIron, entities.ChestGold, entities.ChestLocked}
+
+// upgradeChestVariant returns the next tier up; rooms behind a locked door
+// or a cracked wall pay the player back for finding the way in.
+func upgradeChestVariant(variant string) string {
+	for i, v := range chestTiers {
+		if v == variant && i+1 < len(chestTiers) {
+			return chestTiers[i+1]
+		}
+	}
+	return variant
+}
+
 // spawnHubNPCs places major NPCs in the hub that the player has previously met.
 func (g *Game) spawnHubNPCs() {
 	if g.Meta == nil {
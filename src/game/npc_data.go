@@ -29,6 +29,7 @@ type NPCTemplate struct {
 	PortraitID    string        // key into SpriteMap for dialogue portrait
 	IsMajor       bool
 	DialogueID    string        // dialogue tree ID or SimpleDialogue ID
+	Merchant      bool          // opens the shop instead of a dialogue
 	Biomes        []Biome       // which biomes this NPC appears in (empty = all)
 	Placement     SpawnStrategy // placement tier (default = ambient)
 	SpawnChance   float64       // 0.0-1.0, probability per eligible floor (0 = always)
@@ -170,6 +171,11 @@ var minorNPCPool = []NPCTemplate{
 		DialogueID: "hollow_monk", Biomes: []Biome{BiomeCrypt, BiomeCatacomb},
 		Placement: SpawnAmbient,
 	},
+	{
+		ID: "merchant", Name: "Wandering Merchant", SpriteID: "BlueMan", PortraitID: "BlueMan",
+		Merchant: true, Biomes: nil, // all biomes
+		Placement: SpawnExit, SpawnChance: 0.3, SpawnMinFloor: 2,
+	},
 	{
 		ID: "scavenger", Name: "Scavenger", SpriteID: "Caveman", PortraitID: "Caveman",
 		DialogueID: "scavenger", Biomes: nil, // all biomes
@@ -229,6 +235,7 @@ func (g *Game) createNPCFromTemplate(tmpl NPCTemplate, x, y int) *entities.NPC {
 		PortraitID:    tmpl.PortraitID,
 		IsMajor:       tmpl.IsMajor,
 		DialogueID:    tmpl.DialogueID,
+		Merchant:      tmpl.Merchant,
 		Interactable:  true,
 		InteractRange: 2.0,
 		Behavior:      entities.NewIdleBehavior(6),
@@ -0,0 +1,77 @@
+package game
+
+import (
+	"dungeoneer/entities"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+)
+
+// Noise radii in tiles. Monsters inside a radius hear the noise, loudest at
+// its source.
+const (
+	spellNoiseRadius = 8
+	doorNoiseRadius  = 6
+	dashNoiseRadius  = 5
+	// noiseLoudness is the suspicion a noise adds right at its source; a
+	// monster within the inner third of the radius is alerted outright.
+	noiseLoudness = 1.5
+)
+
+// emitNoise lets every monster within radius of (x, y) hear a noise there.
+// It fades with distance and is muffled by half through walls.
+func (g *Game) emitNoise(x, y, radius int) {
+	for _, m := range g.Monsters {
+		if m.IsDead {
+			continue
+		}
+		d := math.Hypot(float64(m.TileX-x), float64(m.TileY-y))
+		if d > float64(radius) {
+			continue
+		}
+		loudness := noiseLoudness * (1 - d/float64(radius))
+		if !g.hasLineOfSight(x, y, m.TileX, m.TileY) {
+			loudness /= 2
+		}
+		m.Hear(x, y, loudness)
+	}
+}
+
+// emitPlayerNoise emits a noise of radius at the player's tile.
+func (g *Game) emitPlayerNoise(radius int) {
+	if g.player != nil {
+		g.emitNoise(g.player.TileX, g.player.TileY, radius)
+	}
+}
+
+// drawAlertIndicators marks visible monsters that have noticed the player
+// with a red "!" and those that are suspicious or searching with a "?".
+func (g *Game) drawAlertIndicators(target *ebiten.Image, scale, cx, cy float64) {
+	for _, m := range g.Monsters {
+		if m.IsDead || !g.isTileVisible(m.TileX, m.TileY) {
+			continue
+		}
+		var msg string
+		var clr color.Color
+		switch m.Perception.State {
+		case entities.AwarenessAlert:
+			msg, clr = "!", color.NRGBA{255, 60, 60, 255}
+		case entities.AwarenessSuspicious, entities.AwarenessSearching:
+			msg, clr = "?", color.NRGBA{255, 220, 0, 255}
+		default:
+			continue
+		}
+		// Just above the health bar, or above an elite's name.
+		xi, yi := g.cartesianToIso(m.InterpX, m.InterpY)
+		above := 14.0
+		if len(m.Affixes) > 0 {
+			above = 28
+		}
+		drawX := (xi+32-g.camX)*scale + cx
+		drawY := (yi-above+g.camY)*scale + cy
+		text.Draw(target, msg, basicfont.Face7x13, int(drawX), int(drawY), clr)
+	}
+}
@@ -0,0 +1,192 @@
+package game
+
+import (
+	"dungeoneer/items"
+	"dungeoneer/leveleditor"
+	"dungeoneer/levels"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// prefabDir holds hand-authored vaults, one per file.
+const prefabDir = "prefabs"
+
+// vaultChance is the chance that a floor gets a vault when one is eligible.
+const vaultChance = 0.5
+
+// prefabFile is a level editor save (LevelData) with the fields that turn the
+// fragment into a vault. Entrances are walkable tiles on the fragment's edge,
+// in fragment coordinates.
+type prefabFile struct {
+	leveleditor.LevelData
+	ID        string           `json:"id"`
+	Entrances []levels.Point   `json:"entrances"`
+	RoomTags  []levels.RoomTag `json:"room_tags,omitempty"`
+	Weight    int              `json:"weight"`
+	MinFloor  int              `json:"min_floor"`
+	MaxFloor  int              `json:"max_floor,omitempty"` // 0 = no limit
+	Biomes    []Biome          `json:"biomes,omitempty"`    // empty = any biome
+}
+
+// PrefabDef is a loaded vault and the floors it may appear on.
+type PrefabDef struct {
+	Prefab   *levels.Prefab
+	Weight   int
+	MinFloor int
+	MaxFloor int
+	Biomes   []Biome
+}
+
+// prefabDefs holds every vault that passed validation, sorted by ID.
+var prefabDefs []*PrefabDef
+
+// Entity types a vault may place; see spawnEntitiesFromLevel.
+var validPrefabEntities = []string{"AmbushMonster", "ItemDrop", levels.EntityTrap}
+
+// LoadPrefabs reads every .json file in dir as a vault. Sprites and items
+// must already be registered and biomes loaded. Invalid vaults are reported
+// and skipped.
+func LoadPrefabs(dir string) error {
+	prefabDefs = nil
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("prefabs: readdir %s: %w", dir, err)
+	}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("prefabs: read %s: %v\n", path, err)
+			continue
+		}
+		var f prefabFile
+		if err := json.Unmarshal(data, &f); err != nil {
+			fmt.Printf("prefabs: parse %s: %v\n", path, err)
+			continue
+		}
+		def, errs := buildPrefab(&f)
+		if seen[f.ID] {
+			errs = append(errs, fmt.Errorf("duplicate id"))
+		}
+		if len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Printf("prefabs: %s: %v\n", path, err)
+			}
+			continue
+		}
+		seen[f.ID] = true
+		prefabDefs = append(prefabDefs, def)
+	}
+	sort.Slice(prefabDefs, func(i, j int) bool { return prefabDefs[i].Prefab.ID < prefabDefs[j].Prefab.ID })
+	return nil
+}
+
+// buildPrefab validates a vault file and converts it.
+func buildPrefab(f *prefabFile) (*PrefabDef, []error) {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if f.Width <= 0 || f.Height <= 0 || len(f.Tiles) != f.Height {
+		fail("tiles do not match %dx%d", f.Width, f.Height)
+		return nil, errs
+	}
+	for y, row := range f.Tiles {
+		if len(row) != f.Width {
+			fail("row %d has %d tiles, want %d", y, len(row), f.Width)
+			return nil, errs
+		}
+	}
+	for _, id := range f.SpritePalette {
+		if _, ok := leveleditor.SpriteRegistry[id]; !ok {
+			fail("unknown sprite %q", id)
+		}
+	}
+	if f.Weight <= 0 {
+		fail("weight must be positive")
+	}
+	if f.MinFloor < 1 {
+		fail("min_floor must be at least 1")
+	}
+	if f.MaxFloor != 0 && f.MaxFloor < f.MinFloor {
+		fail("max_floor %d is below min_floor %d", f.MaxFloor, f.MinFloor)
+	}
+	for _, b := range f.Biomes {
+		if _, ok := BiomeConfigs[b]; !ok {
+			fail("unknown biome %q", b)
+		}
+	}
+	for _, tag := range f.RoomTags {
+		if !containsTag(validRoomTags, tag) {
+			fail("unknown room tag %q", tag)
+		}
+	}
+	for _, ent := range f.Entities {
+		switch {
+		case !contains(validPrefabEntities, ent.Type):
+			fail("entity at (%d,%d): unknown type %q", ent.X, ent.Y, ent.Type)
+		case ent.Type == levels.EntityTrap:
+			if !contains(levels.TrapKinds, ent.Trap) {
+				fail("entity at (%d,%d): unknown trap %q", ent.X, ent.Y, ent.Trap)
+			}
+		case ent.Type == "ItemDrop":
+			if _, ok := items.Registry[ent.SpriteID]; !ok {
+				fail("entity at (%d,%d): unknown item %q", ent.X, ent.Y, ent.SpriteID)
+			}
+		default:
+			if _, ok := leveleditor.SpriteRegistry[ent.SpriteID]; !ok {
+				fail("entity at (%d,%d): unknown sprite %q", ent.X, ent.Y, ent.SpriteID)
+			}
+		}
+	}
+
+	pf := &levels.Prefab{
+		ID:        f.ID,
+		Level:     leveleditor.ConvertToLevel(&f.LevelData),
+		Entrances: f.Entrances,
+		Tags:      f.RoomTags,
+	}
+	errs = append(errs, pf.Validate()...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &PrefabDef{Prefab: pf, Weight: f.Weight, MinFloor: f.MinFloor, MaxFloor: f.MaxFloor, Biomes: f.Biomes}, nil
+}
+
+// choosePrefabs rolls whether the floor gets a vault and, if so, picks one by
+// weight from those allowed on this floor and biome.
+func choosePrefabs(floor int, biome Biome, rng *rand.Rand) []*levels.Prefab {
+	var eligible []*PrefabDef
+	total := 0
+	for _, d := range prefabDefs {
+		if floor < d.MinFloor || (d.MaxFloor != 0 && floor > d.MaxFloor) {
+			continue
+		}
+		if len(d.Biomes) > 0 && !containsBiome(d.Biomes, biome) {
+			continue
+		}
+		eligible = append(eligible, d)
+		total += d.Weight
+	}
+	if total == 0 || rng.Float64() >= vaultChance {
+		return nil
+	}
+	roll := rng.IntN(total)
+	for _, d := range eligible {
+		if roll < d.Weight {
+			return []*levels.Prefab{d.Prefab}
+		}
+		roll -= d.Weight
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package game
+
+import (
+	"dungeoneer/entities"
+	"dungeoneer/items"
+	"dungeoneer/simrand"
+	"math"
+	"sort"
+)
+
+const (
+	// chainRadius is how far, in tiles, chain lightning looks for monsters
+	// to arc to, and chainJumps how many it strikes at most.
+	chainRadius = 4.0
+	chainJumps  = 3
+	// A burn proc sets its monster alight for burnSeconds, ticking once a
+	// second.
+	burnSeconds = 3
+	// burnProcSource marks burns set by the player's gear.
+	burnProcSource = "item_burn"
+)
+
+// procEvent is what set off an item effect trigger.
+type procEvent struct {
+	// Target is the monster hit or killed, or the one that struck the
+	// player. It is nil when there is none, as for a cast or a dash.
+	Target *entities.Monster
+	// Amount is what the effect scales with: the damage dealt or taken,
+	// the max HP of a kill, the mana a spell cost or, for a dash, the
+	// player's damage.
+	Amount int
+	// X, Y is where the event happened, in tiles.
+	X, Y float64
+}
+
+// fireProcs rolls every equipped item and set bonus effect listening for
+// trigger and applies those that go off. Effects with no chance always go
+// off.
+func (g *Game) fireProcs(trigger string, ev procEvent) {
+	if g.player == nil || g.player.IsDead {
+		return
+	}
+	for _, eff := range g.player.ItemEffects() {
+		if eff.Trigger != trigger {
+			continue
+		}
+		if eff.ChancePct > 0 && simrand.IntN(100) >= eff.ChancePct {
+			continue
+		}
+		g.applyProc(eff, ev)
+	}
+}
+
+// applyProc applies one item effect that went off.
+func (g *Game) applyProc(eff *items.ItemEffect, ev procEvent) {
+	amount := max(1, ev.Amount*eff.MagnitudePct/100)
+	switch eff.Type {
+	case items.EffectLifesteal:
+		p := g.player
+		healed := min(amount, p.MaxHP-p.HP)
+		if healed <= 0 {
+			return
+		}
+		p.HP += healed
+		g.addHealNumber(healed, "")
+	case items.EffectManaRefund:
+		p := g.player
+		refund := min(amount, p.MaxMana-p.Mana)
+		if refund <= 0 {
+			return
+		}
+		p.Mana += refund
+		g.addHealNumber(refund, "mana")
+	case items.EffectBurn:
+		m := ev.Target
+		if m == nil || m.IsDead {
+			return
+		}
+		m.Effects.AddEffect(&entities.StatusEffect{
+			Type: entities.EffectBurn, Duration: burnSeconds, TickRate: 1, Value: amount, Source: burnProcSource,
+		})
+		g.DamageNumbers = append(g.DamageNumbers, entities.DamageNumber{
+			X: float64(m.TileX), Y: float64(m.TileY), Value: amount, MaxTicks: 30, Label: "burn",
+		})
+	case items.EffectThorns:
+		if m := ev.Target; m != nil && !m.IsDead {
+			g.procDamage(m, amount, "thorns")
+		}
+	case items.EffectChainLightning:
+		for _, m := range g.chainTargets(ev) {
+			g.procDamage(m, amount, "chain")
+		}
+	}
+}
+
+// procDamage deals dmg from an item effect to m. Proc damage does not fire
+// further procs, so effects can't set each other off forever.
+func (g *Game) procDamage(m *entities.Monster, dmg int, label string) {
+	died := m.TakeDamage(dmg, &g.HitMarkers, &g.DamageNumbers)
+	g.DamageNumbers[len(g.DamageNumbers)-1].Label = label
+	if died {
+		g.handleMonsterDeath(m)
+	}
+}
+
+// chainTargets returns the living monsters chain lightning arcs to from ev:
+// the nearest few in sight, not counting the monster that was hit.
+func (g *Game) chainTargets(ev procEvent) []*entities.Monster {
+	type near struct {
+		m    *entities.Monster
+		dist float64
+	}
+	var found []near
+	for _, m := range g.Monsters {
+		if m.IsDead || m == ev.Target {
+			continue
+		}
+		d := math.Hypot(m.InterpX-ev.X, m.InterpY-ev.Y)
+		if d > chainRadius || !g.hasLineOfSight(int(ev.X), int(ev.Y), m.TileX, m.TileY) {
+			continue
+		}
+		found = append(found, near{m, d})
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].dist < found[j].dist })
+	targets := make([]*entities.Monster, 0, chainJumps)
+	for i := 0; i < len(found) && i < chainJumps; i++ {
+		targets = append(targets, found[i].m)
+	}
+	return targets
+}
+
+// addHealNumber floats value up from the player in green.
+func (g *Game) addHealNumber(value int, label string) {
+	g.HealNumbers = append(g.HealNumbers, entities.DamageNumber{
+		X:        g.player.MoveController.InterpX,
+		Y:        g.player.MoveController.InterpY,
+		Value:    value,
+		MaxTicks: 40,
+		Label:    label,
+	})
+}
+
+// hitMonster deals dmg from the player to m, firing the player's on-hit and
+// on-kill effects. A monster that survives knows where the hit came from,
+// even if it never saw the player. It reports whether m died.
+func (g *Game) hitMonster(m *entities.Monster, dmg int) bool {
+	died := m.TakeDamage(dmg, &g.HitMarkers, &g.DamageNumbers)
+	if !died && m.Senses() {
+		m.Alert(g.player.TileX, g.player.TileY)
+	}
+	at := procEvent{Target: m, Amount: dmg, X: m.InterpX, Y: m.InterpY}
+	g.fireProcs(items.TriggerOnHit, at)
+	if died {
+		g.handleMonsterDeath(m)
+		at.Amount = m.MaxHP
+		g.fireProcs(items.TriggerOnKill, at)
+	}
+	return died
+}
+
+// tickKilled settles a monster killed by a burn or poison tick. A burn set
+// by the player's gear makes it the player's kill, firing on-kill effects.
+func (g *Game) tickKilled(m *entities.Monster) {
+	g.handleMonsterDeath(m)
+	for _, e := range m.Effects.Effects {
+		if e.Source == burnProcSource {
+			g.fireProcs(items.TriggerOnKill, procEvent{Target: m, Amount: m.MaxHP, X: m.InterpX, Y: m.InterpY})
+			return
+		}
+	}
+}
+
+// playerStruck fires the player's on-damaged effects after taking dmg,
+// from attacker if a monster dealt it up close.
+func (g *Game) playerStruck(attacker *entities.Monster, dmg int) {
+	ev := procEvent{Target: attacker, Amount: dmg, X: g.player.MoveController.InterpX, Y: g.player.MoveController.InterpY}
+	g.fireProcs(items.TriggerOnDamaged, ev)
+}
+
+// playerActed fires the player's on-cast or on-dash effects. amount is the
+// mana the spell cost, or the player's damage for a dash.
+func (g *Game) playerActed(trigger string, amount int) {
+	ev := procEvent{Amount: amount, X: g.player.MoveController.InterpX, Y: g.player.MoveController.InterpY}
+	g.fireProcs(trigger, ev)
+}
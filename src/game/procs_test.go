@@ -0,0 +1,158 @@
+package game
+
+import (
+	"dungeoneer/entities"
+	"dungeoneer/items"
+	"testing"
+)
+
+// equipEffect puts an item with eff in the player's first ring slot.
+func equipEffect(p *entities.Player, eff items.ItemEffect) {
+	p.Equipment["Ring1"] = &items.Item{
+		ItemTemplate: &items.ItemTemplate{ID: "test_ring", Name: "Test Ring", Effect: &eff},
+		Count:        1,
+	}
+}
+
+func newDummy(x, y, hp int) *entities.Monster {
+	return &entities.Monster{
+		Name: "Dummy", TileX: x, TileY: y,
+		InterpX: float64(x), InterpY: float64(y),
+		HP: hp, MaxHP: hp, Level: 1, MovementDuration: 30,
+	}
+}
+
+func TestOnHitChainLightningArcsToNeighbours(t *testing.T) {
+	s := newTestSim(t, 7)
+	g := s.Game
+	p := s.Player()
+	p.Abilities = map[string]bool{}
+	p.AttackTick = p.AttackRate
+	equipEffect(p, items.ItemEffect{Trigger: items.TriggerOnHit, Type: items.EffectChainLightning, MagnitudePct: 100})
+
+	mx, my := openNeighbour(t, s)
+	target, bystander := newDummy(mx, my, 100), newDummy(mx, my, 100)
+	g.Monsters = []*entities.Monster{target, bystander}
+
+	if err := s.Run(leftClick(mx, my)); err != nil {
+		t.Fatal(err)
+	}
+	if target.HP == target.MaxHP {
+		t.Fatal("the attack missed its target")
+	}
+	if bystander.HP == bystander.MaxHP {
+		t.Fatal("chain lightning did not arc to the monster next to the target")
+	}
+	labelled := false
+	for _, d := range g.DamageNumbers {
+		labelled = labelled || d.Label == "chain"
+	}
+	if !labelled {
+		t.Fatal("no damage number shows the chain lightning proc")
+	}
+}
+
+func TestThornsHurtMeleeAttacker(t *testing.T) {
+	s := newTestSim(t, 3)
+	g := s.Game
+	p := s.Player()
+	equipEffect(p, items.ItemEffect{Trigger: items.TriggerOnDamaged, Type: items.EffectThorns, MagnitudePct: 100})
+
+	mx, my := openNeighbour(t, s)
+	brute := newDummy(mx, my, 100)
+	brute.Damage, brute.AttackRate = 2, 10
+	g.Monsters = []*entities.Monster{brute}
+	hp := p.HP
+
+	if err := s.StepN(30); err != nil {
+		t.Fatal(err)
+	}
+	if p.HP >= hp {
+		t.Fatal("the monster never struck the player")
+	}
+	if brute.HP == brute.MaxHP {
+		t.Fatal("thorns did not hurt the monster that struck the player")
+	}
+}
+
+func TestProcsRollAndApply(t *testing.T) {
+	s := newTestSim(t, 5)
+	g := s.Game
+	p := s.Player()
+
+	equipEffect(p, items.ItemEffect{Trigger: items.TriggerOnKill, Type: items.EffectLifesteal, MagnitudePct: 10})
+	p.HP = 1
+	mx, my := openNeighbour(t, s)
+	victim := newDummy(mx, my, 50)
+	g.Monsters = []*entities.Monster{victim}
+	if !g.hitMonster(victim, 50) {
+		t.Fatal("dummy survived a killing blow")
+	}
+	if p.HP != 1+5 {
+		t.Fatalf("HP after an on-kill lifesteal = %d, want 6", p.HP)
+	}
+
+	equipEffect(p, items.ItemEffect{Trigger: items.TriggerOnCast, Type: items.EffectManaRefund, MagnitudePct: 50})
+	p.Mana = 0
+	g.playerActed(items.TriggerOnCast, 10)
+	if p.Mana != 5 {
+		t.Fatalf("mana after a refund = %d, want 5", p.Mana)
+	}
+	g.playerActed(items.TriggerOnDash, 10)
+	if p.Mana != 5 {
+		t.Fatal("an on-cast effect went off on a dash")
+	}
+
+	equipEffect(p, items.ItemEffect{Trigger: items.TriggerOnHit, Type: items.EffectBurn, MagnitudePct: 100, ChancePct: 50})
+	burnt := 0
+	for i := 0; i < 200; i++ {
+		m := newDummy(mx, my, 1000)
+		g.hitMonster(m, 4)
+		if m.Effects.HasEffect(entities.EffectBurn) {
+			burnt++
+		}
+	}
+	if burnt < 60 || burnt > 140 {
+		t.Fatalf("a 50%% burn went off %d times in 200 hits", burnt)
+	}
+}
+
+func TestHitAlertsUnawareMonster(t *testing.T) {
+	s := newTestSim(t, 7)
+	g := s.Game
+	p := s.Player()
+	m := newDummy(p.TileX+20, p.TileY, 100)
+	m.Behavior = &entities.PatrolBehavior{TriggerRadius: 1}
+	g.Monsters = []*entities.Monster{m}
+
+	g.hitMonster(m, 5)
+	if !m.Noticed() {
+		t.Fatal("a monster hit from out of sight should turn on the player")
+	}
+	if pc := m.Perception; pc.LastX != p.TileX || pc.LastY != p.TileY {
+		t.Fatalf("monster looks for the player at (%d,%d), want (%d,%d)", pc.LastX, pc.LastY, p.TileX, p.TileY)
+	}
+}
+
+func TestBurnProcKillFiresOnKill(t *testing.T) {
+	s := newTestSim(t, 7)
+	g := s.Game
+	p := s.Player()
+	equipEffect(p, items.ItemEffect{Trigger: items.TriggerOnKill, Type: items.EffectLifesteal, MagnitudePct: 100})
+	mx, my := openNeighbour(t, s)
+	m := newDummy(mx, my, 1)
+	m.Effects.AddEffect(&entities.StatusEffect{
+		Type: entities.EffectBurn, Duration: 5, TickRate: 0.01, Value: 5, Source: burnProcSource,
+	})
+	g.Monsters = []*entities.Monster{m}
+	kills := g.RunState.KillCount
+	p.HP = 1
+
+	g.updateMonsters()
+	if !m.IsDead || g.RunState.KillCount != kills+1 {
+		t.Fatal("the burn kill was not counted")
+	}
+	if p.HP <= 1 {
+		t.Fatal("the burn kill did not fire the player's on-kill effects")
+	}
+}
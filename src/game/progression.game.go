@@ -50,6 +50,10 @@ func (g *Game) handleMonsterDeath(m *entities.Monster) {
 		g.RunState.KillCount++
 	}
 	g.rollAndDropLoot(m)
+	g.rollAffixLoot(m)
+	if m.HasAffix(entities.AffixExplosive) {
+		g.explodeMonster(m)
+	}
 
 	// Check if the killed monster is the boss.
 	if g.CurrentBoss != nil && g.CurrentBoss.Monster == m {
@@ -87,12 +91,7 @@ func (g *Game) rollAndDropLoot(m *entities.Monster) {
 		return
 	}
 
-	// Build the effective loot table: default registry items merged with any
-	// biome-specific ability item boosts.
-	table := items.BuildDefaultLootTable(string(g.FloorCtx.Biome))
-	if g.FloorCtx.BiomeConfig.LootTable != nil {
-		table.Entries = append(table.Entries, g.FloorCtx.BiomeConfig.LootTable.Entries...)
-	}
+	table := g.floorLootTable()
 
 	// Inject active quest items at high weight so they surface through normal
 	// combat. Elites guarantee a quest item on their first kill; regular enemies
@@ -110,9 +109,9 @@ func (g *Game) rollAndDropLoot(m *entities.Monster) {
 	// the player always leaves floor 1 with at least one new ability.
 	if g.FloorCtx.FloorNumber == 1 && !g.FloorCtx.AbilityDropped &&
 		(m.Role == "elite" || m.Role == "boss") {
-		if result := items.RollAbilityItem(table, 1); result != nil {
-			if tmpl, ok := items.Registry[result.ItemID]; ok {
-				g.spawnDrop(m, tmpl, result.Count)
+		if result := items.RollAbilityItem(table, 1, g.FloorCtx.RNG.Loot); result != nil {
+			if it := result.Item(); it != nil {
+				g.spawnDrop(m, it)
 			}
 		}
 		g.FloorCtx.AbilityDropped = true
@@ -121,28 +120,33 @@ func (g *Game) rollAndDropLoot(m *entities.Monster) {
 	// Elites guarantee the quest item if it's still needed.
 	if len(questItems) > 0 && (m.Role == "elite" || m.Role == "boss") {
 		if tmpl, ok := items.Registry[questItems[0]]; ok {
-			g.spawnDrop(m, tmpl, 1)
+			g.spawnDrop(m, &items.Item{ItemTemplate: tmpl, Count: 1})
 			return
 		}
 	}
 
-	if !items.ShouldDrop(m.Role, g.FloorCtx.FloorNumber) {
+	if !items.ShouldDrop(m.Role, g.FloorCtx.FloorNumber, g.FloorCtx.RNG.Loot) {
 		return
 	}
-	result := items.RollLoot(table, g.FloorCtx.FloorNumber)
+	result := items.RollLoot(table, g.FloorCtx.FloorNumber, g.FloorCtx.RNG.Loot)
 	if result == nil {
 		return
 	}
-	tmpl, ok := items.Registry[result.ItemID]
-	if !ok {
-		return
+	if it := result.Item(); it != nil {
+		g.spawnDrop(m, it)
 	}
-	g.spawnDrop(m, tmpl, result.Count)
+}
+
+// floorLootTable builds the effective loot table of the floor: default
+// registry items merged with any biome-specific ability item boosts.
+func (g *Game) floorLootTable() *items.LootTableDef {
+	table := items.BuildDefaultLootTable(string(g.FloorCtx.Biome))
+	table.Entries = append(table.Entries, g.FloorCtx.BiomeConfig.LootSupplement...)
+	return table
 }
 
 // spawnDrop places an item drop at the monster's tile.
-func (g *Game) spawnDrop(m *entities.Monster, tmpl *items.ItemTemplate, count int) {
-	it := &items.Item{ItemTemplate: tmpl, Count: count}
+func (g *Game) spawnDrop(m *entities.Monster, it *items.Item) {
 	g.ItemDrops = append(g.ItemDrops, &entities.ItemDrop{
 		TileX: m.TileX,
 		TileY: m.TileY,
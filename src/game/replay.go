@@ -0,0 +1,448 @@
+package game
+
+import (
+	"dungeoneer/controls"
+	"dungeoneer/entities"
+	"dungeoneer/simrand"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// replayDir is where finished, abandoned and crashed runs are written.
+var replayDir = "replays"
+
+// replayVersion is bumped whenever the frame format changes.
+const replayVersion = 1
+
+// aimStep quantises the recorded cursor to 1/8 of a tile. The live game sees
+// the same quantised value, so replays stay exact while cursor jitter and
+// camera drift don't produce a new frame every tick.
+const aimStep = 8.0
+
+// recordedKeys are the raw keys gameplay reads outside of the action bindings.
+var recordedKeys = []ebiten.Key{
+	ebiten.KeyEscape, ebiten.KeyQ, ebiten.KeyTab, ebiten.KeyD,
+	ebiten.KeyF3, ebiten.KeyF5, ebiten.KeyF6, ebiten.KeyF12,
+}
+
+// recordedClicks are the mouse buttons gameplay reads as clicks.
+var recordedClicks = []ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonRight}
+
+// ReplayFrame is a run of identical input frames starting at Tick. Ticks
+// with no input are not stored at all.
+type ReplayFrame struct {
+	Tick   int `json:"t"`
+	Repeat int `json:"n,omitempty"`
+	InputFrame
+}
+
+// Kinds of ReplayEvent.
+const (
+	replayBuy    = "buy"
+	replaySell   = "sell"
+	replayUnlock = "unlock"
+)
+
+// ReplayEvent is a trade made at a merchant. Trades happen while the game is
+// paused, so they are kept apart from the frames and applied before Tick.
+type ReplayEvent struct {
+	Tick  int    `json:"t"`
+	Kind  string `json:"kind"`
+	Index int    `json:"i,omitempty"` // ware bought
+	X     int    `json:"x,omitempty"` // inventory cell sold
+	Y     int    `json:"y,omitempty"`
+	ID    string `json:"id,omitempty"` // unlock bought
+}
+
+// Replay is a recorded run: everything needed to rebuild the run's starting
+// state plus the input of every simulated tick.
+//
+// Gameplay input and the input of the in-run panels (dialogue, inventory,
+// hero panel) are recorded. The panels are clicked in screen space, so a
+// replay should be played at the window size it was recorded at. Ticks spent
+// paused are skipped; trades made at a merchant meanwhile are recorded as
+// Events. The developer menus' loads are not recorded.
+type Replay struct {
+	Version     int             `json:"version"`
+	Seed        int64           `json:"seed"`
+	TotalFloors int             `json:"total_floors"`
+	SimRNG      []byte          `json:"sim_rng"`
+	Player      json.RawMessage `json:"player"`
+	Meta        json.RawMessage `json:"meta"`
+	Resume      json.RawMessage `json:"resume,omitempty"` // RunSave the recording started from, if continued
+	Ticks       int             `json:"ticks"`
+	Frames      []ReplayFrame   `json:"frames"`
+	Events      []ReplayEvent   `json:"events,omitempty"`
+}
+
+// tickedInput is implemented by sources that load a new frame each tick.
+type tickedInput interface {
+	Advance()
+}
+
+// replayRecorder sits between the game and the real input source. Each tick
+// it samples the source once, answers the game's queries from that sample and
+// keeps it if the tick ran the simulation.
+type replayRecorder struct {
+	g          *Game
+	inner      InputSource
+	rec        Replay
+	cur        InputFrame
+	aim        Aim
+	aimed      bool
+	last       *Aim // last aim written to the replay
+	cursor     Cursor
+	read       bool    // whether a panel read the screen cursor this tick
+	lastCursor *Cursor // last screen cursor written to the replay
+	prevOK     bool    // whether Frames ends with a frame for the previous tick
+}
+
+func newReplayRecorder(g *Game, inner InputSource) *replayRecorder {
+	return &replayRecorder{g: g, inner: inner}
+}
+
+// Advance samples the inner source for the coming tick.
+func (r *replayRecorder) Advance() {
+	if src, ok := r.inner.(tickedInput); ok {
+		src.Advance()
+	}
+	r.cur = InputFrame{}
+	r.aimed = false
+	r.read = false
+	for _, a := range controls.GetAllActionIDs() {
+		if r.inner.ActionJustPressed(a) {
+			r.cur.Pressed = append(r.cur.Pressed, a)
+		} else if r.inner.ActionPressed(a) {
+			r.cur.Held = append(r.cur.Held, a)
+		}
+	}
+	for _, k := range recordedKeys {
+		if r.inner.KeyJustPressed(k) {
+			r.cur.Keys = append(r.cur.Keys, k)
+		}
+	}
+	for _, b := range recordedClicks {
+		if r.inner.MouseJustPressed(b) {
+			r.cur.Clicks = append(r.cur.Clicks, b)
+		}
+	}
+}
+
+func (r *replayRecorder) ActionPressed(action controls.ActionID) bool {
+	return containsAction(r.cur.Held, action) || containsAction(r.cur.Pressed, action)
+}
+
+func (r *replayRecorder) ActionJustPressed(action controls.ActionID) bool {
+	return containsAction(r.cur.Pressed, action)
+}
+
+func (r *replayRecorder) KeyJustPressed(key ebiten.Key) bool {
+	for _, k := range r.cur.Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *replayRecorder) MouseJustPressed(button ebiten.MouseButton) bool {
+	for _, b := range r.cur.Clicks {
+		if b == button {
+			return true
+		}
+	}
+	return false
+}
+
+// Held keys, held buttons, the wheel and the screen cursor only steer the
+// camera and the editor, so they pass straight through unrecorded.
+func (r *replayRecorder) KeyPressed(key ebiten.Key) bool { return r.inner.KeyPressed(key) }
+func (r *replayRecorder) MousePressed(button ebiten.MouseButton) bool {
+	return r.inner.MousePressed(button)
+}
+func (r *replayRecorder) CursorPosition() (int, int) { return r.inner.CursorPosition() }
+func (r *replayRecorder) Wheel() (float64, float64)  { return r.inner.Wheel() }
+
+// CursorWorld resolves the cursor once per tick and records it.
+func (r *replayRecorder) CursorWorld() (float64, float64, bool) {
+	if !r.aimed {
+		x, y := r.g.sourceCursorWorld(r.inner)
+		r.aim = Aim{X: math.Round(x*aimStep) / aimStep, Y: math.Round(y*aimStep) / aimStep}
+		r.aimed = true
+	}
+	return r.aim.X, r.aim.Y, true
+}
+
+// PanelCursor samples the screen cursor once per tick and records it.
+func (r *replayRecorder) PanelCursor() (int, int) {
+	if !r.read {
+		r.cursor.X, r.cursor.Y = panelSource{src: r.inner}.CursorPosition()
+		r.read = true
+	}
+	return r.cursor.X, r.cursor.Y
+}
+
+// PanelMousePressed records the buttons a panel finds held down.
+func (r *replayRecorder) PanelMousePressed(button ebiten.MouseButton) bool {
+	if !(panelSource{src: r.inner}).MousePressed(button) {
+		return false
+	}
+	if !slices.Contains(r.cur.Buttons, button) {
+		r.cur.Buttons = append(r.cur.Buttons, button)
+	}
+	return true
+}
+
+// commit appends the current tick's frame to the recording.
+func (r *replayRecorder) commit() {
+	tick := r.rec.Ticks
+	r.rec.Ticks++
+	f := r.cur
+	if r.aimed && (r.last == nil || *r.last != r.aim) {
+		aim := r.aim
+		f.Aim = &aim
+		r.last = &aim
+	}
+	if r.read && (r.lastCursor == nil || *r.lastCursor != r.cursor) {
+		cursor := r.cursor
+		f.Cursor = &cursor
+		r.lastCursor = &cursor
+	}
+	if reflect.DeepEqual(f, InputFrame{}) {
+		r.prevOK = false
+		return
+	}
+	if n := len(r.rec.Frames); r.prevOK && n > 0 && f.Aim == nil && f.Cursor == nil {
+		prev := &r.rec.Frames[n-1]
+		pf := prev.InputFrame
+		pf.Aim, pf.Cursor = nil, nil
+		if reflect.DeepEqual(pf, f) {
+			prev.Repeat++
+			return
+		}
+	}
+	r.rec.Frames = append(r.rec.Frames, ReplayFrame{Tick: tick, InputFrame: f})
+	r.prevOK = true
+}
+
+// recordEvent adds a trade to the recording, if one is running. It belongs
+// before the next simulated tick.
+func (g *Game) recordEvent(e ReplayEvent) {
+	if g.recorder == nil {
+		return
+	}
+	e.Tick = g.recorder.rec.Ticks
+	g.recorder.rec.Events = append(g.recorder.rec.Events, e)
+}
+
+// save writes the recording to the replays directory.
+func (r *replayRecorder) save() (string, error) {
+	if err := os.MkdirAll(replayDir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(&r.rec)
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s.json", time.Now().Format("20060102-150405"), FormatSeed(r.rec.Seed))
+	path := filepath.Join(replayDir, name)
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// startRecording begins capturing input for the active run. resume is the
+// RunSave the run was continued from, or nil for a fresh run.
+func (g *Game) startRecording(resume *RunSave) {
+	if !g.RecordReplays || g.replaying || g.RunState == nil {
+		return
+	}
+	g.finishRecording()
+	r := newReplayRecorder(g, g.input)
+	r.rec = Replay{
+		Version:     replayVersion,
+		Seed:        g.RunState.Seed,
+		TotalFloors: g.RunState.TotalFloors,
+		SimRNG:      simrand.State(),
+	}
+	if resume != nil {
+		r.rec.Resume, _ = json.Marshal(resume)
+	} else {
+		r.rec.Player, _ = json.Marshal(g.player.ToSaveData())
+		r.rec.Meta, _ = json.Marshal(g.Meta)
+	}
+	g.recorder = r
+	g.input = r
+}
+
+// finishRecording writes the current recording, if any, and hands input back
+// to the underlying source.
+func (g *Game) finishRecording() {
+	r := g.recorder
+	if r == nil {
+		return
+	}
+	g.recorder = nil
+	g.input = r.inner
+	path, err := r.save()
+	if err != nil {
+		fmt.Println("Error saving replay:", err)
+		return
+	}
+	fmt.Println("Replay saved to", path)
+}
+
+// recordTick runs one Update while recording. A frame is kept only if the
+// tick simulated the run, i.e. it neither started nor ended paused. A panic
+// keeps the crashing frame and writes the replay before propagating.
+func (g *Game) recordTick() error {
+	r := g.recorder
+	live := g.State == StatePlaying && !g.isPaused
+	defer func() {
+		if p := recover(); p != nil {
+			if g.recorder == r {
+				r.commit()
+				g.finishRecording()
+			}
+			panic(p)
+		}
+	}()
+	err := g.update()
+	if g.recorder != r {
+		return err
+	}
+	if live && !g.isPaused {
+		r.commit()
+	}
+	if g.RunState == nil || !g.RunState.Active {
+		g.finishRecording()
+	}
+	return err
+}
+
+// StartReplay loads a replay file and plays it back from the run's starting
+// state. Nothing is written to disk for the rest of the session; once the
+// recorded input runs out the keyboard and mouse take over.
+func (g *Game) StartReplay(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var rec Replay
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return err
+	}
+	if rec.Version != replayVersion {
+		return fmt.Errorf("replay version %d is not supported", rec.Version)
+	}
+
+	g.finishRecording()
+	g.replaying = true
+	if err := simrand.Restore(rec.SimRNG); err != nil {
+		return err
+	}
+	if len(rec.Resume) > 0 {
+		var rs RunSave
+		if err := json.Unmarshal(rec.Resume, &rs); err != nil {
+			return err
+		}
+		if rs.RunState == nil || rs.Level == nil {
+			return fmt.Errorf("replay resume state is incomplete")
+		}
+		g.restoreRun(&rs)
+	} else {
+		var ps entities.PlayerSave
+		if err := json.Unmarshal(rec.Player, &ps); err != nil {
+			return err
+		}
+		meta := &MetaSave{}
+		if err := json.Unmarshal(rec.Meta, meta); err != nil {
+			return err
+		}
+		if meta.NPCMeta == nil {
+			meta.NPCMeta = make(map[string]*NPCMetaState)
+		}
+		g.Meta = meta
+		g.setPlayer(entities.LoadPlayer(ps))
+		g.RunState = NewRunState(rec.TotalFloors, rec.Seed)
+		g.seedNPCPhaseFlags()
+		g.IsInHub = false
+		g.FullBright = false
+		g.startFloor(1)
+	}
+
+	in := NewScriptedInput()
+	in.Queue(expandReplay(&rec)...)
+	g.replayInput = in
+	g.replayEvents = rec.Events
+	g.replayFrame = 0
+	g.input = in
+	g.editor.Active = false
+	g.State = StatePlaying
+	g.ShowHint(fmt.Sprintf("Replaying seed %s (%d ticks)", FormatSeed(rec.Seed), rec.Ticks))
+	return nil
+}
+
+// replayTick runs one Update of a replay and returns control to the player
+// once the recorded frames are used up.
+func (g *Game) replayTick() error {
+	g.applyReplayEvents(g.replayFrame)
+	g.replayFrame++
+	err := g.update()
+	if g.replayInput != nil && g.replayInput.Pending() == 0 {
+		g.applyReplayEvents(g.replayFrame)
+		g.replayInput = nil
+		g.input = ebitenInput{ctrl: g.Controls}
+		g.ShowHint("Replay finished")
+	}
+	return err
+}
+
+// applyReplayEvents makes the trades recorded before tick t.
+func (g *Game) applyReplayEvents(t int) {
+	for len(g.replayEvents) > 0 && g.replayEvents[0].Tick <= t {
+		e := g.replayEvents[0]
+		g.replayEvents = g.replayEvents[1:]
+		switch e.Kind {
+		case replayBuy:
+			g.restockShop()
+			g.buyItem(e.Index)
+		case replaySell:
+			g.sellItem(e.X, e.Y)
+		case replayUnlock:
+			g.buyUnlock(e.ID)
+		}
+	}
+}
+
+// expandReplay turns the stored frames back into one InputFrame per tick.
+func expandReplay(rec *Replay) []InputFrame {
+	frames := make([]InputFrame, rec.Ticks)
+	for _, f := range rec.Frames {
+		for i := 0; i <= f.Repeat; i++ {
+			t := f.Tick + i
+			if t < 0 || t >= len(frames) {
+				break
+			}
+			frames[t] = f.InputFrame
+			if i > 0 {
+				frames[t].Aim, frames[t].Cursor = nil, nil
+			}
+		}
+	}
+	return frames
+}
+
+// saveMeta persists cross-run progress unless a replay is playing.
+func (g *Game) saveMeta() {
+	if g.replaying {
+		return
+	}
+	SaveMeta(g.Meta)
+}
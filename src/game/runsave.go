@@ -0,0 +1,563 @@
+package game
+
+import (
+	"dungeoneer/entities"
+	"dungeoneer/fov"
+	"dungeoneer/items"
+	"dungeoneer/leveleditor"
+	"dungeoneer/levels"
+	"dungeoneer/simrand"
+	"dungeoneer/spells"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const runSavePath = "run.json"
+
+// MonsterSave is the serializable state of a single live monster.
+type MonsterSave struct {
+	Name             string                   `json:"name"`
+	SpriteID         string                   `json:"sprite_id"`
+	Role             string                   `json:"role,omitempty"`
+	TileX            int                      `json:"tile_x"`
+	TileY            int                      `json:"tile_y"`
+	HP               int                      `json:"hp"`
+	MaxHP            int                      `json:"max_hp"`
+	Damage           int                      `json:"damage"`
+	AttackRate       int                      `json:"attack_rate"`
+	MovementDuration int                      `json:"movement_duration"`
+	HitRadius        float64                  `json:"hit_radius"`
+	Level            int                      `json:"level"`
+	Behavior         string                   `json:"behavior"`
+	BehaviorState    json.RawMessage          `json:"behavior_state,omitempty"`
+	SwarmGroup       int                      `json:"swarm_group"` // -1 when not part of a swarm
+	Effects          []*entities.StatusEffect `json:"effects,omitempty"`
+	OnHitEffect      *entities.StatusEffect   `json:"on_hit_effect,omitempty"`
+	Perception       entities.Perception      `json:"perception"`
+	Doors            string                   `json:"doors,omitempty"`
+	Affixes          []entities.Affix         `json:"affixes,omitempty"`
+}
+
+// BossSave records the floor boss so it can be rebuilt with its phase state.
+type BossSave struct {
+	NPCID         string `json:"npc_id,omitempty"` // "varn" for the ascended NPC boss, empty for the guardian
+	TileX         int    `json:"tile_x"`
+	TileY         int    `json:"tile_y"`
+	HP            int    `json:"hp"`
+	IsDead        bool   `json:"is_dead"`
+	CurrentPhase  int    `json:"current_phase"`
+	IsActive      bool   `json:"is_active"`
+	PreFightShown bool   `json:"pre_fight_shown"`
+	RoomIndex     int    `json:"room_index"` // index into Level.Rooms, -1 if unknown
+}
+
+// ChestSave is the serializable state of a chest.
+type ChestSave struct {
+	TileX   int    `json:"tile_x"`
+	TileY   int    `json:"tile_y"`
+	Variant string `json:"variant"`
+	Opened  bool   `json:"opened"`
+}
+
+// NPCSave is the serializable state of an NPC placed on the floor.
+type NPCSave struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Title      string `json:"title,omitempty"`
+	SpriteID   string `json:"sprite_id"`
+	PortraitID string `json:"portrait_id,omitempty"`
+	IsMajor    bool   `json:"is_major"`
+	Phase      int    `json:"phase"`
+	DialogueID string `json:"dialogue_id,omitempty"`
+	TileX      int    `json:"tile_x"`
+	TileY      int    `json:"tile_y"`
+}
+
+// ItemDropSave is an item lying on the floor.
+type ItemDropSave struct {
+	TileX int            `json:"tile_x"`
+	TileY int            `json:"tile_y"`
+	Item  items.ItemSave `json:"item"`
+}
+
+// TrapSave is the state of a trap; its kind and wire come with it so traps
+// placed in the editor survive too.
+type TrapSave struct {
+	TileX    int     `json:"tile_x"`
+	TileY    int     `json:"tile_y"`
+	Kind     string  `json:"kind"`
+	Wire     int     `json:"wire,omitempty"`
+	Timer    float64 `json:"timer,omitempty"`
+	Revealed bool    `json:"revealed,omitempty"`
+	Spent    bool    `json:"spent,omitempty"`
+}
+
+// ExitSave records the floor exit portal.
+type ExitSave struct {
+	TileX    int    `json:"tile_x"`
+	TileY    int    `json:"tile_y"`
+	SpriteID string `json:"sprite_id"`
+}
+
+// FloorSave holds the per-floor context that isn't derivable from RunState.
+type FloorSave struct {
+	GenParams      levels.GenParams  `json:"gen_params"`
+	AbilityDropped bool              `json:"ability_dropped"`
+	Sublayers      []Sublayer        `json:"sublayers,omitempty"`
+	RNGState       map[string][]byte `json:"rng_state,omitempty"` // stream positions so post-resume rolls match
+}
+
+// RunSave is a full mid-run snapshot: run progress, the current floor layout
+// and every live entity on it.
+type RunSave struct {
+	RunState  *RunState              `json:"run_state"`
+	Floor     FloorSave              `json:"floor"`
+	Level     *leveleditor.LevelData `json:"level"`
+	SeenTiles [][]bool               `json:"seen_tiles"`
+	Player    entities.PlayerSave    `json:"player"`
+	Monsters  []MonsterSave          `json:"monsters"`
+	Boss      *BossSave              `json:"boss,omitempty"`
+	Chests    []ChestSave            `json:"chests"`
+	NPCs      []NPCSave              `json:"npcs"`
+	ItemDrops []ItemDropSave         `json:"item_drops"`
+	Traps     []TrapSave             `json:"traps,omitempty"`
+	Exit      *ExitSave              `json:"exit,omitempty"`
+	SimRNG    []byte                 `json:"sim_rng,omitempty"`
+
+	// Multi-layer floors only: every layer and stairwell (Level is the
+	// active layer) and the entities on the layers the player is not on.
+	World   *leveleditor.LayeredLevelData `json:"world,omitempty"`
+	Stashed []LayerSave                   `json:"stashed,omitempty"`
+}
+
+// LayerSave holds the entities of a floor layer the player is not on.
+type LayerSave struct {
+	Index     int            `json:"index"`
+	SeenTiles [][]bool       `json:"seen_tiles"`
+	Monsters  []MonsterSave  `json:"monsters"`
+	Chests    []ChestSave    `json:"chests"`
+	NPCs      []NPCSave      `json:"npcs"`
+	ItemDrops []ItemDropSave `json:"item_drops"`
+	Traps     []TrapSave     `json:"traps,omitempty"`
+	Exit      *ExitSave      `json:"exit,omitempty"`
+}
+
+// HasRunSave reports whether a mid-run save exists on disk.
+func HasRunSave() bool {
+	_, err := os.Stat(runSavePath)
+	return err == nil
+}
+
+// DeleteRunSave removes the mid-run save, e.g. once the run has ended.
+func DeleteRunSave() {
+	_ = os.Remove(runSavePath)
+}
+
+// SaveRun writes a snapshot of the active run to disk. It is a no-op outside
+// of an active run.
+func (g *Game) SaveRun() error {
+	if g.replaying || g.RunState == nil || !g.RunState.Active || g.currentLevel == nil || g.FloorCtx == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(g.snapshotRun(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(runSavePath, data, 0644)
+}
+
+// ContinueRun loads the mid-run save and resumes play on the saved floor.
+func (g *Game) ContinueRun() error {
+	raw, err := os.ReadFile(runSavePath)
+	if err != nil {
+		return err
+	}
+	var rs RunSave
+	if err := json.Unmarshal(raw, &rs); err != nil {
+		return err
+	}
+	if rs.RunState == nil || rs.Level == nil {
+		return fmt.Errorf("run save is incomplete")
+	}
+	if len(rs.SimRNG) > 0 {
+		_ = simrand.Restore(rs.SimRNG)
+	}
+	g.restoreRun(&rs)
+	g.startRecording(&rs)
+	return nil
+}
+
+// snapshotRun captures the current run into a RunSave.
+func (g *Game) snapshotRun() *RunSave {
+	rs := &RunSave{
+		RunState: g.RunState,
+		Floor: FloorSave{
+			GenParams:      g.FloorCtx.GenParams,
+			AbilityDropped: g.FloorCtx.AbilityDropped,
+			RNGState:       g.FloorCtx.RNG.State(),
+			Sublayers:      g.FloorCtx.Sublayers,
+		},
+		Level:     leveleditor.ConvertToLevelData(g.currentLevel),
+		SeenTiles: g.SeenTiles,
+		Player:    g.player.ToSaveData(),
+		SimRNG:    simrand.State(),
+	}
+
+	live := g.snapshotLayer(g.stashLayer())
+	rs.Monsters, rs.Chests, rs.NPCs, rs.ItemDrops, rs.Exit = live.Monsters, live.Chests, live.NPCs, live.ItemDrops, live.Exit
+	rs.Traps = live.Traps
+
+	if b := g.CurrentBoss; b != nil {
+		bs := &BossSave{
+			NPCID:         b.NPCID,
+			TileX:         b.Monster.TileX,
+			TileY:         b.Monster.TileY,
+			HP:            b.Monster.HP,
+			IsDead:        b.Monster.IsDead,
+			CurrentPhase:  b.CurrentPhase,
+			IsActive:      b.IsActive,
+			PreFightShown: b.PreFightShown,
+			RoomIndex:     -1,
+		}
+		if g.BossRoom != nil {
+			bs.RoomIndex = g.BossRoom.Index
+		}
+		rs.Boss = bs
+	}
+
+	if g.currentWorld != nil && len(g.currentWorld.Layers) > 1 {
+		rs.World = leveleditor.ConvertToLayeredLevelData(g.currentWorld)
+		for i, l := range g.currentWorld.Layers {
+			if st, ok := g.layerStates[l]; ok {
+				ls := g.snapshotLayer(st)
+				ls.Index = i
+				rs.Stashed = append(rs.Stashed, ls)
+			}
+		}
+	}
+	return rs
+}
+
+// snapshotLayer serialises the entities of one floor layer. The boss is saved
+// separately in BossSave.
+func (g *Game) snapshotLayer(st *layerState) LayerSave {
+	ls := LayerSave{SeenTiles: st.SeenTiles}
+	swarmGroups := map[*entities.Monster]int{}
+	for _, m := range st.Monsters {
+		if m == nil || m.IsDead {
+			continue
+		}
+		if g.CurrentBoss != nil && m == g.CurrentBoss.Monster {
+			continue
+		}
+		ms := MonsterSave{
+			Name:             m.Name,
+			SpriteID:         leveleditor.ReverseSpriteRegistry[m.Sprite],
+			Role:             m.Role,
+			TileX:            m.TileX,
+			TileY:            m.TileY,
+			HP:               m.HP,
+			MaxHP:            m.MaxHP,
+			Damage:           m.Damage,
+			AttackRate:       m.AttackRate,
+			MovementDuration: m.MovementDuration,
+			HitRadius:        m.HitRadius,
+			Level:            m.Level,
+			Behavior:         behaviorKind(m.Behavior),
+			SwarmGroup:       -1,
+			Effects:          m.Effects.Effects,
+			OnHitEffect:      m.OnHitEffect,
+			Perception:       m.Perception,
+			Doors:            m.Doors.String(),
+			Affixes:          m.Affixes,
+		}
+		if state, err := json.Marshal(m.Behavior); err == nil {
+			ms.BehaviorState = state
+		}
+		if len(m.Siblings) > 0 {
+			leader := m.Siblings[0]
+			id, ok := swarmGroups[leader]
+			if !ok {
+				id = len(swarmGroups)
+				swarmGroups[leader] = id
+			}
+			ms.SwarmGroup = id
+		}
+		ls.Monsters = append(ls.Monsters, ms)
+	}
+
+	for _, c := range st.Chests {
+		ls.Chests = append(ls.Chests, ChestSave{TileX: c.TileX, TileY: c.TileY, Variant: c.Variant, Opened: c.Opened})
+	}
+	for _, n := range st.NPCs {
+		ls.NPCs = append(ls.NPCs, NPCSave{
+			ID:         n.ID,
+			Name:       n.Name,
+			Title:      n.Title,
+			SpriteID:   leveleditor.ReverseSpriteRegistry[n.Sprite],
+			PortraitID: n.PortraitID,
+			IsMajor:    n.IsMajor,
+			Phase:      n.Phase,
+			DialogueID: n.DialogueID,
+			TileX:      n.TileX,
+			TileY:      n.TileY,
+		})
+	}
+	for _, d := range st.ItemDrops {
+		ls.ItemDrops = append(ls.ItemDrops, ItemDropSave{TileX: d.TileX, TileY: d.TileY, Item: d.Item.ToSave()})
+	}
+	for _, t := range st.Traps {
+		ls.Traps = append(ls.Traps, TrapSave{TileX: t.TileX, TileY: t.TileY, Kind: t.Kind, Wire: t.Wire, Timer: t.Timer, Revealed: t.Revealed, Spent: t.Spent})
+	}
+	if st.Exit != nil {
+		ls.Exit = &ExitSave{TileX: st.Exit.TileX, TileY: st.Exit.TileY, SpriteID: st.Exit.SpriteID}
+	}
+	return ls
+}
+
+// restoreRun rebuilds the run, floor and entities from a RunSave.
+func (g *Game) restoreRun(rs *RunSave) {
+	if rs.RunState.QuestFlags == nil {
+		rs.RunState.QuestFlags = make(map[string]int)
+	}
+	g.RunState = rs.RunState
+	ctx := g.RunState.BuildFloorContext(g.RunState.CurrentFloor)
+	ctx.GenParams = rs.Floor.GenParams
+	ctx.AbilityDropped = rs.Floor.AbilityDropped
+	ctx.Sublayers = rs.Floor.Sublayers
+	ctx.RNG.Restore(rs.Floor.RNGState)
+	g.FloorCtx = &ctx
+
+	var newWorld *levels.LayeredLevel
+	if rs.World != nil && len(rs.World.Layers) > 0 {
+		newWorld = leveleditor.ConvertToLayeredLevel(rs.World)
+	} else {
+		newWorld = levels.NewLayeredLevel(leveleditor.ConvertToLevel(rs.Level))
+	}
+	lvl := newWorld.ActiveLayer()
+	g.currentWorld = newWorld
+	g.currentLevel = lvl
+	g.editor = leveleditor.NewLayeredEditor(newWorld, g.w, g.h)
+	g.editor.OnLayerChange = g.editorLayerChanged
+	g.editor.OnStairPlaced = g.stairPlaced
+	g.editor.Active = false
+
+	g.setPlayer(entities.LoadPlayer(rs.Player))
+	g.player.CollisionBox.X = float64(g.player.TileX)
+	g.player.CollisionBox.Y = float64(g.player.TileY)
+
+	// Live entities.
+	g.ActiveSpells = []spells.Spell{}
+	g.CurrentBoss = nil
+	g.BossBar = nil
+	g.BossRoom = nil
+	g.unstashLayer(g.restoreLayer(LayerSave{
+		SeenTiles: copySeenTiles(rs.SeenTiles, lvl),
+		Monsters:  rs.Monsters,
+		Chests:    rs.Chests,
+		NPCs:      rs.NPCs,
+		ItemDrops: rs.ItemDrops,
+		Traps:     rs.Traps,
+		Exit:      rs.Exit,
+	}))
+	g.layerStates = map[*levels.Level]*layerState{}
+	for _, ls := range rs.Stashed {
+		if ls.Index >= 0 && ls.Index < len(newWorld.Layers) && ls.Index != newWorld.ActiveIndex {
+			st := g.restoreLayer(ls)
+			st.SeenTiles = copySeenTiles(ls.SeenTiles, newWorld.Layers[ls.Index])
+			g.layerStates[newWorld.Layers[ls.Index]] = st
+		}
+	}
+
+	if rs.Boss != nil {
+		g.restoreBoss(rs.Boss)
+	}
+
+	// Reset camera and FOV
+	g.IsInHub = false
+	g.FullBright = false
+	snapIsoX, snapIsoY := g.cartesianToIso(float64(g.player.TileX), float64(g.player.TileY))
+	g.camX = snapIsoX
+	g.camY = -snapIsoY
+	g.cachedRays = nil
+	g.RaycastWalls = fov.LevelToWalls(g.currentLevel)
+	fov.InvalidateCache()
+	g.State = StatePlaying
+}
+
+// restoreLayer rebuilds the entities of one floor layer from a LayerSave.
+func (g *Game) restoreLayer(ls LayerSave) *layerState {
+	st := &layerState{
+		Monsters:  []*entities.Monster{},
+		ItemDrops: []*entities.ItemDrop{},
+		Chests:    []*entities.Chest{},
+		NPCs:      []*entities.NPC{},
+		Traps:     []*entities.Trap{},
+		SeenTiles: ls.SeenTiles,
+	}
+	swarms := map[int][]*entities.Monster{}
+	for _, ms := range ls.Monsters {
+		m := g.restoreMonster(ms)
+		if m == nil {
+			continue
+		}
+		if ms.SwarmGroup >= 0 {
+			swarms[ms.SwarmGroup] = append(swarms[ms.SwarmGroup], m)
+		}
+		st.Monsters = append(st.Monsters, m)
+	}
+	for _, group := range swarms {
+		for _, m := range group {
+			m.Siblings = group
+		}
+	}
+
+	for _, cs := range ls.Chests {
+		st.Chests = append(st.Chests, &entities.Chest{
+			TileX:   cs.TileX,
+			TileY:   cs.TileY,
+			Variant: cs.Variant,
+			Opened:  cs.Opened,
+			Sprite:  g.spriteSheet.GrandChest,
+		})
+	}
+	for _, ns := range ls.NPCs {
+		npc := g.createNPCFromTemplate(NPCTemplate{
+			ID:         ns.ID,
+			Name:       ns.Name,
+			Title:      ns.Title,
+			SpriteID:   ns.SpriteID,
+			PortraitID: ns.PortraitID,
+			IsMajor:    ns.IsMajor,
+			DialogueID: ns.DialogueID,
+		}, ns.TileX, ns.TileY)
+		npc.Phase = ns.Phase
+		st.NPCs = append(st.NPCs, npc)
+	}
+	for _, ds := range ls.ItemDrops {
+		if _, ok := items.Registry[ds.Item.ID]; !ok {
+			if _, key := items.KeyLock(ds.Item.ID); !key {
+				continue
+			}
+		}
+		st.ItemDrops = append(st.ItemDrops, &entities.ItemDrop{TileX: ds.TileX, TileY: ds.TileY, Item: *items.FromSave(ds.Item)})
+	}
+	for _, ts := range ls.Traps {
+		st.Traps = append(st.Traps, &entities.Trap{
+			TileX:    ts.TileX,
+			TileY:    ts.TileY,
+			Kind:     ts.Kind,
+			Wire:     ts.Wire,
+			Timer:    ts.Timer,
+			Revealed: ts.Revealed,
+			Spent:    ts.Spent,
+		})
+	}
+	if ls.Exit != nil {
+		sprite := g.spriteSheet.Portal
+		if meta, ok := leveleditor.SpriteRegistry[ls.Exit.SpriteID]; ok {
+			sprite = meta.Image
+		}
+		st.Exit = entities.NewExitEntity(ls.Exit.TileX, ls.Exit.TileY, sprite, ls.Exit.SpriteID)
+	}
+	return st
+}
+
+// copySeenTiles copies saved explored tiles into a fresh grid sized for l,
+// ignoring rows that do not match.
+func copySeenTiles(saved [][]bool, l *levels.Level) [][]bool {
+	seen := make([][]bool, l.H)
+	for y := range seen {
+		seen[y] = make([]bool, l.W)
+		if y < len(saved) && len(saved[y]) == l.W {
+			copy(seen[y], saved[y])
+		}
+	}
+	return seen
+}
+
+// restoreMonster rebuilds a monster and its behavior state from a MonsterSave.
+func (g *Game) restoreMonster(ms MonsterSave) *entities.Monster {
+	meta, ok := leveleditor.SpriteRegistry[ms.SpriteID]
+	if !ok {
+		return nil
+	}
+	behavior := makeBehavior(ms.Behavior)
+	if len(ms.BehaviorState) > 0 {
+		_ = json.Unmarshal(ms.BehaviorState, behavior)
+	}
+	m := &entities.Monster{
+		Name:             ms.Name,
+		TileX:            ms.TileX,
+		TileY:            ms.TileY,
+		InterpX:          float64(ms.TileX),
+		InterpY:          float64(ms.TileY),
+		Sprite:           meta.Image,
+		MovementDuration: ms.MovementDuration,
+		LeftFacing:       true,
+		HP:               ms.HP,
+		MaxHP:            ms.MaxHP,
+		Damage:           ms.Damage,
+		HitRadius:        ms.HitRadius,
+		AttackRate:       ms.AttackRate,
+		Behavior:         behavior,
+		Level:            ms.Level,
+		Role:             ms.Role,
+		OnHitEffect:      ms.OnHitEffect,
+		Perception:       ms.Perception,
+		Affixes:          ms.Affixes,
+	}
+	m.Doors, _ = entities.DoorPolicyByName(ms.Doors)
+	m.Effects.Effects = ms.Effects
+	return m
+}
+
+// restoreBoss respawns the floor boss and reapplies its saved fight state.
+func (g *Game) restoreBoss(bs *BossSave) {
+	if bs.RoomIndex >= 0 && bs.RoomIndex < len(g.currentLevel.Rooms) {
+		g.BossRoom = &g.currentLevel.Rooms[bs.RoomIndex]
+	}
+	if bs.NPCID == "varn" {
+		g.spawnVarnBoss(bs.TileX, bs.TileY)
+	} else {
+		g.spawnBoss(bs.TileX, bs.TileY)
+	}
+	b := g.CurrentBoss
+	if b == nil {
+		return
+	}
+	b.Monster.HP = bs.HP
+	b.Monster.IsDead = bs.IsDead
+	b.PreFightShown = bs.PreFightShown
+	if bs.CurrentPhase > 0 {
+		b.CurrentPhase = bs.CurrentPhase
+		if b.OnPhaseTransition != nil {
+			b.OnPhaseTransition(bs.CurrentPhase)
+		}
+	}
+	if bs.IsActive && !bs.IsDead {
+		g.activateBoss()
+	}
+	if g.BossBar != nil {
+		g.BossBar.CurrentHP = b.Monster.HP
+	}
+}
+
+// behaviorKind returns the makeBehavior key for a monster behavior.
+func behaviorKind(b entities.MonsterBehavior) string {
+	switch b.(type) {
+	case *entities.AmbushBehavior:
+		return "ambush"
+	case *entities.PatrolBehavior:
+		return "patrol"
+	case *entities.RangedBehavior:
+		return "ranged"
+	case *entities.SwarmBehavior:
+		return "swarm"
+	case *entities.CasterBehavior:
+		return "caster"
+	default:
+		return "roaming"
+	}
+}
@@ -0,0 +1,130 @@
+package game
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// RNG stream names. Each system draws from its own stream so that, for a
+// given run seed, adding a roll to one system never shifts the results of
+// another (e.g. an extra loot roll doesn't change the next floor's layout).
+const (
+	streamBiomes     = "biomes"
+	streamLayout     = "layout"
+	streamEncounters = "encounters"
+	streamLoot       = "loot"
+	streamNPCs       = "npcs"
+	streamChests     = "chests"
+	streamPrefabs    = "prefabs"
+	streamLayers     = "layers"
+	streamShop       = "shop"
+	streamSim        = "sim" // seeds simrand for in-tick AI and spell rolls
+)
+
+// FloorRNG holds the per-system random streams for a single floor. The
+// streams are derived from the run seed and floor number, so two runs with
+// the same seed see identical encounters, loot and placement.
+type FloorRNG struct {
+	Encounters *rand.Rand
+	Loot       *rand.Rand
+	NPCs       *rand.Rand
+	Chests     *rand.Rand
+
+	sources map[string]*rand.PCG
+}
+
+// deriveSource returns a PCG source for the given run seed, floor and
+// system. Floor 0 is reserved for run-wide streams such as biome order.
+func deriveSource(seed int64, floor int, system string) *rand.PCG {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(seed))
+	h.Write(buf[:])
+	binary.LittleEndian.PutUint64(buf[:], uint64(floor))
+	h.Write(buf[:])
+	h.Write([]byte(system))
+	s1 := h.Sum64()
+	h.Write([]byte{0xff})
+	return rand.NewPCG(s1, h.Sum64())
+}
+
+// Stream returns a fresh random stream for the given floor and system.
+func (rs *RunState) Stream(floor int, system string) *rand.Rand {
+	return rand.New(deriveSource(rs.Seed, floor, system))
+}
+
+// newFloorRNG derives every per-floor gameplay stream for floorNum.
+func (rs *RunState) newFloorRNG(floorNum int) *FloorRNG {
+	f := &FloorRNG{sources: map[string]*rand.PCG{}}
+	mk := func(system string) *rand.Rand {
+		src := deriveSource(rs.Seed, floorNum, system)
+		f.sources[system] = src
+		return rand.New(src)
+	}
+	f.Encounters = mk(streamEncounters)
+	f.Loot = mk(streamLoot)
+	f.NPCs = mk(streamNPCs)
+	f.Chests = mk(streamChests)
+	return f
+}
+
+// State returns the serialised position of every stream, keyed by system
+// name, so a mid-floor save resumes with the same upcoming rolls.
+func (f *FloorRNG) State() map[string][]byte {
+	if f == nil {
+		return nil
+	}
+	out := make(map[string][]byte, len(f.sources))
+	for name, src := range f.sources {
+		if b, err := src.MarshalBinary(); err == nil {
+			out[name] = b
+		}
+	}
+	return out
+}
+
+// Restore rewinds the streams to a previously saved State. Unknown or
+// malformed entries are ignored and keep the floor-start position.
+func (f *FloorRNG) Restore(state map[string][]byte) {
+	if f == nil {
+		return
+	}
+	for name, b := range state {
+		if src, ok := f.sources[name]; ok {
+			_ = src.UnmarshalBinary(b)
+		}
+	}
+}
+
+// NewRunSeed returns a random seed for a run started without one.
+func NewRunSeed() int64 {
+	for {
+		if s := rand.Int64(); s != 0 {
+			return s
+		}
+	}
+}
+
+// ParseSeed converts player-entered text into a run seed. Numbers are used
+// as-is so shared seeds round-trip exactly; any other text is hashed, which
+// lets players share memorable words. Blank input picks a random seed.
+func ParseSeed(text string) int64 {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return NewRunSeed()
+	}
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n
+	}
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(text)))
+	return int64(h.Sum64())
+}
+
+// FormatSeed renders a seed in the form accepted by ParseSeed.
+func FormatSeed(seed int64) string {
+	return strconv.FormatInt(seed, 10)
+}
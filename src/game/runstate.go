@@ -3,6 +3,7 @@ package game
 import (
 	"dungeoneer/levels"
 	"dungeoneer/sprites"
+	"dungeoneer/tiles"
 	"math/rand/v2"
 	"time"
 )
@@ -18,10 +19,9 @@ const (
 	BiomeCatacomb Biome = "catacomb"
 )
 
-// availableBiomes lists the biomes we can currently generate with existing
-// tilesets. This maps to sprites.WallFlavors entries that have both wall and
-// floor sprites.
-var availableBiomes = []Biome{BiomeCrypt, BiomeMoss, BiomeGallery, BiomeBrick}
+// availableBiomes lists the biomes runs draw from, in a fixed order. It is
+// filled by LoadBiomes with every biome that passed validation.
+var availableBiomes = []Biome{BiomeCrypt}
 
 // FloorContext holds the generation parameters and metadata for a single floor.
 type FloorContext struct {
@@ -29,23 +29,27 @@ type FloorContext struct {
 	TotalFloors    int
 	Biome          Biome
 	Difficulty     float64 // 0.0–1.0
+	Layout         string  // which generator builds the floor; see GenerateLevel
 	GenParams      levels.GenParams
+	Sublayers      []Sublayer // extra layers linked to the main one by stairs
 	BiomeConfig    *BiomeConfig
-	AbilityDropped bool // true once an ability item has been force-dropped this floor
+	AbilityDropped bool      // true once an ability item has been force-dropped this floor
+	RNG            *FloorRNG // per-system random streams derived from the run seed
 }
 
 // RunState tracks all state for a single dungeon run.
 type RunState struct {
-	Active        bool
-	CurrentFloor  int
-	TotalFloors   int
-	Biomes        []Biome
-	KillCount     int
-	FloorsCleared int
-	RemnantEarned int
-	GoldEarned    int            // total gold collected this run
-	StartTime     time.Time
-	QuestFlags    map[string]int // per-run NPC/quest state; resets each run
+	Active        bool           `json:"active"`
+	Seed          int64          `json:"seed"` // derives every per-floor RNG stream; share to replay a run
+	CurrentFloor  int            `json:"current_floor"`
+	TotalFloors   int            `json:"total_floors"`
+	Biomes        []Biome        `json:"biomes"`
+	KillCount     int            `json:"kill_count"`
+	FloorsCleared int            `json:"floors_cleared"`
+	RemnantEarned int            `json:"remnant_earned"`
+	GoldEarned    int            `json:"gold_earned"` // total gold collected this run
+	StartTime     time.Time      `json:"start_time"`
+	QuestFlags    map[string]int `json:"quest_flags"` // per-run NPC/quest state; resets each run
 }
 
 // DefaultRunFloors is the starting number of floors for a new run.
@@ -57,26 +61,27 @@ type RunState struct {
 //   Floor  7     : Boss floor
 const DefaultRunFloors = 7
 
-// NewRunState initialises a new run with the given floor count.
-func NewRunState(totalFloors int) *RunState {
-	biomes := assignBiomes(totalFloors)
-	return &RunState{
+// NewRunState initialises a new run with the given floor count and seed.
+func NewRunState(totalFloors int, seed int64) *RunState {
+	rs := &RunState{
 		Active:       true,
+		Seed:         seed,
 		CurrentFloor: 1,
 		TotalFloors:  totalFloors,
-		Biomes:       biomes,
 		StartTime:    time.Now(),
 		QuestFlags:   make(map[string]int),
 	}
+	rs.Biomes = assignBiomes(totalFloors, rs.Stream(0, streamBiomes))
+	return rs
 }
 
 // assignBiomes creates a biome sequence with no immediate repeats.
-func assignBiomes(count int) []Biome {
+func assignBiomes(count int, rng *rand.Rand) []Biome {
 	biomes := make([]Biome, count)
 	prev := Biome("")
 	for i := range biomes {
 		for {
-			b := availableBiomes[rand.IntN(len(availableBiomes))]
+			b := availableBiomes[rng.IntN(len(availableBiomes))]
 			if b != prev || len(availableBiomes) == 1 {
 				biomes[i] = b
 				prev = b
@@ -116,29 +121,38 @@ func (rs *RunState) BuildFloorContext(floorNum int) FloorContext {
 		TotalFloors: rs.TotalFloors,
 		Biome:       biome,
 		Difficulty:  difficulty,
+		Layout:      LayoutRooms,
 		BiomeConfig: BiomeConfigs[biome],
+		RNG:         rs.newFloorRNG(floorNum),
 		GenParams: levels.GenParams{
-			Seed:           rand.Int64(),
-			Width:          64,
-			Height:         64,
-			RoomCountMin:   roomMin,
-			RoomCountMax:   roomMax,
-			RoomWMin:       6,
-			RoomWMax:       12,
-			RoomHMin:       6,
-			RoomHMax:       12,
-			CorridorWidth:  1,
-			DashLaneMinLen: 7,
-			GrappleRange:   10,
-			Extras:         1 + int(difficulty*2),
-			CoverageTarget: 0.40 + difficulty*0.10,
-			FillerRoomsMax: 4 + int(difficulty*2),
-			DoorLockChance: lockChance,
-			WallFlavor:     flavor,
-			FloorFlavor:    flavor,
+			Seed:             rs.Stream(floorNum, streamLayout).Int64(),
+			Width:            64,
+			Height:           64,
+			RoomCountMin:     roomMin,
+			RoomCountMax:     roomMax,
+			RoomWMin:         6,
+			RoomWMax:         12,
+			RoomHMin:         6,
+			RoomHMax:         12,
+			CorridorWidth:    1,
+			DashLaneMinLen:   7,
+			GrappleRange:     10,
+			Extras:           1 + int(difficulty*2),
+			CoverageTarget:   0.40 + difficulty*0.10,
+			FillerRoomsMax:   4 + int(difficulty*2),
+			DoorLockChance:   lockChance,
+			WallFlavor:       flavor,
+			FloorFlavor:      flavor,
+			Prefabs:          choosePrefabs(floorNum, biome, rs.Stream(floorNum, streamPrefabs)),
+			Traps:            trapBudget(difficulty),
+			SecretRoomChance: 0.3 + difficulty*0.3,
 		},
 	}
 
+	if ctx.BiomeConfig != nil && ctx.BiomeConfig.Layout != "" {
+		ctx.Layout = ctx.BiomeConfig.Layout
+	}
+
 	// Apply biome-specific generation overrides if defined.
 	if ctx.BiomeConfig != nil && ctx.BiomeConfig.GenOverrides != nil {
 		o := ctx.BiomeConfig.GenOverrides
@@ -165,9 +179,36 @@ func (rs *RunState) BuildFloorContext(floorNum int) FloorContext {
 		}
 	}
 
+	if ctx.BiomeConfig != nil {
+		for _, h := range ctx.BiomeConfig.Hazards {
+			if hz, ok := tiles.HazardByName(h.Type); ok {
+				ctx.GenParams.Hazards = append(ctx.GenParams.Hazards, levels.HazardSpec{Hazard: hz, Pools: h.Pools, Size: h.Size})
+			}
+		}
+	}
+
+	// The first floor stays simple and the boss floor keeps a single arena.
+	if floorNum > 1 && floorNum < rs.TotalFloors {
+		ctx.Sublayers = rollSublayers(&ctx, rs.Stream(floorNum, streamLayers))
+	}
+
 	return ctx
 }
 
+// Floor layouts a biome can ask for.
+const (
+	LayoutRooms = "rooms" // rooms and corridors (Generate64x64)
+	LayoutCave  = "cave"  // cellular-automata caverns (GenerateCave)
+)
+
+// GenerateLevel builds the floor's level with the generator its layout names.
+func (ctx *FloorContext) GenerateLevel() *levels.Level {
+	if ctx.Layout == LayoutCave {
+		return levels.GenerateCave(ctx.GenParams)
+	}
+	return levels.Generate64x64(ctx.GenParams)
+}
+
 // IsLastFloor returns true if the current floor is the final floor.
 func (rs *RunState) IsLastFloor() bool {
 	return rs.CurrentFloor >= rs.TotalFloors
@@ -0,0 +1,50 @@
+package game
+
+import (
+	"math"
+
+	"dungeoneer/fov"
+	"dungeoneer/pathing"
+	"dungeoneer/spells"
+)
+
+// hitWall strikes the wall at (x, y). When a cracked wall gives way the
+// floor has a new opening, so the raycast walls and FOV cache are rebuilt.
+func (g *Game) hitWall(x, y int) {
+	if !g.currentLevel.HitWall(x, y) {
+		return
+	}
+	g.RaycastWalls = fov.LevelToWalls(g.currentLevel)
+	fov.InvalidateCache()
+	pathing.InvalidateCache()
+	g.cachedRays = nil
+	g.ShowHint("A hidden passage!")
+}
+
+// blastWalls hits every cracked wall within radius of (cx, cy) that the
+// blast can see.
+func (g *Game) blastWalls(cx, cy, radius int) {
+	for y := cy - radius; y <= cy+radius; y++ {
+		for x := cx - radius; x <= cx+radius; x++ {
+			if t := g.currentLevel.Tile(x, y); t == nil || t.WallHP == 0 {
+				continue
+			}
+			if g.hasLineOfSight(cx, cy, x, y) {
+				g.hitWall(x, y)
+			}
+		}
+	}
+}
+
+// slashWalls hits every wall the slash sweeps over.
+func (g *Game) slashWalls(slash *spells.SlashArc) {
+	r := int(math.Ceil(slash.Radius))
+	ox, oy := int(math.Round(slash.OriginX)), int(math.Round(slash.OriginY))
+	for y := oy - r; y <= oy+r; y++ {
+		for x := ox - r; x <= ox+r; x++ {
+			if slash.IsInArc(float64(x), float64(y)) {
+				g.hitWall(x, y)
+			}
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package game
+
+import (
+	"dungeoneer/entities"
+	"dungeoneer/items"
+	"dungeoneer/menumanager"
+	"dungeoneer/ui"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+const (
+	// shopStockSize is how many wares a merchant lays out per run.
+	shopStockSize = 6
+	// consumablePrice is the gold price of any consumable, whatever its
+	// quality, and affixPrice what each rolled affix adds to gear.
+	consumablePrice = 10
+	affixPrice      = 15
+	// Merchants buy items back for a sellDivisor'th of their price.
+	sellDivisor = 4
+	// Gold doesn't survive a run, so the hub merchant asks a
+	// remnantDivisor'th of the gold price in Remnants instead.
+	remnantDivisor = 5
+)
+
+// qualityPrices is the gold price of gear by quality.
+var qualityPrices = map[string]int{
+	items.RarityCommon:    15,
+	items.RarityUncommon:  40,
+	items.RarityRare:      100,
+	items.RarityLegendary: 250,
+}
+
+// Shop unlocks, bought once with Remnants and kept in MetaSave.
+const (
+	unlockWideStock    = "wide_stock"    // merchants lay out more wares
+	unlockDeepStock    = "deep_stock"    // wares roll as if found deeper
+	unlockStartPotions = "start_potions" // every run starts with potions
+	unlockStartGold    = "start_gold"    // every run starts with gold
+)
+
+// shopUnlock is a permanent upgrade sold at the hub merchant.
+type shopUnlock struct {
+	ID, Name string
+	Cost     int // in Remnants
+}
+
+var shopUnlocks = []shopUnlock{
+	{unlockStartPotions, "Start with 3 Healing Potions", 20},
+	{unlockStartGold, "Start with 50 gold", 25},
+	{unlockWideStock, "Merchants stock 3 more wares", 40},
+	{unlockDeepStock, "Merchants stock deeper wares", 60},
+}
+
+const (
+	wideStockBonus    = 3
+	deepStockFloors   = 2
+	startPotionsCount = 3
+	startGoldAmount   = 50
+)
+
+// hasUnlock reports whether the shop unlock id has been bought.
+func (g *Game) hasUnlock(id string) bool {
+	return g.Meta != nil && g.Meta.Unlocks[id]
+}
+
+// applyRunUnlocks hands the player what their shop unlocks start a run with.
+func (g *Game) applyRunUnlocks() {
+	if g.player == nil {
+		return
+	}
+	if g.hasUnlock(unlockStartGold) {
+		g.player.Gold += startGoldAmount
+	}
+	if g.hasUnlock(unlockStartPotions) {
+		potions := items.NewItem(items.HealthPotionID)
+		potions.Count = startPotionsCount
+		g.player.AddToInventory(potions)
+	}
+}
+
+// itemPrice returns what a merchant charges for it.
+func itemPrice(it *items.Item) int {
+	if it.Type == items.ItemConsumable {
+		return consumablePrice
+	}
+	price, ok := qualityPrices[it.Quality]
+	if !ok {
+		price = qualityPrices[items.RarityCommon]
+	}
+	return price + affixPrice*len(it.Affixes)
+}
+
+// remnantPrice returns what the hub merchant charges for it, in Remnants.
+func remnantPrice(it *items.Item) int {
+	return max(1, itemPrice(it)/remnantDivisor)
+}
+
+// sellPrice returns what a merchant pays for one of it.
+func sellPrice(it *items.Item) int {
+	return max(1, itemPrice(it)/sellDivisor)
+}
+
+// restockShop rolls the merchant's wares from the loot table. Stock is
+// rolled once per floor of a run, at that floor's depth, so buying a ware
+// takes it off the shelf until the next floor; coming back to the hub
+// restocks it too.
+func (g *Game) restockShop() {
+	if g.Meta == nil {
+		return
+	}
+	seed, at := int64(g.Meta.RunCount), 0
+	if g.RunState != nil && g.RunState.Active {
+		seed, at = g.RunState.Seed, g.RunState.CurrentFloor
+	}
+	if g.shopStock != nil && g.shopStockRun == g.Meta.RunCount && g.shopStockFloor == at {
+		return
+	}
+	floor := max(at, 1)
+	if g.hasUnlock(unlockDeepStock) {
+		floor += deepStockFloors
+	}
+	size := shopStockSize
+	if g.hasUnlock(unlockWideStock) {
+		size += wideStockBonus
+	}
+
+	table := items.BuildDefaultLootTable("")
+	// The table comes from a map; sort it so a seed always stocks the same.
+	slices.SortFunc(table.Entries, func(a, b items.LootEntry) int { return strings.Compare(a.ItemID, b.ItemID) })
+	rng := rand.New(deriveSource(seed, at, streamShop))
+	g.shopStock = []*items.Item{}
+	for len(g.shopStock) < size {
+		result := items.RollLoot(table, floor, rng)
+		if result == nil {
+			break
+		}
+		if it := result.Item(); it != nil {
+			it.Unidentified = false // merchants know what they sell
+			g.shopStock = append(g.shopStock, it)
+		}
+	}
+	g.shopStockRun, g.shopStockFloor = g.Meta.RunCount, at
+}
+
+// openShop shows the merchant's wares.
+func (g *Game) openShop() {
+	g.restockShop()
+	if g.ShopMenu == nil {
+		g.ShopMenu = ui.NewShopMenu(g.w, g.h)
+	}
+	g.refreshShop()
+	menumanager.Manager().Open(g.ShopMenu)
+}
+
+// refreshShop rebuilds the shop lines after a trade.
+func (g *Game) refreshShop() {
+	line := func(label string, price string) string {
+		return fmt.Sprintf("%-34s %7s", label, price)
+	}
+	opts := []ui.MenuOption{{Text: "-- Wares --"}}
+	for i, it := range g.shopStock {
+		price := fmt.Sprintf("%dg", itemPrice(it))
+		if g.IsInHub {
+			price = fmt.Sprintf("%dR", remnantPrice(it))
+		}
+		opts = append(opts, ui.MenuOption{
+			Text:   line("Buy "+it.DisplayName(), price),
+			Action: func() { g.buyItem(i) },
+		})
+	}
+
+	// Gold is only good for the run, so the hub merchant doesn't buy.
+	if inv := g.player.Inventory; inv != nil && !g.IsInHub {
+		opts = append(opts, ui.MenuOption{Text: "-- Sell --"})
+		for y := 0; y < inv.Height; y++ {
+			for x := 0; x < inv.Width; x++ {
+				it := inv.Grid[y][x]
+				if it == nil || it.QuestLocked {
+					continue
+				}
+				label := "Sell " + it.DisplayName()
+				if it.Count > 1 {
+					label += fmt.Sprintf(" x%d", it.Count)
+				}
+				opts = append(opts, ui.MenuOption{
+					Text:   line(label, fmt.Sprintf("+%dg", sellPrice(it))),
+					Action: func() { g.sellItem(x, y) },
+				})
+			}
+		}
+	}
+
+	if g.IsInHub {
+		opts = append(opts, ui.MenuOption{Text: "-- Unlocks --"})
+		for _, u := range shopUnlocks {
+			price := fmt.Sprintf("%dR", u.Cost)
+			if g.hasUnlock(u.ID) {
+				price = "owned"
+			}
+			opts = append(opts, ui.MenuOption{
+				Text:   line(u.Name, price),
+				Action: func() { g.buyUnlock(u.ID) },
+			})
+		}
+	}
+
+	opts = append(opts, ui.MenuOption{Text: "Leave", Action: func() { menumanager.Manager().CloseActiveMenu() }})
+	g.ShopMenu.SetOptions(opts)
+
+	status := fmt.Sprintf("Gold: %d", g.player.Gold)
+	if g.Meta != nil {
+		status += fmt.Sprintf("   Remnants: %d", g.Meta.Remnants)
+	}
+	g.ShopMenu.SetStatus([]string{status, "Enter Buy/Sell   Esc Leave"})
+}
+
+// buyItem buys ware i of the shop stock, for gold on a run and for Remnants
+// at the hub.
+func (g *Game) buyItem(i int) bool {
+	if i < 0 || i >= len(g.shopStock) {
+		return false
+	}
+	it := g.shopStock[i]
+	if g.IsInHub {
+		price := remnantPrice(it)
+		if g.Meta == nil || g.Meta.Remnants < price {
+			g.ShowHint("Not enough Remnants")
+			return false
+		}
+		if !g.AddItemToPlayer(it) {
+			return false
+		}
+		g.Meta.Remnants -= price
+		g.saveMeta()
+	} else {
+		price := itemPrice(it)
+		if g.player.Gold < price {
+			g.ShowHint("Not enough gold")
+			return false
+		}
+		if !g.AddItemToPlayer(it) {
+			return false
+		}
+		g.player.Gold -= price
+	}
+	g.recordEvent(ReplayEvent{Kind: replayBuy, Index: i})
+	g.shopStock = slices.Delete(g.shopStock, i, i+1)
+	g.refreshShopIfOpen()
+	return true
+}
+
+// sellItem sells one item from inventory cell (x, y) for gold. Only
+// merchants met on a run buy.
+func (g *Game) sellItem(x, y int) bool {
+	if g.IsInHub {
+		return false
+	}
+	it := g.player.DropFromInventory(x, y, 1)
+	if it == nil {
+		return false
+	}
+	g.player.Gold += sellPrice(it)
+	g.recordEvent(ReplayEvent{Kind: replaySell, X: x, Y: y})
+	g.refreshShopIfOpen()
+	return true
+}
+
+// buyUnlock buys the shop unlock id for Remnants and saves it.
+func (g *Game) buyUnlock(id string) bool {
+	i := slices.IndexFunc(shopUnlocks, func(u shopUnlock) bool { return u.ID == id })
+	if i < 0 || g.Meta == nil || g.hasUnlock(id) {
+		return false
+	}
+	if g.Meta.Remnants < shopUnlocks[i].Cost {
+		g.ShowHint("Not enough Remnants")
+		return false
+	}
+	g.Meta.Remnants -= shopUnlocks[i].Cost
+	if g.Meta.Unlocks == nil {
+		g.Meta.Unlocks = make(map[string]bool)
+	}
+	g.Meta.Unlocks[id] = true
+	g.recordEvent(ReplayEvent{Kind: replayUnlock, ID: id})
+	g.saveMeta()
+	g.refreshShopIfOpen()
+	return true
+}
+
+// refreshShopIfOpen rebuilds the shop lines if the shop window exists.
+func (g *Game) refreshShopIfOpen() {
+	if g.ShopMenu != nil {
+		g.refreshShop()
+	}
+}
+
+// hubMerchantOffsets are where, relative to the portal, the hub merchant
+// tries to stand, in order.
+var hubMerchantOffsets = [][2]int{{3, 0}, {-3, 0}, {0, 3}, {0, -3}, {3, 3}, {-3, -3}, {3, -3}, {-3, 3}}
+
+// spawnHubMerchant places the merchant near the hub portal.
+func (g *Game) spawnHubMerchant() {
+	if g.hubPortalX < 0 || g.hubPortalY < 0 {
+		return
+	}
+	for _, t := range minorNPCPool {
+		if !t.Merchant {
+			continue
+		}
+		for _, d := range hubMerchantOffsets {
+			x, y := g.hubPortalX+d[0], g.hubPortalY+d[1]
+			if !g.currentLevel.IsWalkable(x, y) || g.npcAt(x, y) != nil {
+				continue
+			}
+			g.NPCs = append(g.NPCs, g.createNPCFromTemplate(t, x, y))
+			return
+		}
+	}
+}
+
+// npcAt returns the NPC standing on tile (x, y), or nil.
+func (g *Game) npcAt(x, y int) *entities.NPC {
+	for _, npc := range g.NPCs {
+		if npc.TileX == x && npc.TileY == y {
+			return npc
+		}
+	}
+	return nil
+}
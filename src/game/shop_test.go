@@ -0,0 +1,123 @@
+package game
+
+import (
+	"dungeoneer/items"
+	"testing"
+)
+
+func TestShopRestocksOncePerFloor(t *testing.T) {
+	s := newTestSim(t, 9)
+	g := s.Game
+	p := s.Player()
+	p.Gold = 10000
+
+	g.restockShop()
+	if len(g.shopStock) != shopStockSize {
+		t.Fatalf("merchant stocked %d wares, want %d", len(g.shopStock), shopStockSize)
+	}
+	first := g.shopStock[0]
+	price := itemPrice(first)
+	if !g.buyItem(0) {
+		t.Fatal("could not buy the first ware")
+	}
+	if p.Gold != 10000-price {
+		t.Fatalf("gold after buying = %d, want %d", p.Gold, 10000-price)
+	}
+	if !p.HasItemAnywhere(first.ID) {
+		t.Fatal("the bought ware is not in the inventory")
+	}
+
+	g.restockShop()
+	if len(g.shopStock) != shopStockSize-1 {
+		t.Fatal("the merchant restocked on the same floor")
+	}
+	g.RunState.CurrentFloor++
+	g.restockShop()
+	if len(g.shopStock) != shopStockSize {
+		t.Fatal("the merchant did not restock for a new floor")
+	}
+	g.buyItem(0)
+	g.Meta.RunCount++
+	g.restockShop()
+	if len(g.shopStock) != shopStockSize {
+		t.Fatal("the merchant did not restock for a new run")
+	}
+
+	p.Gold = 0
+	if g.buyItem(0) {
+		t.Fatal("bought a ware without gold")
+	}
+}
+
+func TestSellingAndUnlocks(t *testing.T) {
+	s := newTestSim(t, 3)
+	g := s.Game
+	p := s.Player()
+	p.Gold = 0
+
+	potions := items.NewItem(items.ManaPotionID)
+	potions.Count = 2
+	if !p.AddToInventory(potions) {
+		t.Fatal("no room for the potions")
+	}
+	x, y := -1, -1
+	for gy, row := range p.Inventory.Grid {
+		for gx, it := range row {
+			if it != nil && it.ID == items.ManaPotionID {
+				x, y = gx, gy
+			}
+		}
+	}
+	if !g.sellItem(x, y) || p.Gold != sellPrice(potions) || potions.Count != 1 {
+		t.Fatalf("selling one potion left %d gold and %d potions", p.Gold, potions.Count)
+	}
+
+	g.Meta.Remnants = 30
+	if !g.buyUnlock(unlockStartGold) || g.Meta.Remnants != 5 || !g.hasUnlock(unlockStartGold) {
+		t.Fatal("buying an unlock did not spend Remnants or record it")
+	}
+	if g.buyUnlock(unlockStartGold) || g.buyUnlock(unlockDeepStock) {
+		t.Fatal("bought an unlock twice or without enough Remnants")
+	}
+	gold := p.Gold
+	g.applyRunUnlocks()
+	if p.Gold != gold+startGoldAmount {
+		t.Fatal("the start gold unlock gave no gold")
+	}
+}
+
+func TestHubWaresCostRemnants(t *testing.T) {
+	s := newTestSim(t, 9)
+	g := s.Game
+	g.returnToHub()
+	p := s.Player()
+	if p.Gold != 0 {
+		t.Fatalf("gold in the hub = %d, want 0", p.Gold)
+	}
+
+	g.restockShop()
+	ware := g.shopStock[0]
+	price := remnantPrice(ware)
+	g.Meta.Remnants = price - 1
+	if g.buyItem(0) {
+		t.Fatal("bought a hub ware without enough Remnants")
+	}
+	g.Meta.Remnants = price
+	if !g.buyItem(0) {
+		t.Fatal("could not buy a hub ware with Remnants")
+	}
+	if g.Meta.Remnants != 0 || !p.HasItemAnywhere(ware.ID) {
+		t.Fatal("buying in the hub did not spend Remnants or hand over the ware")
+	}
+
+	for y, row := range p.Inventory.Grid {
+		for x, it := range row {
+			if it != nil && g.sellItem(x, y) {
+				t.Fatal("sold an item in the hub")
+			}
+		}
+	}
+	if p.Gold != 0 {
+		t.Fatalf("gold in the hub after selling = %d, want 0", p.Gold)
+	}
+}
@@ -97,9 +97,18 @@ func (g *Game) updateSpells() {
 			isArcaneBolt = true
 			prevX, prevY = ab.X, ab.Y
 		}
+		fireballInFlight := false
+		if fb, ok := sp.(*spells.Fireball); ok && !fb.Impact {
+			fireballInFlight = true
+		}
 
 		sp.Update(g.currentLevel, g.DeltaTime)
 		if fb, ok := sp.(*spells.Fireball); ok {
+			if fireballInFlight && fb.Impact && !fb.MonsterCast {
+				// Flew into a wall: it bursts there, and the blast hits
+				// the wall once along with any cracked walls around it.
+				g.applyFireballDamage(fb, int(fb.X), int(fb.Y))
+			}
 			if !fb.Impact {
 				if fb.MonsterCast {
 					// Monster-cast fireball: check player collision.
@@ -109,6 +118,7 @@ func (g *Game) updateSpells() {
 						if dx*dx+dy*dy <= fb.Radius*fb.Radius {
 							fb.Impact = true
 							g.player.TakeDamage(fb.Info.Damage)
+							g.playerStruck(nil, fb.Info.Damage)
 						}
 					}
 				} else {
@@ -237,9 +247,7 @@ func (g *Game) updateChanneledSpray() {
 				continue
 			}
 			if spray.IsInCone(m.BodyX(), m.BodyY()) {
-				if m.TakeDamage(spray.Info.Damage, &g.HitMarkers, &g.DamageNumbers) {
-					g.handleMonsterDeath(m)
-				}
+				g.hitMonster(m, spray.Info.Damage)
 			}
 		}
 	}
@@ -273,28 +281,15 @@ func (g *Game) applyFireballDamage(fb *spells.Fireball, cx, cy int) {
 		dy := int(math.Abs(float64(m.TileY - cy)))
 		if dx <= radius && dy <= radius {
 			if g.hasLineOfSight(cx, cy, m.TileX, m.TileY) {
-				if m.TakeDamage(dmg, &g.HitMarkers, &g.DamageNumbers) {
-					g.handleMonsterDeath(m)
-				}
+				g.hitMonster(m, dmg)
 			}
 		}
 	}
+	g.blastWalls(cx, cy, radius)
 }
 
 func (g *Game) hasLineOfSight(x1, y1, x2, y2 int) bool {
-	pts := fov.TraceLineToTiles(float64(x1)+0.5, float64(y1)+0.5, float64(x2)+0.5, float64(y2)+0.5)
-	for _, p := range pts {
-		if p.X == x1 && p.Y == y1 {
-			continue
-		}
-		if p.X == x2 && p.Y == y2 {
-			return true
-		}
-		if !g.currentLevel.IsWalkable(p.X, p.Y) {
-			return false
-		}
-	}
-	return true
+	return fov.HasLineOfSight(g.currentLevel, x1, y1, x2, y2)
 }
 
 // spellManaCost returns the mana cost for a spell ability ID.
@@ -358,6 +353,8 @@ func (g *Game) castSpellSlot(index int) {
 
 	if cast {
 		g.player.Mana -= cost
+		g.emitPlayerNoise(spellNoiseRadius)
+		g.playerActed(items.TriggerOnCast, cost)
 	}
 }
 
@@ -420,11 +417,10 @@ func (g *Game) applySlashDamage(slash *spells.SlashArc) {
 			continue
 		}
 		if slash.IsInArc(m.InterpX, m.InterpY) {
-			if m.TakeDamage(dmg, &g.HitMarkers, &g.DamageNumbers) {
-				g.handleMonsterDeath(m)
-			}
+			g.hitMonster(m, dmg)
 		}
 	}
+	g.slashWalls(slash)
 }
 
 func (g *Game) handleArcaneBolt(px, py, tx, ty float64) {
@@ -438,6 +434,8 @@ func (g *Game) handleArcaneBolt(px, py, tx, ty float64) {
 	}
 	c.PutOnCooldown(info)
 	g.player.Mana -= info.Cost
+	g.emitPlayerNoise(spellNoiseRadius)
+	g.playerActed(items.TriggerOnCast, info.Cost)
 
 	// Emit from the player's body center so the bolt travels from the
 	// character's visual position, not the feet anchor.
@@ -455,11 +453,8 @@ func (g *Game) handleBasicMelee(cx, cy int) {
 		if m.TileX == cx && m.TileY == cy &&
 			entities.IsAdjacentRanged(g.player.TileX, g.player.TileY, m.TileX, m.TileY, 2) &&
 			g.player.CanAttack() {
-			died := m.TakeDamage(g.player.Damage, &g.HitMarkers, &g.DamageNumbers)
 			g.player.AttackTick = 0
-			if died {
-				g.handleMonsterDeath(m)
-			}
+			g.hitMonster(m, g.player.Damage)
 		}
 	}
 }
@@ -511,9 +506,7 @@ func (g *Game) checkArcaneBoltHits(ab *spells.ArcaneBolt, prevX, prevY float64,
 			ab.Impact = true
 			ab.X = hitX
 			ab.Y = hitY
-			if m.TakeDamage(ab.Info.Damage, &g.HitMarkers, &g.DamageNumbers) {
-				g.handleMonsterDeath(m)
-			}
+			g.hitMonster(m, ab.Info.Damage)
 			return
 		}
 	}
@@ -643,9 +636,7 @@ func (g *Game) applyChaosRayDamage(cr *spells.ChaosRay) {
 			p1 := cr.Path[i]
 			p2 := cr.Path[i+1]
 			if pointSegmentDistance(px, py, p1.X, p1.Y, p2.X, p2.Y) <= radius {
-				if m.TakeDamage(cr.Info.Damage, &g.HitMarkers, &g.DamageNumbers) {
-					g.handleMonsterDeath(m)
-				}
+				g.hitMonster(m, cr.Info.Damage)
 				break
 			}
 		}
@@ -691,9 +682,7 @@ func (g *Game) applyLightningDamage(l *spells.LightningStrike, cx, cy int) {
 		dy := int(math.Abs(float64(m.TileY - cy)))
 		if dx <= radius && dy <= radius {
 			if g.hasLineOfSight(cx, cy, m.TileX, m.TileY) {
-				if m.TakeDamage(dmg, &g.HitMarkers, &g.DamageNumbers) {
-					g.handleMonsterDeath(m)
-				}
+				g.hitMonster(m, dmg)
 			}
 		}
 	}
@@ -735,9 +724,7 @@ func (g *Game) applyFractalDamage(n *spells.FractalNode, cx, cy int) {
 		dy := int(math.Abs(float64(m.TileY - cy)))
 		if dx <= radius && dy <= radius {
 			if g.hasLineOfSight(cx, cy, m.TileX, m.TileY) {
-				if m.TakeDamage(dmg, &g.HitMarkers, &g.DamageNumbers) {
-					g.handleMonsterDeath(m)
-				}
+				g.hitMonster(m, dmg)
 			}
 		}
 	}
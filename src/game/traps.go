@@ -0,0 +1,193 @@
+package game
+
+import (
+	"dungeoneer/entities"
+	"dungeoneer/fov"
+	"dungeoneer/leveleditor"
+	"dungeoneer/levels"
+	"dungeoneer/pathing"
+	"math"
+)
+
+// Trap tuning.
+const (
+	trapRearm      = 2.0  // seconds before a re-arming trap can go off again
+	collapseFuse   = 0.5  // seconds between stepping on a collapsing floor and the fall
+	dartSpeed      = 0.25 // tiles per tick
+	dartDamage     = 8
+	spikeDamage    = 10
+	collapseDamage = 12
+	alarmRadius    = 10
+)
+
+// trapBudget is how many traps a floor gets at the given difficulty.
+func trapBudget(difficulty float64) int {
+	return 2 + int(difficulty*6)
+}
+
+// newTrap builds the live trap for a placed trap entity. A collapsing floor
+// whose tile is already a pit has gone off before.
+func (g *Game) newTrap(ent levels.PlacedEntity) *entities.Trap {
+	t := &entities.Trap{TileX: ent.X, TileY: ent.Y, Kind: ent.Trap, Wire: ent.Wire}
+	if t.Kind == levels.TrapCollapse && !g.currentLevel.IsWalkable(t.TileX, t.TileY) {
+		t.Spent, t.Revealed = true, true
+	}
+	return t
+}
+
+// updateTraps advances trap timers and sets off traps the player has just
+// stepped onto. Monsters know where the traps are and never set them off; a
+// dashing player skims over them.
+func (g *Game) updateTraps(dt float64) {
+	for _, t := range g.Traps {
+		if t.Timer <= 0 {
+			continue
+		}
+		t.Timer -= dt
+		if t.Timer <= 0 && t.Kind == levels.TrapCollapse {
+			g.collapseFloor(t)
+		}
+	}
+	if g.player == nil || g.player.IsDead {
+		return
+	}
+	px, py := g.player.TileX, g.player.TileY
+	for _, t := range g.Traps {
+		on := !g.player.IsDashing && t.TileX == px && t.TileY == py
+		if on && !t.Pressed {
+			g.triggerTrap(t, px, py)
+		}
+		t.Pressed = on
+	}
+}
+
+// triggerTrap sets off t and every armed trap on its wire. (x, y) is the tile
+// that was stepped on, which dart launchers aim at.
+func (g *Game) triggerTrap(t *entities.Trap, x, y int) {
+	if !t.Armed() {
+		return
+	}
+	g.fireTrap(t, x, y)
+	if t.Wire == 0 {
+		return
+	}
+	for _, o := range g.Traps {
+		if o != t && o.Wire == t.Wire && o.Armed() {
+			g.fireTrap(o, x, y)
+		}
+	}
+}
+
+// fireTrap makes a single trap go off and reveals it.
+func (g *Game) fireTrap(t *entities.Trap, x, y int) {
+	t.Timer = trapRearm
+	g.revealTrap(t)
+	switch t.Kind {
+	case levels.TrapDart:
+		dx, dy := float64(x-t.TileX), float64(y-t.TileY)
+		d := math.Hypot(dx, dy)
+		if d == 0 {
+			return
+		}
+		// Launch from the floor tile in front of the launcher, not from
+		// inside the wall, or the dart would hit the wall it sits in.
+		sx, sy := float64(t.TileX)+dx/d, float64(t.TileY)+dy/d
+		g.MonsterProjectiles = append(g.MonsterProjectiles,
+			entities.NewMonsterProjectile(sx, sy, float64(x), float64(y), dartSpeed, dartDamage))
+	case levels.TrapSpikes:
+		if g.player.TileX == t.TileX && g.player.TileY == t.TileY {
+			g.player.TakeDamage(spikeDamage)
+		}
+	case levels.TrapCollapse:
+		t.Spent = true
+		t.Timer = collapseFuse
+		g.ShowHint("The floor cracks!")
+	case levels.TrapAlarm:
+		t.Spent = true
+		g.soundAlarm(t.TileX, t.TileY)
+	}
+}
+
+// revealTrap marks t as found and paints its sprite on the floor. Dart
+// launchers sit in walls and stay as they are.
+func (g *Game) revealTrap(t *entities.Trap) {
+	if t.Revealed {
+		return
+	}
+	t.Revealed = true
+	if t.Kind == levels.TrapDart {
+		return
+	}
+	id := leveleditor.TrapSprites[t.Kind]
+	meta, ok := leveleditor.SpriteRegistry[id]
+	tile := g.currentLevel.Tile(t.TileX, t.TileY)
+	if ok && tile != nil && !tile.HasSpriteID(id) {
+		tile.AddSpriteByID(id, meta.Image)
+	}
+}
+
+// soundAlarm wakes every dormant ambusher within alarmRadius of (x, y).
+func (g *Game) soundAlarm(x, y int) {
+	woke := false
+	for _, m := range g.Monsters {
+		if _, ok := m.Behavior.(*entities.AmbushBehavior); !ok || m.IsDead || m.Noticed() {
+			continue
+		}
+		dx, dy := m.TileX-x, m.TileY-y
+		if dx*dx+dy*dy <= alarmRadius*alarmRadius {
+			m.Alert(x, y)
+			woke = true
+		}
+	}
+	if woke {
+		g.ShowHint("An alarm rings out!")
+	} else {
+		g.ShowHint("A bell tolls, but nothing stirs.")
+	}
+}
+
+// collapseFloor turns a collapsing floor into a pit. Whoever is still on it
+// falls, takes damage and scrambles out onto a neighbouring tile.
+func (g *Game) collapseFloor(t *entities.Trap) {
+	tile := g.currentLevel.Tile(t.TileX, t.TileY)
+	if tile == nil {
+		return
+	}
+	tile.Sprites = nil
+	tile.IsWalkable = false
+
+	if g.player.TileX == t.TileX && g.player.TileY == t.TileY {
+		g.player.TakeDamage(collapseDamage)
+		if x, y, ok := g.pitExit(t.TileX, t.TileY); ok {
+			g.placePlayerAt(x, y)
+		}
+	}
+	for _, m := range g.Monsters {
+		if m.IsDead || m.TileX != t.TileX || m.TileY != t.TileY {
+			continue
+		}
+		if m.TakeDamage(collapseDamage, &g.HitMarkers, &g.DamageNumbers) {
+			g.handleMonsterDeath(m)
+			continue
+		}
+		if x, y, ok := g.pitExit(t.TileX, t.TileY); ok {
+			m.TileX, m.TileY = x, y
+			m.InterpX, m.InterpY = float64(x), float64(y)
+			m.Moving = false
+		}
+	}
+	g.RaycastWalls = fov.LevelToWalls(g.currentLevel)
+	fov.InvalidateCache()
+	pathing.InvalidateCache()
+	g.cachedRays = nil
+}
+
+// pitExit returns a safe tile next to a pit.
+func (g *Game) pitExit(x, y int) (int, int, bool) {
+	for _, d := range [8][2]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}, {1, 1}, {-1, 1}, {1, -1}, {-1, -1}} {
+		if g.currentLevel.IsSafe(x+d[0], y+d[1]) {
+			return x + d[0], y + d[1], true
+		}
+	}
+	return 0, 0, false
+}
@@ -22,6 +22,12 @@ type SkillSlot struct {
 	Name     string
 }
 
+// BeltSlot is a quick-use belt slot: the item's icon and how many are left.
+type BeltSlot struct {
+	Icon  *ebiten.Image
+	Count int
+}
+
 // HUD renders a bottom-screen interface similar to classic action RPGs.
 type HUD struct {
 	HealthPercent  float64
@@ -36,6 +42,7 @@ type HUD struct {
 	Gold           int
 	SkillSlots     [6]SkillSlot
 	ActiveSkill    int
+	Belt           [constants.BeltSlots]BeltSlot
 
 	OrbFrame      *ebiten.Image
 	HUDBackground *ebiten.Image
@@ -156,6 +163,34 @@ func (h *HUD) drawSkillBar(screen *ebiten.Image, w, hgt int) {
 		h.drawDashCharges(screen, x, barW, y)
 	}
 	h.drawEXPBar(screen, x, barW, y)
+	h.drawBelt(screen, x+barW+16, y+slot)
+}
+
+// drawBelt draws the quick-use belt with its bottom-left corner at x, bottom.
+func (h *HUD) drawBelt(screen *ebiten.Image, x, bottom int) {
+	slot := 40
+	pad := 4
+	y := bottom - slot
+	for i, b := range h.Belt {
+		sx := x + i*(slot+pad)
+		key := fmt.Sprintf("%d", (i+7)%10)
+		if b.Count == 0 {
+			vector.StrokeRect(screen, float32(sx), float32(y), float32(slot), float32(slot), 2, color.RGBA{80, 80, 80, 180}, false)
+			text.Draw(screen, key, basicfont.Face7x13, sx+slot/2-4, y+slot+12, color.RGBA{80, 80, 80, 180})
+			continue
+		}
+		vector.StrokeRect(screen, float32(sx), float32(y), float32(slot), float32(slot), 2, color.White, false)
+		if ic := b.Icon; ic != nil {
+			iw, ih := ic.Size()
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(float64(slot)/float64(iw), float64(slot)/float64(ih))
+			op.GeoM.Translate(float64(sx), float64(y))
+			screen.DrawImage(ic, op)
+		}
+		count := fmt.Sprintf("%d", b.Count)
+		text.Draw(screen, count, basicfont.Face7x13, sx+slot-3-len(count)*7, y+slot-3, color.White)
+		text.Draw(screen, key, basicfont.Face7x13, sx+slot/2-4, y+slot+12, color.White)
+	}
 }
 
 func (h *HUD) drawDashCharges(screen *ebiten.Image, barX, barW, barY int) {
@@ -114,6 +114,36 @@ func (inv *Inventory) HasItem(id string) bool {
 	return false
 }
 
+// RemoveItem removes one item with the given ID, reporting whether one was found.
+func (inv *Inventory) RemoveItem(id string) bool {
+	for y := 0; y < inv.Height; y++ {
+		for x := 0; x < inv.Width; x++ {
+			slot := inv.Grid[y][x]
+			if slot == nil || slot.ID != id {
+				continue
+			}
+			if slot.Count > 1 {
+				slot.Count--
+			} else {
+				inv.Grid[y][x] = nil
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveType removes every item of the given type.
+func (inv *Inventory) RemoveType(t items.ItemType) {
+	for y := 0; y < inv.Height; y++ {
+		for x := 0; x < inv.Width; x++ {
+			if slot := inv.Grid[y][x]; slot != nil && slot.Type == t {
+				inv.Grid[y][x] = nil
+			}
+		}
+	}
+}
+
 // FirstEmpty returns the coordinates of the first empty grid cell.
 func FirstEmpty(inv *Inventory) (x, y int, ok bool) {
 	for y = 0; y < inv.Height; y++ {
@@ -0,0 +1,152 @@
+package items
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// AffixDef is a stat bonus that can be rolled onto a dropped item, naming it
+// with a prefix ("Mighty Iron Amulet") or a suffix ("Iron Amulet of the Ox").
+type AffixDef struct {
+	ID       string
+	Name     string
+	Suffix   bool
+	Stat     string // same keys as ItemTemplate.Stats
+	Min, Max int    // value range on floor 1, before floor scaling
+}
+
+// ItemAffix is an affix rolled onto one item instance.
+type ItemAffix struct {
+	ID    string `json:"id"`
+	Value int    `json:"value"`
+}
+
+// AffixDefs lists every item affix, prefixes first.
+var AffixDefs = []AffixDef{
+	{ID: "mighty", Name: "Mighty", Stat: "Strength", Min: 1, Max: 3},
+	{ID: "nimble", Name: "Nimble", Stat: "Dexterity", Min: 1, Max: 3},
+	{ID: "sturdy", Name: "Sturdy", Stat: "Vitality", Min: 1, Max: 4},
+	{ID: "wise", Name: "Wise", Stat: "Intelligence", Min: 1, Max: 3},
+	{ID: "lucky", Name: "Lucky", Stat: "Luck", Min: 1, Max: 3},
+	{ID: "of_the_ox", Name: "of the Ox", Suffix: true, Stat: "Strength", Min: 2, Max: 4},
+	{ID: "of_the_fox", Name: "of the Fox", Suffix: true, Stat: "Dexterity", Min: 2, Max: 4},
+	{ID: "of_the_bear", Name: "of the Bear", Suffix: true, Stat: "Vitality", Min: 2, Max: 5},
+	{ID: "of_the_owl", Name: "of the Owl", Suffix: true, Stat: "Intelligence", Min: 2, Max: 4},
+	{ID: "of_fortune", Name: "of Fortune", Suffix: true, Stat: "Luck", Min: 2, Max: 4},
+}
+
+// affixFloorScale is how much affix values grow per floor below the first.
+const affixFloorScale = 0.1
+
+// Def returns the definition of a.
+func (a ItemAffix) Def() (AffixDef, bool) {
+	for _, d := range AffixDefs {
+		if d.ID == a.ID {
+			return d, true
+		}
+	}
+	return AffixDef{}, false
+}
+
+// RollItemAffixes rolls the affixes for a fresh drop of tmpl found on floor
+// at the given rarity. Better rarities get more affixes and stronger values;
+// deeper floors scale every value up. Only gear gets affixes.
+func RollItemAffixes(tmpl *ItemTemplate, rarity string, floor int, rng *rand.Rand) []ItemAffix {
+	if tmpl == nil || !tmpl.Equippable || tmpl.Stackable || tmpl.QuestLocked {
+		return nil
+	}
+	tier := max(rarityTier(rarity), rarityTier(tmpl.Quality))
+	n := 0
+	switch tier {
+	case 0:
+		if randFloat(rng) < 0.5 {
+			n = 1
+		}
+	case 1:
+		n = 1
+	default:
+		n = 2
+	}
+	if n == 0 {
+		return nil
+	}
+
+	// One prefix and one suffix at most; a lone affix is either.
+	suffix := randFloat(rng) < 0.5
+	var affixes []ItemAffix
+	for i := 0; i < n; i++ {
+		var pool []AffixDef
+		for _, d := range AffixDefs {
+			if d.Suffix == suffix {
+				pool = append(pool, d)
+			}
+		}
+		d := pool[int(randFloat(rng)*float64(len(pool)))]
+		// Rarer items roll toward the top of the range.
+		roll := math.Max(randFloat(rng), float64(tier)/4)
+		base := float64(d.Min) + roll*float64(d.Max-d.Min)
+		value := int(math.Round(base * (1 + affixFloorScale*float64(max(0, floor-1)))))
+		affixes = append(affixes, ItemAffix{ID: d.ID, Value: max(1, value)})
+		suffix = !suffix
+	}
+	return affixes
+}
+
+// rarityTier ranks a rarity, common (or none) being 0.
+func rarityTier(rarity string) int {
+	switch rarity {
+	case RarityUncommon:
+		return 1
+	case RarityRare:
+		return 2
+	case RarityLegendary:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// DisplayName returns the item's name with its rolled prefix and suffix, or
+// just its name while it is unidentified.
+func (i *Item) DisplayName() string {
+	name := i.Name
+	if i.Unidentified {
+		return name
+	}
+	for _, a := range i.Affixes {
+		d, ok := a.Def()
+		switch {
+		case !ok:
+		case d.Suffix:
+			name += " " + d.Name
+		default:
+			name = d.Name + " " + name
+		}
+	}
+	return name
+}
+
+// AffixStats sums the item's rolled affixes, keyed as ItemTemplate.Stats.
+// An unidentified item's affixes don't count yet.
+func (i *Item) AffixStats() map[string]int {
+	if len(i.Affixes) == 0 || i.Unidentified {
+		return nil
+	}
+	stats := map[string]int{}
+	for _, a := range i.Affixes {
+		if d, ok := a.Def(); ok {
+			stats[d.Stat] += a.Value
+		}
+	}
+	return stats
+}
+
+// Identify reveals an unidentified item's affixes, reporting whether there
+// was anything to reveal.
+func (i *Item) Identify() bool {
+	if !i.Unidentified {
+		return false
+	}
+	i.Unidentified = false
+	return true
+}
@@ -0,0 +1,73 @@
+package items
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestRollItemAffixes(t *testing.T) {
+	ring := &ItemTemplate{ID: "test_ring", Name: "Ring", Equippable: true}
+	RegisterItem(ring)
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	for i := 0; i < 100; i++ {
+		affixes := RollItemAffixes(ring, RarityLegendary, 1, rng)
+		if len(affixes) != 2 {
+			t.Fatalf("legendary drop rolled %d affixes, want 2", len(affixes))
+		}
+		a, _ := affixes[0].Def()
+		b, _ := affixes[1].Def()
+		if a.Suffix == b.Suffix {
+			t.Fatalf("rolled two of a kind: %q and %q", a.Name, b.Name)
+		}
+	}
+
+	if got := RollItemAffixes(&ItemTemplate{ID: "potion", Stackable: true}, RarityRare, 1, rng); got != nil {
+		t.Fatalf("a consumable rolled affixes %v", got)
+	}
+
+	sum := func(floor int) int {
+		total := 0
+		for i := 0; i < 200; i++ {
+			for _, a := range RollItemAffixes(ring, RarityUncommon, floor, rng) {
+				total += a.Value
+			}
+		}
+		return total
+	}
+	if shallow, deep := sum(1), sum(11); deep <= shallow {
+		t.Fatalf("affixes on floor 11 (%d) no stronger than on floor 1 (%d)", deep, shallow)
+	}
+}
+
+func TestAffixesSurviveSave(t *testing.T) {
+	RegisterItem(&ItemTemplate{ID: "test_amulet", Name: "Amulet", Equippable: true})
+	it := NewItem("test_amulet")
+	it.Affixes = []ItemAffix{{ID: "sturdy", Value: 3}, {ID: "of_the_owl", Value: 5}}
+
+	data, err := json.Marshal(it.ToSave())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var save ItemSave
+	if err := json.Unmarshal(data, &save); err != nil {
+		t.Fatal(err)
+	}
+	back := FromSave(save)
+	if got := back.DisplayName(); got != "Sturdy Amulet of the Owl" {
+		t.Fatalf("name after a save round trip = %q", got)
+	}
+	if stats := back.AffixStats(); stats["Vitality"] != 3 || stats["Intelligence"] != 5 {
+		t.Fatalf("affix stats after a save round trip = %v", stats)
+	}
+
+	it.Unidentified = true
+	back = FromSave(it.ToSave())
+	if !back.Unidentified || back.AffixStats() != nil || back.DisplayName() != "Amulet" {
+		t.Fatal("an unidentified item showed its affixes after a save round trip")
+	}
+	if !back.Identify() || back.AffixStats()["Vitality"] != 3 {
+		t.Fatal("identifying the item did not reveal its affixes")
+	}
+}
@@ -0,0 +1,93 @@
+package items
+
+// Consumable item IDs.
+const (
+	HealthPotionID   = "potion_health"
+	ManaPotionID     = "potion_mana"
+	TeleportScrollID = "scroll_teleport"
+	RevealScrollID   = "scroll_reveal"
+	IdentifyScrollID = "scroll_identify"
+	FireBombID       = "bomb_fire"
+)
+
+const (
+	// Potions restore these percentages of the player's max HP and mana.
+	HealthPotionPct = 40
+	ManaPotionPct   = 50
+	// TeleportMinDistance is how far, in tiles, a teleport scroll moves the
+	// player at least when the floor has room.
+	TeleportMinDistance = 10
+	// A fire bomb lands up to BombRange tiles away and bursts like a
+	// level 2 fireball dealing BombDamage before the level multiplier.
+	BombRange       = 6
+	BombDamage      = 6
+	BombNoiseRadius = 10
+)
+
+// UseContext is the game a consumable is used in. OnUse hooks act through
+// it; each call reports whether it took effect, so the item is used up.
+type UseContext interface {
+	RestoreHealth(pct int) bool
+	RestoreMana(pct int) bool
+	Teleport(minDistance int) bool
+	RevealFloor() bool
+	IdentifyGear() bool
+	ThrowFireBomb(reach, damage, noiseRadius int) bool
+}
+
+// consumable describes one bundled consumable.
+type consumable struct {
+	ID, Name    string
+	Description string
+	IconID      string // item whose icon it borrows
+	MaxStack    int
+	DropWeight  float64 // loot table weight, next to 1 for gear
+	Use         func(UseContext) bool
+}
+
+var consumables = []consumable{
+	{HealthPotionID, "Healing Potion", "Restores a good share of your health.", "item_2_18", 10, 3,
+		func(c UseContext) bool { return c.RestoreHealth(HealthPotionPct) }},
+	{ManaPotionID, "Mana Potion", "Restores a good share of your mana.", "item_2_15", 10, 2.5,
+		func(c UseContext) bool { return c.RestoreMana(ManaPotionPct) }},
+	{TeleportScrollID, "Scroll of Teleport", "Whisks you somewhere else on this floor.", "item_0_23", 5, 1,
+		func(c UseContext) bool { return c.Teleport(TeleportMinDistance) }},
+	{RevealScrollID, "Scroll of Revelation", "Reveals the layout of this floor.", "item_0_23", 5, 0.8,
+		UseContext.RevealFloor},
+	{IdentifyScrollID, "Scroll of Identify", "Reveals the hidden properties of your gear.", "item_0_23", 5, 1,
+		UseContext.IdentifyGear},
+	{FireBombID, "Fire Bomb", "Thrown at the cursor, it bursts into flame.", "item_1_21", 5, 1.5,
+		func(c UseContext) bool { return c.ThrowFireBomb(BombRange, BombDamage, BombNoiseRadius) }},
+}
+
+// registerConsumables adds the bundled consumables. Called once after
+// LoadItemSheet so the items they borrow icons from exist.
+func registerConsumables() {
+	for _, c := range consumables {
+		tmpl := &ItemTemplate{
+			ID:          c.ID,
+			Name:        c.Name,
+			Type:        ItemConsumable,
+			Description: c.Description,
+			Stackable:   true,
+			MaxStack:    c.MaxStack,
+			Usable:      true,
+			Quality:     RarityCommon,
+			OnUse:       c.Use,
+		}
+		if icon, ok := Registry[c.IconID]; ok {
+			tmpl.Icon = icon.Icon
+		}
+		RegisterItem(tmpl)
+	}
+}
+
+// dropWeight returns the loot table weight of the item with id.
+func dropWeight(id string) float64 {
+	for _, c := range consumables {
+		if c.ID == id {
+			return c.DropWeight
+		}
+	}
+	return 1.0
+}
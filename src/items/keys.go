@@ -0,0 +1,53 @@
+package items
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keyPrefix starts the ID of every floor key; the rest is the lock ID.
+const keyPrefix = "key_"
+
+// keyIconID is the item whose icon keys borrow.
+const keyIconID = "item_0_5"
+
+// KeyID returns the item ID of the key for a floor lock.
+func KeyID(lock int) string {
+	return fmt.Sprintf("%s%d", keyPrefix, lock)
+}
+
+// KeyLock returns the lock a key item opens, or false if id is not a key.
+func KeyLock(id string) (int, bool) {
+	if !strings.HasPrefix(id, keyPrefix) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(id[len(keyPrefix):])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// registerKey adds the template for a key on first use. Keys only open doors
+// on the floor they were found on and never appear in loot tables.
+func registerKey(id string) (*ItemTemplate, bool) {
+	lock, ok := KeyLock(id)
+	if !ok {
+		return nil, false
+	}
+	tmpl := &ItemTemplate{
+		ID:          id,
+		Name:        fmt.Sprintf("Door Sigil %d", lock),
+		Type:        ItemKey,
+		Description: "Opens a sealed door on this floor.",
+		MaxStack:    1,
+		QuestLocked: true,
+		Quality:     RarityCommon,
+	}
+	if icon, ok := Registry[keyIconID]; ok {
+		tmpl.Icon = icon.Icon
+	}
+	RegisterItem(tmpl)
+	return tmpl, true
+}
@@ -92,7 +92,8 @@ func LoadItemSheet(img *ebiten.Image, entries []sheetEntry) {
 }
 
 // LoadDefaultItems loads the bundled item sheet and mapping, then applies
-// ability overrides to starter/quest items.
+// ability overrides to starter/quest items and registers the item sets and
+// consumables.
 func LoadDefaultItems() error {
 	img, err := images.LoadEmbeddedImage(images.Item_subset_png)
 	if err != nil {
@@ -104,6 +105,8 @@ func LoadDefaultItems() error {
 	}
 	LoadItemSheet(img, entries)
 	applyAbilityOverrides()
+	applyDefaultSets()
+	registerConsumables()
 	return nil
 }
 
@@ -12,10 +12,10 @@ const (
 
 // LootEntry defines a single possible drop.
 type LootEntry struct {
-	ItemID   string
-	Weight   float64
-	MinFloor int
-	Rarity   string
+	ItemID   string  `json:"item_id"`
+	Weight   float64 `json:"weight"`
+	MinFloor int     `json:"min_floor"`
+	Rarity   string  `json:"rarity"`
 }
 
 // LootTableDef defines loot for a biome.
@@ -26,12 +26,40 @@ type LootTableDef struct {
 
 // LootResult is the outcome of a loot roll.
 type LootResult struct {
-	ItemID string
-	Count  int
+	ItemID       string
+	Count        int
+	Affixes      []ItemAffix
+	Unidentified bool
+}
+
+// Item makes the item instance the roll produced, or nil if its template is
+// not registered.
+func (r *LootResult) Item() *Item {
+	tmpl, ok := Registry[r.ItemID]
+	if !ok {
+		return nil
+	}
+	return &Item{ItemTemplate: tmpl, Count: r.Count, Affixes: r.Affixes, Unidentified: r.Unidentified}
+}
+
+// rolled makes the result for drawing entry on floor, rolling its affixes.
+// Gear with both a prefix and a suffix drops unidentified.
+func rolled(e LootEntry, floor int, rng *rand.Rand) *LootResult {
+	affixes := RollItemAffixes(Registry[e.ItemID], e.Rarity, floor, rng)
+	return &LootResult{ItemID: e.ItemID, Count: 1, Affixes: affixes, Unidentified: len(affixes) > 1}
+}
+
+// randFloat draws from rng, or from the global source when rng is nil so
+// callers outside a seeded run (editor, tools) don't need a stream.
+func randFloat(rng *rand.Rand) float64 {
+	if rng == nil {
+		return rand.Float64()
+	}
+	return rng.Float64()
 }
 
 // ShouldDrop returns true if a killed monster drops loot based on role and floor.
-func ShouldDrop(role string, floor int) bool {
+func ShouldDrop(role string, floor int, rng *rand.Rand) bool {
 	base := 0.30
 	switch role {
 	case "elite":
@@ -43,7 +71,7 @@ func ShouldDrop(role string, floor int) bool {
 	if chance > 0.90 {
 		chance = 0.90
 	}
-	return rand.Float64() < chance
+	return randFloat(rng) < chance
 }
 
 // adjustedWeight scales an entry's weight by floor for rarity progression.
@@ -64,7 +92,7 @@ func adjustedWeight(e LootEntry, floor int) float64 {
 
 // RollLoot picks an item from the loot table based on floor and luck.
 // Returns nil if no valid entry found.
-func RollLoot(table *LootTableDef, floor int) *LootResult {
+func RollLoot(table *LootTableDef, floor int, rng *rand.Rand) *LootResult {
 	if table == nil || len(table.Entries) == 0 {
 		return nil
 	}
@@ -93,19 +121,19 @@ func RollLoot(table *LootTableDef, floor int) *LootResult {
 		return nil
 	}
 
-	r := rand.Float64() * total
+	r := randFloat(rng) * total
 	for _, c := range pool {
 		r -= c.weight
 		if r <= 0 {
-			return &LootResult{ItemID: c.entry.ItemID, Count: 1}
+			return rolled(c.entry, floor, rng)
 		}
 	}
-	return &LootResult{ItemID: pool[len(pool)-1].entry.ItemID, Count: 1}
+	return rolled(pool[len(pool)-1].entry, floor, rng)
 }
 
 // RollAbilityItem picks a random ability-granting item from the table,
 // ignoring quest-locked entries. Returns nil if no eligible ability item exists.
-func RollAbilityItem(table *LootTableDef, floor int) *LootResult {
+func RollAbilityItem(table *LootTableDef, floor int, rng *rand.Rand) *LootResult {
 	if table == nil {
 		return nil
 	}
@@ -130,20 +158,20 @@ func RollAbilityItem(table *LootTableDef, floor int) *LootResult {
 	if len(pool) == 0 {
 		return nil
 	}
-	r := rand.Float64() * total
+	r := randFloat(rng) * total
 	for _, c := range pool {
 		r -= c.weight
 		if r <= 0 {
-			return &LootResult{ItemID: c.entry.ItemID, Count: 1}
+			return rolled(c.entry, floor, rng)
 		}
 	}
-	return &LootResult{ItemID: pool[len(pool)-1].entry.ItemID, Count: 1}
+	return rolled(pool[len(pool)-1].entry, floor, rng)
 }
 
 // RollChestLoot rolls loot for a chest based on its variant.
 // Wooden chests roll normal loot. Iron chests bias toward uncommon+. Gold/locked
 // chests guarantee ability items when possible, falling back to normal loot.
-func RollChestLoot(table *LootTableDef, variant string, floor int) []*LootResult {
+func RollChestLoot(table *LootTableDef, variant string, floor int, rng *rand.Rand) []*LootResult {
 	if table == nil {
 		return nil
 	}
@@ -151,19 +179,19 @@ func RollChestLoot(table *LootTableDef, variant string, floor int) []*LootResult
 	case "gold", "locked":
 		// Try for an ability item first; always produce at least one drop.
 		var results []*LootResult
-		if r := RollAbilityItem(table, floor); r != nil {
+		if r := RollAbilityItem(table, floor, rng); r != nil {
 			results = append(results, r)
 		}
 		// Second roll: normal loot (bonus drop for premium chests).
-		if r := RollLoot(table, floor); r != nil {
+		if r := RollLoot(table, floor, rng); r != nil {
 			results = append(results, r)
 		}
 		return results
 	case "iron":
 		// Bias toward uncommon/rare by re-rolling once and keeping the
 		// result with higher rarity weight.
-		r1 := RollLoot(table, floor)
-		r2 := RollLoot(table, floor)
+		r1 := RollLoot(table, floor, rng)
+		r2 := RollLoot(table, floor, rng)
 		if r1 == nil {
 			return nil
 		}
@@ -176,7 +204,7 @@ func RollChestLoot(table *LootTableDef, variant string, floor int) []*LootResult
 		}
 		return []*LootResult{r1}
 	default: // wooden
-		if r := RollLoot(table, floor); r != nil {
+		if r := RollLoot(table, floor, rng); r != nil {
 			return []*LootResult{r}
 		}
 		return nil
@@ -189,16 +217,7 @@ func rarityRank(table *LootTableDef, itemID string) int {
 	if !ok {
 		return 0
 	}
-	switch tmpl.Quality {
-	case RarityLegendary:
-		return 3
-	case RarityRare:
-		return 2
-	case RarityUncommon:
-		return 1
-	default:
-		return 0
-	}
+	return rarityTier(tmpl.Quality)
 }
 
 // BuildDefaultLootTable creates a generic loot table from all items in the
@@ -211,7 +230,7 @@ func BuildDefaultLootTable(biomeID string) *LootTableDef {
 		}
 		table.Entries = append(table.Entries, LootEntry{
 			ItemID:   id,
-			Weight:   1.0,
+			Weight:   dropWeight(id),
 			MinFloor: 1,
 			Rarity:   RarityCommon,
 		})
@@ -11,6 +11,9 @@ func RegisterItem(tmpl *ItemTemplate) {
 // NewItem creates an item instance from a template ID.
 func NewItem(id string) *Item {
 	tmpl, ok := Registry[id]
+	if !ok {
+		tmpl, ok = registerKey(id)
+	}
 	if !ok {
 		panic("Invalid item ID: " + id)
 	}
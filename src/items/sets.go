@@ -0,0 +1,139 @@
+package items
+
+import "sort"
+
+// SetBonus is what wearing enough pieces of an item set grants.
+type SetBonus struct {
+	Pieces        int             // distinct members worn to unlock it
+	Stats         map[string]int  // same keys as ItemTemplate.Stats
+	GrantsAbility string          // ability ID, as on ItemTemplate
+	AbilitySlot   AbilitySlotType // where the ability goes
+	Effect        *ItemEffect     // proc, as on ItemTemplate
+}
+
+// ItemSet groups items whose bonuses grow with the pieces worn together.
+type ItemSet struct {
+	ID      string
+	Name    string
+	Members []string   // item IDs, in display order
+	Bonuses []SetBonus // by Pieces, fewest first
+}
+
+// Sets holds all registered item sets keyed by ID.
+var Sets = map[string]*ItemSet{}
+
+// RegisterSet adds a set to the registry and marks its member templates
+// with the set's ID. Members must already be registered.
+func RegisterSet(s *ItemSet) {
+	Sets[s.ID] = s
+	for _, id := range s.Members {
+		if tmpl, ok := Registry[id]; ok {
+			tmpl.SetID = s.ID
+		}
+	}
+}
+
+// ActiveBonuses returns the bonuses unlocked by wearing worn distinct pieces
+// of s.
+func (s *ItemSet) ActiveBonuses(worn int) []SetBonus {
+	var active []SetBonus
+	for _, b := range s.Bonuses {
+		if worn >= b.Pieces {
+			active = append(active, b)
+		}
+	}
+	return active
+}
+
+// SetPieces counts, for each set, how many distinct members appear in ids.
+func SetPieces(ids []string) map[string]int {
+	seen := map[string]bool{}
+	pieces := map[string]int{}
+	for _, id := range ids {
+		tmpl, ok := Registry[id]
+		if !ok || tmpl.SetID == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		pieces[tmpl.SetID]++
+	}
+	return pieces
+}
+
+// ActiveSetBonuses returns every set bonus unlocked by wearing the items in
+// ids, sets in ID order.
+func ActiveSetBonuses(ids []string) []SetBonus {
+	pieces := SetPieces(ids)
+	setIDs := make([]string, 0, len(pieces))
+	for id := range pieces {
+		setIDs = append(setIDs, id)
+	}
+	sort.Strings(setIDs)
+	var active []SetBonus
+	for _, id := range setIDs {
+		if s, ok := Sets[id]; ok {
+			active = append(active, s.ActiveBonuses(pieces[id])...)
+		}
+	}
+	return active
+}
+
+// applyDefaultSets registers the bundled item sets. Called once after
+// LoadItemSheet so the members already exist in the registry.
+func applyDefaultSets() {
+	defaults := []*ItemSet{
+		{
+			ID: "crimson_oath", Name: "Crimson Oath",
+			Members: []string{"item_0_12", "item_0_39", "item_1_13", "item_1_39"}, // Cross Amulet, Ring, Gauntlets, Hauberk
+			Bonuses: []SetBonus{
+				{Pieces: 2, Stats: map[string]int{"Vitality": 3}},
+				{Pieces: 3, Effect: &ItemEffect{Trigger: TriggerOnHit, Type: EffectLifesteal, MagnitudePct: 20, ChancePct: 25}},
+				{Pieces: 4, Stats: map[string]int{"Strength": 4, "Vitality": 4}},
+			},
+		},
+		{
+			ID: "chaos", Name: "Chaos Incarnate",
+			Members: []string{"item_0_3", "item_1_25", "item_1_26"}, // Emblem, Jerkin, Matter
+			Bonuses: []SetBonus{
+				{Pieces: 2, Stats: map[string]int{"Intelligence": 3}},
+				{Pieces: 3, Effect: &ItemEffect{Trigger: TriggerOnCast, Type: EffectChainLightning, MagnitudePct: 100, ChancePct: 30}},
+			},
+		},
+		{
+			ID: "verdant", Name: "Verdant Circle",
+			Members: []string{"item_0_14", "item_0_38", "item_0_41", "item_2_5"}, // Skull Necklace, Ring, Bell, Stone
+			Bonuses: []SetBonus{
+				{Pieces: 2, Stats: map[string]int{"Vitality": 2, "Luck": 2}},
+				{Pieces: 3, Effect: &ItemEffect{Trigger: TriggerOnKill, Type: EffectLifesteal, MagnitudePct: 10}},
+				{Pieces: 4, GrantsAbility: "fractal_bloom", AbilitySlot: AbilitySlotSpell},
+			},
+		},
+		{
+			ID: "ironclad", Name: "Ironclad",
+			Members: []string{"item_0_8", "item_0_16", "item_1_40", "item_1_41"}, // Amulet, Skull Necklace, Slab, Jerkin
+			Bonuses: []SetBonus{
+				{Pieces: 2, Stats: map[string]int{"Vitality": 3}},
+				{Pieces: 3, Effect: &ItemEffect{Trigger: TriggerOnDamaged, Type: EffectThorns, MagnitudePct: 25}},
+				{Pieces: 4, Stats: map[string]int{"Strength": 3, "Dexterity": 2}},
+			},
+		},
+		{
+			ID: "stormweave", Name: "Stormweave",
+			Members: []string{"item_0_63", "item_1_36", "item_1_53"}, // Boots of Speed, Spellwoven Robe, Starwoven Vestment
+			Bonuses: []SetBonus{
+				{Pieces: 2, Effect: &ItemEffect{Trigger: TriggerOnCast, Type: EffectManaRefund, MagnitudePct: 50, ChancePct: 25}},
+				{Pieces: 3, Effect: &ItemEffect{Trigger: TriggerOnDash, Type: EffectChainLightning, MagnitudePct: 100, ChancePct: 50}},
+			},
+		},
+		{
+			ID: "buried_flame", Name: "Buried Flame",
+			Members: []string{"item_1_12", "item_2_0"}, // Grips, Thornwoven Wrap
+			Bonuses: []SetBonus{
+				{Pieces: 2, Effect: &ItemEffect{Trigger: TriggerOnHit, Type: EffectBurn, MagnitudePct: 25, ChancePct: 20}},
+			},
+		},
+	}
+	for _, s := range defaults {
+		RegisterSet(s)
+	}
+}
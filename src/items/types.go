@@ -37,7 +37,7 @@ type ItemTemplate struct {
 	Stats       map[string]int
 	Effect      *ItemEffect
 	Icon        *ebiten.Image
-	OnUse       func(p interface{})
+	OnUse       func(ctx UseContext) bool // consumables; reports whether it was used up
 	OnEquip     func(p interface{})
 	OnUnequip   func(p interface{})
 
@@ -52,7 +52,9 @@ type ItemTemplate struct {
 // Item represents an inventory instance.
 type Item struct {
 	*ItemTemplate
-	Count int
+	Count        int
+	Affixes      []ItemAffix // rolled when the item dropped
+	Unidentified bool        // affixes stay hidden and inactive until identified
 }
 
 // ItemEffect describes a special effect an item grants.
@@ -63,21 +65,43 @@ type ItemEffect struct {
 	ChancePct    int
 }
 
+// Triggers the game fires item effects on.
+const (
+	TriggerOnHit     = "on_hit"     // the player damages a monster
+	TriggerOnKill    = "on_kill"    // the player kills a monster
+	TriggerOnDamaged = "on_damaged" // a monster damages the player
+	TriggerOnCast    = "on_cast"    // the player casts a spell
+	TriggerOnDash    = "on_dash"    // the player dashes or blinks
+)
+
+// Effect types the game knows how to apply when a trigger fires.
+const (
+	EffectLifesteal      = "lifesteal"       // heals the player
+	EffectChainLightning = "chain_lightning" // arcs to monsters nearby
+	EffectBurn           = "burn"            // sets the monster hit alight
+	EffectManaRefund     = "mana_refund"     // gives mana back
+	EffectThorns         = "thorns"          // hurts the monster that struck
+)
+
 // ItemSave is a minimal representation for serialization.
 type ItemSave struct {
-	ID    string
-	Count int
+	ID           string
+	Count        int
+	Affixes      []ItemAffix `json:",omitempty"`
+	Unidentified bool        `json:",omitempty"`
 }
 
 // ToSave converts an item instance to its save form.
 func (i *Item) ToSave() ItemSave {
-	return ItemSave{ID: i.ID, Count: i.Count}
+	return ItemSave{ID: i.ID, Count: i.Count, Affixes: i.Affixes, Unidentified: i.Unidentified}
 }
 
 // FromSave recreates an item from saved data.
 func FromSave(data ItemSave) *Item {
 	it := NewItem(data.ID)
 	it.Count = data.Count
+	it.Affixes = data.Affixes
+	it.Unidentified = data.Unidentified
 	return it
 }
 
@@ -0,0 +1,387 @@
+// Package levelcheck measures generated levels and checks the invariants the
+// generator is supposed to guarantee. The generator repairs most problems on
+// its own (ensureConnectivity, pruneDeadEnds, throat validation); this package
+// makes the cases it misses visible instead of leaving them to be found in play.
+package levelcheck
+
+import (
+	"dungeoneer/levels"
+	"dungeoneer/tiles"
+	"fmt"
+	"image"
+	"sort"
+	"strings"
+)
+
+// Options tunes which measurements count as violations.
+type Options struct {
+	MinCoverage float64 // walkable ratio below this is a violation
+	MaxCoverage float64 // walkable ratio above this is a violation
+	MaxDeadEnds int     // dead-end tiles above this are a violation; < 0 disables
+	// ThroatSpacing is how close (Chebyshev) two doors may be before they are
+	// counted as guarding the same throat.
+	ThroatSpacing int
+}
+
+// DefaultOptions matches the ranges Generate64x64 is tuned for.
+func DefaultOptions() Options {
+	return Options{
+		MinCoverage:   0.25,
+		MaxCoverage:   0.70,
+		MaxDeadEnds:   -1,
+		ThroatSpacing: levels.DefaultDoorDensityConfig().MinThroatSpacing,
+	}
+}
+
+// Report is the result of checking a single level.
+type Report struct {
+	Coverage       float64
+	Components     int // passable regions, doors counted as passable
+	DeadEnds       int
+	Doors          int
+	LockedDoors    int
+	Throats        int         // groups of doors guarding the same passage
+	DoorsPerThroat map[int]int // doors in a throat -> number of such throats
+	MisplacedDoors []image.Point
+	SpawnX, SpawnY int
+	ExitX, ExitY   int
+	ExitLocked     bool          // the exit cannot be reached with the keys on the floor
+	Keyless        []image.Point // locked doors whose key is never reached
+	Unreachable    []int
+	TagCounts      map[levels.RoomTag]int
+
+	Violations []string
+}
+
+// OK reports whether the level passed every check.
+func (r *Report) OK() bool {
+	return len(r.Violations) == 0
+}
+
+func (r *Report) violate(format string, args ...any) {
+	r.Violations = append(r.Violations, fmt.Sprintf(format, args...))
+}
+
+// String renders the metrics on one line, followed by any violations.
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "coverage %.2f, %d component(s), %d dead end(s), %d door(s) (%d locked) in %d throat(s), tags %s",
+		r.Coverage, r.Components, r.DeadEnds, r.Doors, r.LockedDoors, r.Throats, formatTags(r.TagCounts))
+	for _, v := range r.Violations {
+		sb.WriteString("\n  FAIL ")
+		sb.WriteString(v)
+	}
+	return sb.String()
+}
+
+func formatTags(counts map[levels.RoomTag]int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(counts))
+	for tag := range counts {
+		names = append(names, string(tag))
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, n := range names {
+		parts[i] = fmt.Sprintf("%s=%d", n, counts[levels.RoomTag(n)])
+	}
+	return strings.Join(parts, " ")
+}
+
+var orth = []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+// Check measures l and records every invariant it breaks. Spawn and exit are
+// placed with levels.FindSpawnAndExit, as the game does; room tags are read
+// as they are, so run levels.TagRooms first to check the tag distribution.
+func Check(l *levels.Level, opts Options) *Report {
+	r := &Report{
+		DoorsPerThroat: map[int]int{},
+		TagCounts:      map[levels.RoomTag]int{},
+	}
+	if l == nil || l.W == 0 || l.H == 0 {
+		r.violate("level is empty")
+		return r
+	}
+
+	walkable := 0
+	var doors []image.Point
+	for y := 0; y < l.H; y++ {
+		for x := 0; x < l.W; x++ {
+			t := l.Tile(x, y)
+			if t == nil {
+				continue
+			}
+			if t.HasTag(tiles.TagDoor) {
+				doors = append(doors, image.Pt(x, y))
+				if t.DoorState == 3 {
+					r.LockedDoors++
+				}
+			}
+			if l.IsPassable(x, y) {
+				walkable++
+				if !t.HasTag(tiles.TagGrappleAnchor) && passableNeighbours(l, x, y) <= 1 {
+					r.DeadEnds++
+				}
+			}
+		}
+	}
+	r.Doors = len(doors)
+	r.Coverage = float64(walkable) / float64(l.W*l.H)
+	if r.Coverage < opts.MinCoverage || r.Coverage > opts.MaxCoverage {
+		r.violate("coverage %.2f outside [%.2f, %.2f]", r.Coverage, opts.MinCoverage, opts.MaxCoverage)
+	}
+	if opts.MaxDeadEnds >= 0 && r.DeadEnds > opts.MaxDeadEnds {
+		r.violate("%d dead-end tiles (max %d)", r.DeadEnds, opts.MaxDeadEnds)
+	}
+
+	// Connectivity, doors treated as passable. Secret rooms are sealed off
+	// until their cracked wall breaks, so they don't count as regions.
+	secret := secretMask(l)
+	comp := label(l, func(x, y int) bool { return l.IsPassable(x, y) && !secret[y][x] })
+	r.Components = comp.count
+	if r.Components > 1 {
+		r.violate("%d disconnected regions", r.Components)
+	}
+
+	// Doors: each should sit in a one-tile throat, one door per throat.
+	for _, d := range doors {
+		if !inThroat(l, d.X, d.Y) {
+			r.MisplacedDoors = append(r.MisplacedDoors, d)
+			r.violate("door at (%d,%d) is not in a throat", d.X, d.Y)
+		}
+	}
+	for _, group := range groupDoors(doors, opts.ThroatSpacing) {
+		r.Throats++
+		r.DoorsPerThroat[len(group)]++
+		if len(group) > 1 {
+			r.violate("%d doors share the throat at (%d,%d)", len(group), group[0].X, group[0].Y)
+		}
+	}
+
+	// Spawn, exit and what stands between them.
+	r.SpawnX, r.SpawnY, r.ExitX, r.ExitY = levels.FindSpawnAndExit(l)
+	if r.SpawnX == r.ExitX && r.SpawnY == r.ExitY {
+		r.violate("spawn and exit share tile (%d,%d)", r.SpawnX, r.SpawnY)
+	}
+	fromSpawn := reach(l, r.SpawnX, r.SpawnY, l.IsPassable)
+	if !fromSpawn[r.ExitY][r.ExitX] {
+		r.violate("exit (%d,%d) unreachable from spawn (%d,%d)", r.ExitX, r.ExitY, r.SpawnX, r.SpawnY)
+	} else {
+		unlocked := solveLocks(l, r.SpawnX, r.SpawnY)
+		if !unlocked[r.ExitY][r.ExitX] {
+			r.ExitLocked = true
+			r.violate("locked doors block every path from spawn to exit")
+		}
+		for _, lk := range l.Locks {
+			if !unlocked[lk.Key.Y][lk.Key.X] {
+				r.Keyless = append(r.Keyless, image.Pt(lk.Door.X, lk.Door.Y))
+				r.violate("key for lock %d at (%d,%d) cannot be reached", lk.ID, lk.Door.X, lk.Door.Y)
+			}
+		}
+	}
+
+	// Rooms.
+	for i := range l.Rooms {
+		room := &l.Rooms[i]
+		r.TagCounts[room.PrimaryTag()]++
+		if !room.Secret && !roomReached(room, fromSpawn) {
+			r.Unreachable = append(r.Unreachable, room.Index)
+			r.violate("room %d at (%d,%d) unreachable from spawn", room.Index, room.X, room.Y)
+		}
+	}
+	if len(l.Rooms) > 0 && hasTags(l.Rooms) {
+		for _, tag := range []levels.RoomTag{levels.TagSpawn, levels.TagExit} {
+			if n := len(levels.RoomsByTag(l.Rooms, tag)); n > 1 {
+				r.violate("%d rooms tagged %s", n, tag)
+			}
+		}
+	}
+	return r
+}
+
+func passableNeighbours(l *levels.Level, x, y int) int {
+	n := 0
+	for _, d := range orth {
+		if l.IsPassable(x+d.X, y+d.Y) {
+			n++
+		}
+	}
+	return n
+}
+
+// inThroat reports whether (x, y) passes through along exactly one axis, the
+// same shape levels.BuildThroatDebug accepts as a throat.
+func inThroat(l *levels.Level, x, y int) bool {
+	ns := l.IsPassable(x, y-1) && l.IsPassable(x, y+1)
+	ew := l.IsPassable(x-1, y) && l.IsPassable(x+1, y)
+	return ns != ew
+}
+
+// groupDoors clusters doors lying within spacing tiles of each other.
+func groupDoors(doors []image.Point, spacing int) [][]image.Point {
+	if spacing < 1 {
+		spacing = 1
+	}
+	seen := make([]bool, len(doors))
+	var groups [][]image.Point
+	for i := range doors {
+		if seen[i] {
+			continue
+		}
+		seen[i] = true
+		group := []image.Point{doors[i]}
+		for k := 0; k < len(group); k++ {
+			for j := range doors {
+				if seen[j] {
+					continue
+				}
+				dx, dy := abs(group[k].X-doors[j].X), abs(group[k].Y-doors[j].Y)
+				if dx <= spacing && dy <= spacing {
+					seen[j] = true
+					group = append(group, doors[j])
+				}
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+type labels struct {
+	ids   [][]int
+	count int
+}
+
+// label assigns a component id (from 1) to every passable tile.
+func label(l *levels.Level, passable func(x, y int) bool) labels {
+	lb := labels{ids: make([][]int, l.H)}
+	for y := range lb.ids {
+		lb.ids[y] = make([]int, l.W)
+	}
+	for y := 0; y < l.H; y++ {
+		for x := 0; x < l.W; x++ {
+			if lb.ids[y][x] != 0 || !passable(x, y) {
+				continue
+			}
+			lb.count++
+			for _, p := range flood(l, x, y, passable) {
+				lb.ids[p.Y][p.X] = lb.count
+			}
+		}
+	}
+	return lb
+}
+
+// solveLocks plays the floor's keys from (sx, sy): it floods without crossing
+// locked doors, opens every door whose key was reached and repeats until no
+// new door opens. Locked doors without a Lock record never open.
+func solveLocks(l *levels.Level, sx, sy int) [][]bool {
+	opened := map[image.Point]bool{}
+	for {
+		mask := reach(l, sx, sy, func(x, y int) bool {
+			t := l.Tile(x, y)
+			if t.HasTag(tiles.TagDoor) && t.DoorState == 3 {
+				return opened[image.Pt(x, y)]
+			}
+			return l.IsPassable(x, y)
+		})
+		progress := false
+		for _, lk := range l.Locks {
+			door := image.Pt(lk.Door.X, lk.Door.Y)
+			if !opened[door] && mask[lk.Key.Y][lk.Key.X] {
+				opened[door] = true
+				progress = true
+			}
+		}
+		if !progress {
+			return mask
+		}
+	}
+}
+
+// reach returns a mask of tiles reachable from (sx, sy).
+func reach(l *levels.Level, sx, sy int, passable func(x, y int) bool) [][]bool {
+	mask := make([][]bool, l.H)
+	for y := range mask {
+		mask[y] = make([]bool, l.W)
+	}
+	for _, p := range flood(l, sx, sy, passable) {
+		mask[p.Y][p.X] = true
+	}
+	return mask
+}
+
+func flood(l *levels.Level, sx, sy int, passable func(x, y int) bool) []image.Point {
+	if !passable(sx, sy) {
+		return nil
+	}
+	visited := make([][]bool, l.H)
+	for y := range visited {
+		visited[y] = make([]bool, l.W)
+	}
+	visited[sy][sx] = true
+	out := []image.Point{{sx, sy}}
+	for i := 0; i < len(out); i++ {
+		p := out[i]
+		for _, d := range orth {
+			nx, ny := p.X+d.X, p.Y+d.Y
+			if nx < 0 || ny < 0 || nx >= l.W || ny >= l.H || visited[ny][nx] || !passable(nx, ny) {
+				continue
+			}
+			visited[ny][nx] = true
+			out = append(out, image.Pt(nx, ny))
+		}
+	}
+	return out
+}
+
+// secretMask marks the tiles of secret rooms.
+func secretMask(l *levels.Level) [][]bool {
+	mask := make([][]bool, l.H)
+	for y := range mask {
+		mask[y] = make([]bool, l.W)
+	}
+	for _, room := range l.Rooms {
+		if !room.Secret {
+			continue
+		}
+		for y := max(room.Y, 0); y < room.Y+room.H && y < l.H; y++ {
+			for x := max(room.X, 0); x < room.X+room.W && x < l.W; x++ {
+				mask[y][x] = true
+			}
+		}
+	}
+	return mask
+}
+
+func roomReached(room *levels.Room, mask [][]bool) bool {
+	for y := room.Y; y < room.Y+room.H && y < len(mask); y++ {
+		if y < 0 {
+			continue
+		}
+		for x := room.X; x < room.X+room.W && x < len(mask[y]); x++ {
+			if x >= 0 && mask[y][x] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasTags(rooms []levels.Room) bool {
+	for _, r := range rooms {
+		if len(r.Tags) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -162,7 +162,7 @@ func (ep *EntitiesPalette) Draw(screen *ebiten.Image) {
 	// Draw entities on current page
 	for i := start; i < end; i++ {
 		id := ep.Entries[i]
-		img := SpriteRegistry[id].Image
+		img := entityImage(id)
 		indexOnPage := i - start
 		col := indexOnPage % ep.columns
 		row := indexOnPage / ep.columns
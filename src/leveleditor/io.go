@@ -14,6 +14,7 @@ import (
 type SpriteMetadata struct {
 	Image      *ebiten.Image
 	IsWalkable bool
+	Hazard     uint8 // placing the sprite makes the tile this hazard
 }
 type TileData struct {
 	SpriteIndexes []int `json:"sprite_indexes"`
@@ -21,6 +22,8 @@ type TileData struct {
 	Tags          uint8 `json:"tags"`
 	DoorState     uint8 `json:"door_state"`
 	DoorSpriteID  string `json:"door_sprite_id"`
+	Hazard        uint8  `json:"hazard,omitempty"`
+	WallHP        uint8  `json:"wall_hp,omitempty"`
 }
 
 type LevelData struct {
@@ -31,6 +34,8 @@ type LevelData struct {
 	SpritePalette []string              `json:"sprite_palette"`
 	Entities      []levels.PlacedEntity `json:"entities"`
 	DoorDensity   levels.DoorDensityConfig `json:"door_density,omitempty"`
+	Rooms         []levels.Room            `json:"rooms,omitempty"`
+	Locks         []levels.Lock            `json:"locks,omitempty"`
 }
 
 var SpriteRegistry = map[string]SpriteMetadata{}
@@ -804,6 +809,27 @@ func RegisterSprites(ss *sprites.SpriteSheet) {
 			Image:      ss.Trap,
 			IsWalkable: true,
 		},
+		// Hazards: walkable tiles that hurt whoever stands on them.
+		"LavaPool": {
+			Image:      ss.Lava,
+			IsWalkable: true,
+			Hazard:     tiles.HazardLava,
+		},
+		"DeepWater": {
+			Image:      ss.Water,
+			IsWalkable: true,
+			Hazard:     tiles.HazardWater,
+		},
+		"Spikes": {
+			Image:      ss.FloorTrap,
+			IsWalkable: true,
+			Hazard:     tiles.HazardSpikes,
+		},
+		"PoisonGas": {
+			Image:      ss.PosionBurst,
+			IsWalkable: true,
+			Hazard:     tiles.HazardGas,
+		},
 	}
 
 	// Build reverse lookup
@@ -833,6 +859,8 @@ func ConvertToLevelData(level *levels.Level) *LevelData {
 		SpritePalette: palette,
 		Entities:      level.Entities,
 		DoorDensity:   level.DoorDensity,
+		Rooms:         level.Rooms,
+		Locks:         level.Locks,
 	}
 
 	for y := 0; y < level.H; y++ {
@@ -852,6 +880,8 @@ func ConvertToLevelData(level *levels.Level) *LevelData {
 				Tags:          t.Tags,
 				DoorState:     t.DoorState,
 				DoorSpriteID:  t.DoorSpriteID,
+				Hazard:        t.Hazard,
+				WallHP:        t.WallHP,
 			}
 		}
 	}
@@ -868,6 +898,8 @@ func ConvertToLevel(data *LevelData) *levels.Level {
 		TileSize: data.TileSize,
 		Tiles:    make([][]*tiles.Tile, data.Height),
 		Entities: data.Entities,
+		Rooms:    data.Rooms,
+		Locks:    data.Locks,
 	}
 	if data.DoorDensity.RoomCorridorChance == 0 && data.DoorDensity.RoomRoomChance == 0 &&
 		data.DoorDensity.MaxDoorsPerRoom == 0 && data.DoorDensity.MinThroatSpacing == 0 {
@@ -885,6 +917,8 @@ func ConvertToLevel(data *LevelData) *levels.Level {
 				Tags:       td.Tags,
 				DoorState:  td.DoorState,
 				DoorSpriteID: td.DoorSpriteID,
+				Hazard:     td.Hazard,
+				WallHP:     td.WallHP,
 			}
 
 			for _, index := range td.SpriteIndexes {
@@ -39,6 +39,7 @@ type Editor struct {
 	JustSelectedEntity bool
 	SelectedEntityID   string
 	EntityMode         EditorMode
+	Wire               int // wire given to newly placed traps; see CycleWire
 	spawnerButtonRect  image.Rectangle
 	deleteButtonRect   image.Rectangle
 	clearButtonRect    image.Rectangle
@@ -111,6 +112,7 @@ func NewEditor(level *levels.Level, screenWidth, screenHeight int) *Editor {
 		"Caveman",
 		"RockCollector",
 		"RedMan"}
+	entries = append(entries, trapEntries()...)
 	editor.EntitiesPalette = NewEntitiesPalette(screenWidth, screenHeight, entries, editor.SetSelectedEntity)
 
 	return editor
@@ -212,6 +214,12 @@ func (e *Editor) Update(screenToTile func() (int, int)) {
 	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
 		e.ToggleEntityPalette()
 	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) {
+		e.CycleWire(-1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) {
+		e.CycleWire(1)
+	}
 
 	// Handle mode button clicks
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
@@ -292,6 +300,9 @@ func (e *Editor) PlaceSelectedSpriteAt(tx, ty int) {
 
 	tile.AddSpriteByID(id, meta.Image)
 	tile.IsWalkable = meta.IsWalkable
+	if meta.Hazard != tiles.HazardNone {
+		tile.Hazard = meta.Hazard
+	}
 	// If placing a door sprite, enforce door state and clear other door sprites.
 	if isDoorSpriteID(id) {
 		// Remove any existing door sprites so there is only one active state.
@@ -350,22 +361,17 @@ func (e *Editor) PlaceSelectedEntityAt(tx, ty int) {
 	}
 
 	// remove existing entity on this tile if any
+	ent := e.placedEntityFor(e.SelectedEntityID, tx, ty)
 	replaced := false
-	for i, ent := range e.level.Entities {
-		if ent.X == tx && ent.Y == ty {
-			e.level.Entities[i].Type = "AmbushMonster"
-			e.level.Entities[i].SpriteID = e.SelectedEntityID
+	for i, old := range e.level.Entities {
+		if old.X == tx && old.Y == ty {
+			e.level.Entities[i] = ent
 			replaced = true
 			break
 		}
 	}
 	if !replaced {
-		e.level.Entities = append(e.level.Entities, levels.PlacedEntity{
-			X:        tx,
-			Y:        ty,
-			Type:     "AmbushMonster",
-			SpriteID: e.SelectedEntityID,
-		})
+		e.level.Entities = append(e.level.Entities, ent)
 	}
 }
 
@@ -495,6 +501,13 @@ func (e *Editor) DrawModeButtons(screen *ebiten.Image) {
 		ebitenutil.DebugPrintAt(screen, "Clear", clearRect.Min.X+30, clearRect.Min.Y+4)
 	}
 
+	// Wire given to newly placed traps.
+	wireLabel := "Wire: none"
+	if e.Wire != 0 {
+		wireLabel = fmt.Sprintf("Wire: %d", e.Wire)
+	}
+	ebitenutil.DebugPrintAt(screen, wireLabel+"  (-/=)", clearRect.Max.X+10, clearRect.Min.Y+4)
+
 	// Store button rects on editor for click detection in Update
 	e.spawnerButtonRect = spawnerRect
 	e.deleteButtonRect = deleteRect
@@ -0,0 +1,68 @@
+package leveleditor
+
+import (
+	"strings"
+
+	"dungeoneer/levels"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// trapEntryPrefix marks entity palette entries that place a trap; the rest of
+// the entry is the trap kind.
+const trapEntryPrefix = "Trap:"
+
+// maxWire is the highest wire the editor cycles through.
+const maxWire = 9
+
+// TrapSprites maps each trap kind to the sprite shown for it in the entity
+// palette and on the floor once it has gone off.
+var TrapSprites = map[string]string{
+	levels.TrapPlate:    "Trap",
+	levels.TrapDart:     "DragonStatue",
+	levels.TrapSpikes:   "FloorTrap",
+	levels.TrapCollapse: "SkullHex",
+	levels.TrapAlarm:    "Pentagram",
+}
+
+// trapEntries returns the entity palette entries for every trap kind.
+func trapEntries() []string {
+	out := make([]string, 0, len(levels.TrapKinds))
+	for _, kind := range levels.TrapKinds {
+		out = append(out, trapEntryPrefix+kind)
+	}
+	return out
+}
+
+// trapKind returns the trap kind of a palette entry, or false if the entry
+// places a monster.
+func trapKind(id string) (string, bool) {
+	if !strings.HasPrefix(id, trapEntryPrefix) {
+		return "", false
+	}
+	return id[len(trapEntryPrefix):], true
+}
+
+// entityImage returns the palette image for an entity palette entry.
+func entityImage(id string) *ebiten.Image {
+	if kind, ok := trapKind(id); ok {
+		id = TrapSprites[kind]
+	}
+	return SpriteRegistry[id].Image
+}
+
+// placedEntityFor builds the entity a palette entry places at (tx, ty). Traps
+// are put on the editor's current wire; plates and darts need one to do
+// anything, floor traps may share it to fire other traps as well.
+func (e *Editor) placedEntityFor(id string, tx, ty int) levels.PlacedEntity {
+	if kind, ok := trapKind(id); ok {
+		return levels.PlacedEntity{X: tx, Y: ty, Type: levels.EntityTrap, Trap: kind, Wire: e.Wire}
+	}
+	return levels.PlacedEntity{X: tx, Y: ty, Type: "AmbushMonster", SpriteID: id}
+}
+
+// CycleWire steps the wire given to newly placed traps, wrapping through
+// 0 (unwired) to maxWire.
+func (e *Editor) CycleWire(step int) {
+	e.Wire = (e.Wire + step + maxWire + 1) % (maxWire + 1)
+}
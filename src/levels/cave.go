@@ -0,0 +1,225 @@
+package levels
+
+import (
+	"math/rand/v2"
+)
+
+// Cave tuning. Smoothing uses the usual 4-5 rule; the first few passes also
+// fill tiles with no wall within two steps so large caverns break up into
+// chambers instead of one open field.
+const (
+	caveSmoothSteps = 5
+	caveOpenSteps   = 3
+	caveMinPocket   = 24 // walkable pockets smaller than this are filled in
+	caveMinRoomSide = 4
+	caveMaxAspect   = 2 // chambers are at most twice as long as they are wide
+)
+
+// GenerateCave builds an organic cavern floor with cellular automata. It takes
+// the same GenParams as Generate64x64: CoverageTarget steers how open the
+// caverns are, RoomCountMax caps the chambers and RoomWMin/RoomHMin set the
+// smallest chamber worth keeping.
+//
+// The open chambers become Rooms (the largest fully walkable rectangle in
+// each), so TagRooms, encounters, grapple anchors and throat door placement
+// work on caves exactly as on room-and-corridor floors.
+func GenerateCave(p GenParams) *Level {
+	p = applyGenDefaults(p)
+	currentParams = p
+	currentCenters = nil
+	roomMask, corridorMask = nil, nil
+	reservedMask = nil
+	rng = rand.New(rand.NewPCG(uint64(p.Seed), uint64(p.Seed^0xca7e)))
+
+	l := NewEmptyLevel(p.Width, p.Height)
+	l.DoorDensity = p.DoorDensity
+
+	cave := seedCave(p.Width, p.Height, caveWallChance(p.CoverageTarget), rng)
+	for i := 0; i < caveSmoothSteps; i++ {
+		cave = smoothCave(cave, i < caveOpenSteps)
+	}
+	for y := 0; y < l.H; y++ {
+		for x := 0; x < l.W; x++ {
+			l.Tiles[y][x].IsWalkable = cave[y][x]
+		}
+	}
+	fillSmallPockets(l, caveMinPocket)
+	ensureConnectivity(l)
+
+	rooms := findCaveChambers(l, p)
+	tagDashLanes(l, p.CorridorWidth, p.DashLaneMinLen)
+	placeGrappleAnchors(l, rooms, p.GrappleRange, rng)
+	pruneDeadEnds(l, 3)
+	ensureConnectivity(l)
+
+	l.Rooms = rectsToRooms(rooms)
+	stampPrefabs(l, p.Prefabs, rng)
+	ensureConnectivity(l)
+	sealWalkableEdges(l)
+	paintLevelSprites(l, p)
+	placeDoorsFromValidatedThroats(l, p)
+	carveSecretRooms(l, p, rng)
+	placeHazards(l, p.Hazards, rng)
+	buildLockGraph(l, rng)
+	placeTraps(l, p.Traps, rng)
+	return l
+}
+
+// caveWallChance maps the coverage target onto the initial wall density.
+// Smoothing amplifies small changes in density (0.50 settles near 0.41
+// walkable, 0.46 near 0.55), so the slope is shallow.
+func caveWallChance(coverage float64) float64 {
+	w := 0.5 - (coverage-0.41)*0.3
+	if w < 0.42 {
+		w = 0.42
+	}
+	if w > 0.54 {
+		w = 0.54
+	}
+	return w
+}
+
+// seedCave fills the interior with random noise; the border is always wall.
+func seedCave(w, h int, wallChance float64, rng *rand.Rand) [][]bool {
+	open := make([][]bool, h)
+	for y := range open {
+		open[y] = make([]bool, w)
+		for x := range open[y] {
+			if x == 0 || y == 0 || x == w-1 || y == h-1 {
+				continue
+			}
+			open[y][x] = rng.Float64() >= wallChance
+		}
+	}
+	return open
+}
+
+// smoothCave runs one cellular automata step. A tile becomes wall when five
+// or more of the nine tiles around it (itself included) are wall; with
+// breakUp set, tiles with no wall within two steps become wall as well.
+func smoothCave(open [][]bool, breakUp bool) [][]bool {
+	h := len(open)
+	w := len(open[0])
+	out := make([][]bool, h)
+	for y := range out {
+		out[y] = make([]bool, w)
+		if y == 0 || y == h-1 {
+			continue
+		}
+		for x := 1; x < w-1; x++ {
+			near := caveWallsWithin(open, x, y, 1)
+			wall := near >= 5
+			if breakUp && caveWallsWithin(open, x, y, 2) == 0 {
+				wall = true
+			}
+			out[y][x] = !wall
+		}
+	}
+	return out
+}
+
+// caveWallsWithin counts wall tiles in the square of radius r around (x, y);
+// anything off the map counts as wall.
+func caveWallsWithin(open [][]bool, x, y, r int) int {
+	n := 0
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			nx, ny := x+dx, y+dy
+			if ny < 0 || ny >= len(open) || nx < 0 || nx >= len(open[ny]) || !open[ny][nx] {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// fillSmallPockets turns walkable regions smaller than minSize back into
+// wall, so ensureConnectivity does not tunnel out to every speck of noise.
+// The largest region is always kept.
+func fillSmallPockets(L *Level, minSize int) {
+	comps := floodComponents(L)
+	largest := 0
+	for i, c := range comps {
+		if len(c) > len(comps[largest]) {
+			largest = i
+		}
+	}
+	for i, c := range comps {
+		if i == largest || len(c) >= minSize {
+			continue
+		}
+		for _, pt := range c {
+			L.Tiles[pt.Y][pt.X].IsWalkable = false
+		}
+	}
+}
+
+// findCaveChambers repeatedly takes the largest fully walkable rectangle not
+// yet claimed, until RoomCountMax chambers are found or what is left is
+// smaller than the minimum room. A one-tile margin around each claimed
+// rectangle keeps neighbouring chambers apart.
+func findCaveChambers(L *Level, p GenParams) []rect {
+	minArea := p.RoomWMin * p.RoomHMin / 2
+	claimed := make([][]bool, L.H)
+	for y := range claimed {
+		claimed[y] = make([]bool, L.W)
+	}
+	var rooms []rect
+	for len(rooms) < p.RoomCountMax {
+		r, ok := largestOpenRect(L, claimed)
+		if !ok || r.W < caveMinRoomSide || r.H < caveMinRoomSide || r.W*r.H < minArea {
+			break
+		}
+		rooms = append(rooms, r)
+		for y := r.Y - 1; y <= r.Y+r.H; y++ {
+			for x := r.X - 1; x <= r.X+r.W; x++ {
+				if inBounds(L, x, y) {
+					claimed[y][x] = true
+				}
+			}
+		}
+	}
+	return rooms
+}
+
+// largestOpenRect finds the largest rectangle of walkable, unclaimed tiles
+// using the row-histogram method. Candidates are trimmed to caveMaxAspect and
+// those thinner than caveMinRoomSide ignored, so long stretches of tunnel do
+// not count as chambers.
+func largestOpenRect(L *Level, claimed [][]bool) (rect, bool) {
+	heights := make([]int, L.W)
+	best, bestArea := rect{}, 0
+	for y := 0; y < L.H; y++ {
+		for x := 0; x < L.W; x++ {
+			if L.Tiles[y][x].IsWalkable && !claimed[y][x] {
+				heights[x]++
+			} else {
+				heights[x] = 0
+			}
+		}
+		// Largest rectangle under the histogram ending on row y.
+		var stack []int
+		for x := 0; x <= L.W; x++ {
+			cur := 0
+			if x < L.W {
+				cur = heights[x]
+			}
+			for len(stack) > 0 && heights[stack[len(stack)-1]] >= cur {
+				h := heights[stack[len(stack)-1]]
+				stack = stack[:len(stack)-1]
+				left := 0
+				if len(stack) > 0 {
+					left = stack[len(stack)-1] + 1
+				}
+				w := min(x-left, h*caveMaxAspect)
+				h = min(h, w*caveMaxAspect)
+				if w >= caveMinRoomSide && h >= caveMinRoomSide && w*h > bestArea {
+					bestArea = w * h
+					best = rect{X: left, Y: y - h + 1, W: w, H: h}
+				}
+			}
+			stack = append(stack, x)
+		}
+	}
+	return best, bestArea > 0
+}
@@ -31,6 +31,15 @@ type GenParams struct {
 	// Visual/theme
 	WallFlavor  string // e.g., "crypt", "moss", "normal"
 	FloorFlavor string // usually same list as wall flavors
+	// Prefabs are hand-authored rooms to stamp into the floor; see stampPrefabs.
+	Prefabs []*Prefab
+	// Hazards are pools of lava, water, etc. grown in rooms; see placeHazards.
+	Hazards []HazardSpec
+	// Traps is how many traps to scatter over the floor; see placeTraps.
+	Traps int
+	// SecretRoomChance is the chance of each secret room roll; see
+	// carveSecretRooms.
+	SecretRoomChance float64
 }
 
 type rect struct{ X, Y, W, H int }
@@ -44,6 +53,61 @@ var roomMask [][]bool
 var corridorMask [][]bool
 
 func Generate64x64(p GenParams) *Level {
+	p = applyGenDefaults(p)
+	currentParams = p
+	reservedMask = nil
+	rng = rand.New(rand.NewPCG(uint64(p.Seed), uint64(p.Seed^0xface)))
+
+	l := NewEmptyLevel(p.Width, p.Height)
+	l.DoorDensity = p.DoorDensity
+	fmt.Printf("DoorDensityConfig: %+v\n", l.DoorDensity)
+
+	depth := 3
+	if p.RoomCountMin > 8 {
+		depth = 4
+	}
+	regions := bspRegions(p.Width, p.Height, depth, rng)
+	centers := poissonInRegions(regions, p, rng)
+	currentCenters = centers
+	roomMask = make([][]bool, p.Height)
+	for y := range roomMask {
+		roomMask[y] = make([]bool, p.Width)
+	}
+	corridorMask = make([][]bool, p.Height)
+	for y := range corridorMask {
+		corridorMask[y] = make([]bool, p.Width)
+	}
+	rooms := growRooms(l, centers, p, rng)
+	edges := connectKNN(centers, 3)
+	edges = mstPlusExtras(edges, centers, p.Extras, rng)
+	carveCorridors(l, edges, p.CorridorWidth)
+	// Corridors must remain exactly 1 tile wide; no widening passes.
+	// Do not carve a perimeter loop; it creates a moat between walls and void.
+	optionalPerimeterLoop(l, p.CorridorWidth, corridorHalf(p.CorridorWidth), false)
+	tagDashLanes(l, p.CorridorWidth, p.DashLaneMinLen)
+	placeGrappleAnchors(l, rooms, p.GrappleRange, rng)
+	pruneDeadEnds(l, 3)
+	ensureConnectivity(l)     // Connectivity check ignores doors (treats them as walkable)
+	growToCoverage(l, p, rng) // NEW
+
+	// Populate Room metadata from carved rooms (primary + filler).
+	l.Rooms = rectsToRooms(rooms)
+	// Append filler rooms by scanning for walkable clusters not in primary rooms.
+	l.Rooms = append(l.Rooms, detectFillerRooms(l, l.Rooms)...)
+	stampPrefabs(l, p.Prefabs, rng)
+	ensureConnectivity(l)     // Ensure all filler rooms are connected
+	sealWalkableEdges(l)
+	paintLevelSprites(l, p)
+	placeDoorsFromValidatedThroats(l, p)
+	carveSecretRooms(l, p, rng)
+	placeHazards(l, p.Hazards, rng)
+	buildLockGraph(l, rng)
+	placeTraps(l, p.Traps, rng)
+	return l
+}
+
+// applyGenDefaults fills in every GenParams field left at its zero value.
+func applyGenDefaults(p GenParams) GenParams {
 	if p.Width == 0 {
 		p.Width = 64
 	}
@@ -104,52 +168,17 @@ func Generate64x64(p GenParams) *Level {
 	if p.FloorFlavor == "" {
 		p.FloorFlavor = "crypt"
 	}
+	return p
+}
 
-	currentParams = p
-	rng = rand.New(rand.NewPCG(uint64(p.Seed), uint64(p.Seed^0xface)))
-
-	l := NewEmptyLevel(p.Width, p.Height)
-	l.DoorDensity = p.DoorDensity
-	fmt.Printf("DoorDensityConfig: %+v\n", l.DoorDensity)
+// paintLevelSprites adds floor and wall sprites in the params' flavors. Walls
+// are only painted where they border walkable tiles; doors keep a floor
+// sprite under them.
+func paintLevelSprites(l *Level, p GenParams) {
 	ss, err := sprites.LoadSpriteSheet(constants.DefaultTileSize)
 	if err != nil {
 		ss = nil
 	}
-
-	depth := 3
-	if p.RoomCountMin > 8 {
-		depth = 4
-	}
-	regions := bspRegions(p.Width, p.Height, depth, rng)
-	centers := poissonInRegions(regions, p, rng)
-	currentCenters = centers
-	roomMask = make([][]bool, p.Height)
-	for y := range roomMask {
-		roomMask[y] = make([]bool, p.Width)
-	}
-	corridorMask = make([][]bool, p.Height)
-	for y := range corridorMask {
-		corridorMask[y] = make([]bool, p.Width)
-	}
-	rooms := growRooms(l, centers, p, rng)
-	edges := connectKNN(centers, 3)
-	edges = mstPlusExtras(edges, centers, p.Extras, rng)
-	carveCorridors(l, edges, p.CorridorWidth)
-	// Corridors must remain exactly 1 tile wide; no widening passes.
-	// Do not carve a perimeter loop; it creates a moat between walls and void.
-	optionalPerimeterLoop(l, p.CorridorWidth, corridorHalf(p.CorridorWidth), false)
-	tagDashLanes(l, p.CorridorWidth, p.DashLaneMinLen)
-	placeGrappleAnchors(l, rooms, p.GrappleRange, rng)
-	pruneDeadEnds(l, 3)
-	ensureConnectivity(l)     // Connectivity check ignores doors (treats them as walkable)
-	growToCoverage(l, p, rng) // NEW
-
-	// Populate Room metadata from carved rooms (primary + filler).
-	l.Rooms = rectsToRooms(rooms)
-	// Append filler rooms by scanning for walkable clusters not in primary rooms.
-	l.Rooms = append(l.Rooms, detectFillerRooms(l, l.Rooms)...)
-	ensureConnectivity(l)     // Ensure all filler rooms are connected
-	sealWalkableEdges(l)
 	if ss != nil {
 		wallMask := buildWallMask(l)
 		// Try to load flavored sheets; fall back to base if unavailable
@@ -175,6 +204,11 @@ func Generate64x64(p GenParams) *Level {
 				// If you have layer-aware clears, use them; otherwise this is fine.
 				// t.ClearSprites()
 
+				if isPrefabTile(x, y) && len(t.Sprites) > 0 {
+					// Authored prefab tiles keep their sprites over our floor.
+					t.Sprites = append([]tiles.SpriteRef{{ID: p.FloorFlavor + "_floor", Image: floorImg}}, t.Sprites...)
+					continue
+				}
 				if t.HasTag(tiles.TagDoor) {
 					// Doors should not get wall sprites; keep floor under the door.
 					t.AddSpriteByID(p.FloorFlavor+"_floor", floorImg)
@@ -188,8 +222,6 @@ func Generate64x64(p GenParams) *Level {
 			}
 		}
 	}
-	placeDoorsFromValidatedThroats(l, p)
-	return l
 }
 
 // rectsToRooms converts the raw rect slice from growRooms into Room metadata.
@@ -1488,6 +1520,9 @@ func placeDoorsFromValidatedThroats(L *Level, p GenParams) {
 	maxDoorsPerRoom := cfg.MaxDoorsPerRoom
 
 	for _, t := range chosen {
+		if isPrefabTile(t.X, t.Y) {
+			continue // prefabs bring their own doors
+		}
 		if hasAdjacentDoor(L, t.X, t.Y) {
 			continue
 		}
@@ -1865,6 +1900,16 @@ func ensureConnectivity(L *Level) {
 				}
 			}
 		}
+		// Tunnel around stamped prefabs rather than straight through them.
+		if reservedMask != nil {
+			inB := map[image.Point]bool{}
+			for _, q := range b {
+				inB[q] = true
+			}
+			if tunnelToWalkable(L, a, func(x, y int) bool { return inB[image.Pt(x, y)] }) {
+				continue
+			}
+		}
 		half := max(1, currentParams.CorridorWidth/2)
 		carveL(L, pa.X, pa.Y, pb.X, pb.Y, half)
 	}
@@ -0,0 +1,93 @@
+package levels
+
+import (
+	"image"
+	"math/rand/v2"
+
+	"dungeoneer/constants"
+	"dungeoneer/sprites"
+	"dungeoneer/tiles"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// HazardSpec asks the generator for Pools pools of a hazard, each grown to
+// about Size tiles.
+type HazardSpec struct {
+	Hazard uint8
+	Pools  int
+	Size   int
+}
+
+// hazardClearance keeps pools this many tiles (Chebyshev) from spawn and exit.
+const hazardClearance = 3
+
+// placeHazards grows the requested hazard pools inside generated rooms. Pools
+// stay off each room's outer ring, doors, entities and the area around spawn
+// and exit, so every room can still be crossed without stepping in one.
+// Hazards leave walkability alone; they only add sprites and path cost.
+func placeHazards(l *Level, specs []HazardSpec, rng *rand.Rand) {
+	if len(specs) == 0 || len(l.Rooms) == 0 {
+		return
+	}
+	imgs := hazardImages()
+	if imgs == nil {
+		return
+	}
+	sx, sy, ex, ey := FindSpawnAndExit(l)
+	blocked := map[image.Point]bool{}
+	for _, e := range l.Entities {
+		blocked[image.Pt(e.X, e.Y)] = true
+	}
+	ok := func(x, y int) bool {
+		t := l.Tile(x, y)
+		if t == nil || !t.IsWalkable || t.HasTag(tiles.TagDoor) || t.Hazard != tiles.HazardNone ||
+			isPrefabTile(x, y) || blocked[image.Pt(x, y)] {
+			return false
+		}
+		if abs(x-sx) <= hazardClearance && abs(y-sy) <= hazardClearance ||
+			abs(x-ex) <= hazardClearance && abs(y-ey) <= hazardClearance {
+			return false
+		}
+		return inRoomInterior(l, x, y)
+	}
+
+	for _, spec := range specs {
+		info, known := tiles.Hazards[spec.Hazard]
+		if !known {
+			continue
+		}
+		for i := 0; i < spec.Pools; i++ {
+			r := &l.Rooms[rng.IntN(len(l.Rooms))]
+			x, y := r.X+1+rng.IntN(max(1, r.W-2)), r.Y+1+rng.IntN(max(1, r.H-2))
+			// Random walk from the seed tile, marking each new tile it lands on.
+			for steps, painted := 0, 0; painted < spec.Size && steps < spec.Size*4; steps++ {
+				if ok(x, y) {
+					t := l.Tiles[y][x]
+					t.Hazard = spec.Hazard
+					t.AddSpriteByID(info.SpriteID, imgs[spec.Hazard])
+					painted++
+				}
+				d := [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}[rng.IntN(4)]
+				if nx, ny := x+d[0], y+d[1]; r.Contains(nx, ny) {
+					x, y = nx, ny
+				}
+			}
+		}
+	}
+}
+
+// hazardImages returns the sprite painted for each hazard type, or nil when
+// the sprite sheet cannot be loaded.
+func hazardImages() map[uint8]*ebiten.Image {
+	ss, err := sprites.LoadSpriteSheet(constants.DefaultTileSize)
+	if err != nil {
+		return nil
+	}
+	return map[uint8]*ebiten.Image{
+		tiles.HazardLava:   ss.Lava,
+		tiles.HazardWater:  ss.Water,
+		tiles.HazardSpikes: ss.FloorTrap,
+		tiles.HazardGas:    ss.PosionBurst,
+	}
+}
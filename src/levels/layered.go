@@ -1,5 +1,7 @@
 package levels
 
+import "dungeoneer/tiles"
+
 // Point represents tile coordinates within a level.
 type Point struct {
 	X int `json:"x"`
@@ -71,3 +73,66 @@ func (ll *LayeredLevel) RemoveLastLayer() {
 		ll.ActiveIndex = len(ll.Layers) - 1
 	}
 }
+
+// LinkLayers joins layer a and layer b with a two-way stairwell. Stepping on
+// from (on layer a) lands the player beside to (on layer b) and the other way
+// round; landing next to the stairs rather than on them keeps the player from
+// being sent straight back. fromSprite and toSprite name the stair sprites the
+// game paints on each end.
+func (ll *LayeredLevel) LinkLayers(a int, from Point, b int, to Point, fromSprite, toSprite string) {
+	if a < 0 || b < 0 || a >= len(ll.Layers) || b >= len(ll.Layers) {
+		return
+	}
+	ll.Stairwells = append(ll.Stairwells,
+		&LayerLink{FromLayerIndex: a, FromTile: from, ToLayerIndex: b, ToTile: StairLanding(ll.Layers[b], to), TriggerSprite: fromSprite},
+		&LayerLink{FromLayerIndex: b, FromTile: to, ToLayerIndex: a, ToTile: StairLanding(ll.Layers[a], from), TriggerSprite: toSprite},
+	)
+}
+
+// StairLanding returns a walkable tile next to the stairs at p, or p itself
+// when the stairs are boxed in.
+func StairLanding(l *Level, p Point) Point {
+	for _, d := range [4][2]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}} {
+		x, y := p.X+d[0], p.Y+d[1]
+		if t := l.Tile(x, y); t != nil && t.IsWalkable && !t.HasTag(tiles.TagDoor) && t.Hazard == tiles.HazardNone {
+			return Point{X: x, Y: y}
+		}
+	}
+	return p
+}
+
+// FindStairTile picks a spot for another stairwell: the walkable room tile
+// farthest from (sx, sy) that lies at least minDist tiles from every point in
+// avoid, is free of entities and has somewhere to land beside it.
+func FindStairTile(l *Level, sx, sy int, avoid []Point, minDist int) (Point, bool) {
+	dist := bfsDistMap(l, sx, sy, l.IsPassable)
+	occupied := map[Point]bool{}
+	for _, e := range l.Entities {
+		occupied[Point{X: e.X, Y: e.Y}] = true
+	}
+	best, bestD := Point{}, -1
+	for y := 0; y < l.H; y++ {
+		for x := 0; x < l.W; x++ {
+			p := Point{X: x, Y: y}
+			t := l.Tiles[y][x]
+			if dist[y][x] <= bestD || !t.IsWalkable || t.HasTag(tiles.TagDoor) || t.Hazard != tiles.HazardNone || occupied[p] || l.RoomAt(x, y) == nil {
+				continue
+			}
+			if StairLanding(l, p) == p {
+				continue
+			}
+			near := false
+			for _, a := range avoid {
+				dx, dy := x-a.X, y-a.Y
+				if dx*dx+dy*dy < minDist*minDist {
+					near = true
+					break
+				}
+			}
+			if !near {
+				best, bestD = p, dist[y][x]
+			}
+		}
+	}
+	return best, bestD >= 0
+}
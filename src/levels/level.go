@@ -17,6 +17,9 @@ type PlacedEntity struct {
 	X, Y     int
 	Type     string
 	SpriteID string
+	LockID   int    `json:",omitempty"` // for keys (EntityKey): the Lock they open
+	Trap     string `json:",omitempty"` // for traps (EntityTrap): the trap kind
+	Wire     int    `json:",omitempty"` // for traps: fires with every trap sharing it; 0 = none
 }
 
 // RoomSize classifies a room by area.
@@ -43,6 +46,7 @@ const (
 	TagAmbush     RoomTag = "ambush"
 	TagCrossroads RoomTag = "crossroads"
 	TagDeadEnd    RoomTag = "dead_end"
+	TagSecret     RoomTag = "secret"
 	TagCommon     RoomTag = "common"
 
 	// Modifier tags (stackable).
@@ -60,6 +64,11 @@ type Room struct {
 	Size              RoomSize
 	Index             int
 	Tags              []RoomTag
+	Prefab            string    // ID of the prefab stamped here; "" for generated rooms
+	PrefabTags        []RoomTag // tags from the prefab, restored by TagRooms
+	Lock              int       // ID of the lock that gates the room; 0 if reachable without keys
+	LockDepth         int       // keys needed to reach the room from spawn
+	Secret            bool      // carved behind a cracked wall; see carveSecretRooms
 }
 
 // Contains reports whether tile (tx, ty) falls inside the room.
@@ -129,6 +138,7 @@ type Level struct {
 	Entities    []PlacedEntity
 	DoorDensity DoorDensityConfig
 	Rooms       []Room
+	Locks       []Lock // lock/key graph built by the generators
 }
 
 // RoomAt returns the room containing tile (x, y), or nil if not in any room.
@@ -333,6 +343,12 @@ func (l Level) IsWalkable(x, y int) bool {
 	return t.IsWalkable
 }
 
+// IsSafe reports whether (x, y) is walkable and not a hazard, i.e. a tile
+// worth putting the player, an exit or a spawned entity on.
+func (l Level) IsSafe(x, y int) bool {
+	return l.IsWalkable(x, y) && l.Tiles[y][x].Hazard == tiles.HazardNone
+}
+
 // IsPassable returns true if a tile can be traversed, treating closed and
 // locked doors as passable. Used by spawn/exit placement so the BFS can
 // path through doors and place objectives behind them.
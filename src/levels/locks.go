@@ -0,0 +1,284 @@
+package levels
+
+import (
+	"image"
+	"math/rand/v2"
+	"sort"
+
+	"dungeoneer/tiles"
+)
+
+// EntityKey is the PlacedEntity type of a key; its LockID names the door.
+const EntityKey = "Key"
+
+// Lock is a locked door together with the key that opens it. Locks form a
+// dependency graph: each key lies where it can be reached from spawn by
+// opening only locks of lower Depth, so every locked door can be opened.
+type Lock struct {
+	ID       int   // 1-based, matches the key entity's LockID
+	Door     Point // the locked door tile
+	Key      Point // where the key lies
+	Depth    int   // locks that must be opened before reaching this door
+	Optional bool  // the exit can be reached without opening this door
+}
+
+// LockByDoor returns the lock on the door at (x, y), or nil.
+func (l *Level) LockByDoor(x, y int) *Lock {
+	for i := range l.Locks {
+		if l.Locks[i].Door.X == x && l.Locks[i].Door.Y == y {
+			return &l.Locks[i]
+		}
+	}
+	return nil
+}
+
+// LockByID returns the lock with the given ID, or nil.
+func (l *Level) LockByID(id int) *Lock {
+	for i := range l.Locks {
+		if l.Locks[i].ID == id {
+			return &l.Locks[i]
+		}
+	}
+	return nil
+}
+
+// OffsetLocks adds n to every lock ID on the level, and to the rooms and keys
+// that refer to them, so several layers of one floor never share a key.
+func (l *Level) OffsetLocks(n int) {
+	if n == 0 {
+		return
+	}
+	for i := range l.Locks {
+		l.Locks[i].ID += n
+	}
+	for i := range l.Rooms {
+		if l.Rooms[i].Lock != 0 {
+			l.Rooms[i].Lock += n
+		}
+	}
+	for i := range l.Entities {
+		if l.Entities[i].Type == EntityKey {
+			l.Entities[i].LockID += n
+		}
+	}
+}
+
+// buildLockGraph walks the floor from spawn the way a player would: flood
+// everything reachable without keys, pick a locked door on the edge of that
+// area, drop its key somewhere inside the area, open the door and repeat. The
+// keys become PlacedEntities and each room records the lock that gates it.
+// A door with no free tile left for its key is unlocked to a plain closed
+// door. Locked doors the walk never reaches are left without a key.
+func buildLockGraph(l *Level, rng *rand.Rand) {
+	l.Locks = nil
+	sx, sy, ex, ey := FindSpawnAndExit(l)
+
+	opened := map[image.Point]bool{}
+	gate := make([][]int, l.H) // lock ID that first let the walk reach a tile, 0 = none
+	depth := make([][]int, l.H)
+	for y := range gate {
+		gate[y] = make([]int, l.W)
+		depth[y] = make([]int, l.W)
+	}
+	reached := floodLocked(l, sx, sy, opened)
+	seen := map[image.Point]bool{}
+	for _, p := range reached {
+		seen[p] = true
+	}
+	taken := map[image.Point]bool{{X: sx, Y: sy}: true, {X: ex, Y: ey}: true}
+	for _, e := range l.Entities {
+		taken[image.Pt(e.X, e.Y)] = true
+	}
+
+	for {
+		frontier := lockedFrontier(l, reached, opened)
+		if len(frontier) == 0 {
+			break
+		}
+		door := frontier[rng.IntN(len(frontier))]
+		d := lockDoorDepth(l, door, seen, depth)
+		id, nd := lockDoorGate(door, seen, gate, depth), d
+		if key, ok := pickKeyTile(l, reached, taken, rng); ok {
+			taken[key] = true
+			lock := Lock{ID: len(l.Locks) + 1, Door: Point{X: door.X, Y: door.Y}, Key: Point{X: key.X, Y: key.Y}, Depth: d}
+			l.Locks = append(l.Locks, lock)
+			l.Entities = append(l.Entities, PlacedEntity{X: key.X, Y: key.Y, Type: EntityKey, LockID: lock.ID})
+			id, nd = lock.ID, d+1
+		} else {
+			// A locked door with no key would wall the rest of the
+			// floor off for good.
+			l.Tiles[door.Y][door.X].DoorState = 2
+		}
+
+		opened[door] = true
+		reached = floodLocked(l, sx, sy, opened)
+		for _, p := range reached {
+			if !seen[p] {
+				seen[p] = true
+				gate[p.Y][p.X] = id
+				depth[p.Y][p.X] = nd
+			}
+		}
+	}
+
+	// A lock is optional when the exit stays reachable with every other
+	// door open.
+	all := map[image.Point]bool{}
+	for _, lk := range l.Locks {
+		all[image.Pt(lk.Door.X, lk.Door.Y)] = true
+	}
+	for i := range l.Locks {
+		door := image.Pt(l.Locks[i].Door.X, l.Locks[i].Door.Y)
+		all[door] = false
+		for _, p := range floodLocked(l, sx, sy, all) {
+			if p.X == ex && p.Y == ey {
+				l.Locks[i].Optional = true
+				break
+			}
+		}
+		all[door] = true
+	}
+
+	for i := range l.Rooms {
+		r := &l.Rooms[i]
+		r.Lock, r.LockDepth = 0, 0
+		if x, y, ok := roomReachedTile(r, seen); ok {
+			r.Lock, r.LockDepth = gate[y][x], depth[y][x]
+		}
+	}
+}
+
+// Reachable returns every tile that can be walked to from (sx, sy), going
+// through doors but not locked ones or cracked walls.
+func Reachable(l *Level, sx, sy int) []image.Point {
+	return floodLocked(l, sx, sy, nil)
+}
+
+// floodLocked returns every tile reachable from (sx, sy) through walkable
+// tiles and doors, treating locked doors as walls unless opened.
+func floodLocked(l *Level, sx, sy int, opened map[image.Point]bool) []image.Point {
+	pass := func(x, y int) bool {
+		t := l.Tile(x, y)
+		if t == nil {
+			return false
+		}
+		if t.HasTag(tiles.TagDoor) {
+			return t.DoorState != 3 || opened[image.Pt(x, y)]
+		}
+		return t.IsWalkable
+	}
+	if !pass(sx, sy) {
+		return nil
+	}
+	visited := make([][]bool, l.H)
+	for y := range visited {
+		visited[y] = make([]bool, l.W)
+	}
+	visited[sy][sx] = true
+	out := []image.Point{{X: sx, Y: sy}}
+	for i := 0; i < len(out); i++ {
+		p := out[i]
+		for _, d := range []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nx, ny := p.X+d.X, p.Y+d.Y
+			if !inBounds(l, nx, ny) || visited[ny][nx] || !pass(nx, ny) {
+				continue
+			}
+			visited[ny][nx] = true
+			out = append(out, image.Pt(nx, ny))
+		}
+	}
+	return out
+}
+
+// lockedFrontier lists the unopened locked doors next to the reached area,
+// in row order so the walk is stable for a given seed.
+func lockedFrontier(l *Level, reached []image.Point, opened map[image.Point]bool) []image.Point {
+	found := map[image.Point]bool{}
+	var out []image.Point
+	for _, p := range reached {
+		for _, d := range []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			q := p.Add(d)
+			t := l.Tile(q.X, q.Y)
+			if t == nil || !t.HasTag(tiles.TagDoor) || t.DoorState != 3 || opened[q] || found[q] {
+				continue
+			}
+			found[q] = true
+			out = append(out, q)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Y != out[j].Y {
+			return out[i].Y < out[j].Y
+		}
+		return out[i].X < out[j].X
+	})
+	return out
+}
+
+// lockDoorDepth is the lowest depth of the reached tiles beside a door.
+func lockDoorDepth(l *Level, door image.Point, seen map[image.Point]bool, depth [][]int) int {
+	best := -1
+	for _, d := range []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		q := door.Add(d)
+		if seen[q] && (best < 0 || depth[q.Y][q.X] < best) {
+			best = depth[q.Y][q.X]
+		}
+	}
+	return max(best, 0)
+}
+
+// lockDoorGate is the gate of the lowest-depth reached tile beside a door,
+// which is what gates the far side of a door that needs no key.
+func lockDoorGate(door image.Point, seen map[image.Point]bool, gate, depth [][]int) int {
+	id, best := 0, -1
+	for _, d := range []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		q := door.Add(d)
+		if seen[q] && (best < 0 || depth[q.Y][q.X] < best) {
+			id, best = gate[q.Y][q.X], depth[q.Y][q.X]
+		}
+	}
+	return id
+}
+
+// pickKeyTile chooses a free walkable tile in the reached area for a key.
+// Room tiles are preferred over corridors, and among them the farther half
+// from spawn, so keys reward exploring rather than sitting at the door.
+func pickKeyTile(l *Level, reached []image.Point, taken map[image.Point]bool, rng *rand.Rand) (image.Point, bool) {
+	var inRooms, other []image.Point
+	for _, p := range reached {
+		t := l.Tiles[p.Y][p.X]
+		if taken[p] || !t.IsWalkable || t.HasTag(tiles.TagDoor) || t.Hazard != tiles.HazardNone {
+			continue
+		}
+		if l.RoomAt(p.X, p.Y) != nil {
+			inRooms = append(inRooms, p)
+		} else {
+			other = append(other, p)
+		}
+	}
+	pool := inRooms
+	if len(pool) == 0 {
+		pool = other
+	}
+	if len(pool) == 0 {
+		return image.Point{}, false
+	}
+	// reached is in BFS order, so the back half is the farther half.
+	pool = pool[len(pool)/2:]
+	return pool[rng.IntN(len(pool))], true
+}
+
+// roomReachedTile returns a reached tile of the room, preferring its centre.
+func roomReachedTile(r *Room, seen map[image.Point]bool) (int, int, bool) {
+	if seen[image.Pt(r.CenterX, r.CenterY)] {
+		return r.CenterX, r.CenterY, true
+	}
+	for y := r.Y; y < r.Y+r.H; y++ {
+		for x := r.X; x < r.X+r.W; x++ {
+			if seen[image.Pt(x, y)] {
+				return x, y, true
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -107,12 +107,17 @@ func FindSpawnAndExit(l *Level) (spawnX, spawnY, exitX, exitY int) {
 	// Use IsPassable so the BFS can path through closed/locked doors,
 	// placing the exit behind doors to force exploration.
 	passable := l.IsPassable
-	sx, sy := anyPassable(l, passable)
+	// Start outside secret rooms: they are sealed off until their wall
+	// breaks, and a walk from inside one would never leave it.
+	sx, sy := anyPassable(l, func(x, y int) bool {
+		r := l.RoomAt(x, y)
+		return passable(x, y) && (r == nil || !r.Secret)
+	})
 	// Pass 1: establish the spawn at one extreme of the dungeon.
 	ax, ay, _ := bfsFarthest(l, sx, sy, passable)
 
-	// Ensure spawn lands on a truly walkable tile, not a door.
-	if !l.IsWalkable(ax, ay) {
+	// Ensure spawn lands on a truly walkable tile, not a door or hazard.
+	if !l.IsSafe(ax, ay) {
 		if nx, ny, ok := nearestWalkable(l, ax, ay); ok {
 			ax, ay = nx, ny
 		}
@@ -134,8 +139,9 @@ func FindSpawnAndExit(l *Level) (spawnX, spawnY, exitX, exitY int) {
 				if d < 0 {
 					continue // unreachable
 				}
-				// Only place spawn/exit on truly walkable tiles, not on doors.
-				if !l.IsWalkable(x, y) {
+				// Only place spawn/exit on truly walkable tiles, not on doors
+				// or hazards.
+				if !l.IsSafe(x, y) {
 					continue
 				}
 				dx, dy := x-ax, y-ay
@@ -0,0 +1,259 @@
+package levels
+
+import (
+	"dungeoneer/tiles"
+	"fmt"
+	"image"
+	"math/rand/v2"
+)
+
+// Prefab is a hand-authored room fragment (a shrine, puzzle room or set-piece
+// arena) that the generators stamp into a random floor. The fragment is made
+// in the level editor; its outer ring is normally wall, broken by the declared
+// entrances.
+type Prefab struct {
+	ID        string
+	Level     *Level    // fragment tiles and entities, origin at (0, 0)
+	Entrances []Point   // walkable tiles on the fragment's edge
+	Tags      []RoomTag // tags the stamped room keeps through TagRooms
+}
+
+// Validate checks that the fragment has tiles, that every entrance is a
+// walkable tile on its edge and that its walkable tiles are all connected.
+func (pf *Prefab) Validate() []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	if pf.ID == "" {
+		fail("missing id")
+	}
+	l := pf.Level
+	if l == nil || l.W < 3 || l.H < 3 || len(l.Tiles) != l.H {
+		fail("fragment must be at least 3x3")
+		return errs
+	}
+	if len(pf.Entrances) == 0 {
+		fail("no entrances")
+	}
+	for _, e := range pf.Entrances {
+		if _, ok := pf.entranceDir(e); !ok {
+			fail("entrance (%d,%d) is not on the fragment's edge", e.X, e.Y)
+		} else if !l.Tiles[e.Y][e.X].IsWalkable {
+			fail("entrance (%d,%d) is not walkable", e.X, e.Y)
+		}
+	}
+	if comps := floodComponentsIgnoreDoors(l); len(comps) > 1 {
+		fail("walkable tiles form %d separate areas", len(comps))
+	}
+	for _, ent := range l.Entities {
+		if ent.X < 0 || ent.Y < 0 || ent.X >= l.W || ent.Y >= l.H {
+			fail("entity %s at (%d,%d) lies outside the fragment", ent.Type, ent.X, ent.Y)
+		}
+	}
+	return errs
+}
+
+// entranceDir returns the outward direction of an edge tile. Corners are
+// rejected since they have no single outward side.
+func (pf *Prefab) entranceDir(e Point) (image.Point, bool) {
+	w, h := pf.Level.W, pf.Level.H
+	if e.X < 0 || e.Y < 0 || e.X >= w || e.Y >= h {
+		return image.Point{}, false
+	}
+	onX := e.X == 0 || e.X == w-1
+	onY := e.Y == 0 || e.Y == h-1
+	switch {
+	case onX == onY:
+		return image.Point{}, false
+	case e.X == 0:
+		return image.Pt(-1, 0), true
+	case e.X == w-1:
+		return image.Pt(1, 0), true
+	case e.Y == 0:
+		return image.Pt(0, -1), true
+	default:
+		return image.Pt(0, 1), true
+	}
+}
+
+// Values of reservedMask. The margin is the generator-owned wall ring around a
+// stamped prefab; only the doorstep outside each entrance is left open.
+const (
+	reservedMargin = 1
+	reservedPrefab = 2
+)
+
+// reservedMask marks tiles that carving must leave alone once a prefab is
+// stamped. Nil when the floor has no prefabs.
+var reservedMask [][]uint8
+
+func isReserved(x, y int) bool {
+	return reservedMask != nil && reservedMask[y][x] != 0
+}
+
+func isPrefabTile(x, y int) bool {
+	return reservedMask != nil && reservedMask[y][x] == reservedPrefab
+}
+
+// stampPrefabs places each prefab on a free spot of the floor, pastes its tiles
+// and entities, adds a Room for it and tunnels from every entrance to the
+// nearest walkable tile outside. A spot is free when the prefab and its margin
+// overlap no room; failing that, a generated room large enough is replaced.
+// Prefabs that fit nowhere are skipped.
+func stampPrefabs(l *Level, prefabs []*Prefab, rng *rand.Rand) {
+	for _, pf := range prefabs {
+		r, ok := findPrefabSpot(l, pf, rng)
+		if !ok {
+			fmt.Printf("prefab %s: no room for a %dx%d vault\n", pf.ID, pf.Level.W, pf.Level.H)
+			continue
+		}
+		if reservedMask == nil {
+			reservedMask = make([][]uint8, l.H)
+			for y := range reservedMask {
+				reservedMask[y] = make([]uint8, l.W)
+			}
+		}
+		stampPrefab(l, pf, r)
+	}
+}
+
+// findPrefabSpot picks a random free rect for pf, keeping two tiles from the
+// map edge so tunnels can always run around it.
+func findPrefabSpot(l *Level, pf *Prefab, rng *rand.Rand) (rect, bool) {
+	w, h := pf.Level.W, pf.Level.H
+	var free []rect
+	for y := 2; y+h+2 <= l.H; y++ {
+		for x := 2; x+w+2 <= l.W; x++ {
+			r := rect{X: x, Y: y, W: w, H: h}
+			if !prefabSpotBlocked(l, r, -1) {
+				free = append(free, r)
+			}
+		}
+	}
+	if len(free) > 0 {
+		return free[rng.IntN(len(free))], true
+	}
+
+	// Replace a generated room, centring the prefab inside it.
+	var hosts []int
+	for i, room := range l.Rooms {
+		if room.Prefab == "" && room.W >= w && room.H >= h {
+			r := rect{X: room.X + (room.W-w)/2, Y: room.Y + (room.H-h)/2, W: w, H: h}
+			if r.X >= 2 && r.Y >= 2 && r.X+w+2 <= l.W && r.Y+h+2 <= l.H && !prefabSpotBlocked(l, r, i) {
+				hosts = append(hosts, i)
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		return rect{}, false
+	}
+	i := hosts[rng.IntN(len(hosts))]
+	room := l.Rooms[i]
+	l.Rooms = append(l.Rooms[:i], l.Rooms[i+1:]...)
+	for j := range l.Rooms {
+		l.Rooms[j].Index = j
+	}
+	return rect{X: room.X + (room.W-w)/2, Y: room.Y + (room.H-h)/2, W: w, H: h}, true
+}
+
+// prefabSpotBlocked reports whether r plus its margin overlaps a room other
+// than skip, or tiles already reserved by another prefab.
+func prefabSpotBlocked(l *Level, r rect, skip int) bool {
+	for i, room := range l.Rooms {
+		if i == skip {
+			continue
+		}
+		if r.X-1 < room.X+room.W && room.X < r.X+r.W+1 && r.Y-1 < room.Y+room.H && room.Y < r.Y+r.H+1 {
+			return true
+		}
+	}
+	for y := r.Y - 2; y < r.Y+r.H+2; y++ {
+		for x := r.X - 2; x < r.X+r.W+2; x++ {
+			if isReserved(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func stampPrefab(l *Level, pf *Prefab, r rect) {
+	// Wall in the margin, then paste the fragment over the interior.
+	for y := r.Y - 1; y <= r.Y+r.H; y++ {
+		for x := r.X - 1; x <= r.X+r.W; x++ {
+			*l.Tiles[y][x] = tiles.Tile{}
+			reservedMask[y][x] = reservedMargin
+		}
+	}
+	for py := 0; py < r.H; py++ {
+		for px := 0; px < r.W; px++ {
+			src := pf.Level.Tiles[py][px]
+			t := *src
+			t.Sprites = append(t.Sprites[:0:0], src.Sprites...)
+			*l.Tiles[r.Y+py][r.X+px] = t
+			reservedMask[r.Y+py][r.X+px] = reservedPrefab
+		}
+	}
+	for _, ent := range pf.Level.Entities {
+		ent.X += r.X
+		ent.Y += r.Y
+		l.Entities = append(l.Entities, ent)
+	}
+
+	room := rectsToRooms([]rect{r})[0]
+	room.Index = len(l.Rooms)
+	room.Prefab = pf.ID
+	room.PrefabTags = append([]RoomTag(nil), pf.Tags...)
+	room.Tags = append([]RoomTag(nil), pf.Tags...)
+	l.Rooms = append(l.Rooms, room)
+
+	for _, e := range pf.Entrances {
+		d, _ := pf.entranceDir(e)
+		step := image.Pt(r.X+e.X+d.X, r.Y+e.Y+d.Y)
+		reservedMask[step.Y][step.X] = 0
+		l.Tiles[step.Y][step.X].IsWalkable = true
+		tunnelToWalkable(l, []image.Point{step}, func(x, y int) bool {
+			return (x != step.X || y != step.Y) && l.Tiles[y][x].IsWalkable
+		})
+	}
+}
+
+// tunnelToWalkable carves the shortest path from any of the start tiles to the
+// first tile accepted by goal, moving only through unreserved tiles inside the
+// map border. It reports whether a path was found.
+func tunnelToWalkable(l *Level, starts []image.Point, goal func(x, y int) bool) bool {
+	prev := make([][]image.Point, l.H)
+	seen := make([][]bool, l.H)
+	for y := range prev {
+		prev[y] = make([]image.Point, l.W)
+		seen[y] = make([]bool, l.W)
+	}
+	queue := make([]image.Point, 0, len(starts))
+	for _, s := range starts {
+		seen[s.Y][s.X] = true
+		prev[s.Y][s.X] = image.Pt(-1, -1)
+		queue = append(queue, s)
+	}
+	for i := 0; i < len(queue); i++ {
+		p := queue[i]
+		for _, d := range []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nx, ny := p.X+d.X, p.Y+d.Y
+			if nx < 1 || ny < 1 || nx >= l.W-1 || ny >= l.H-1 || seen[ny][nx] || isReserved(nx, ny) {
+				continue
+			}
+			seen[ny][nx] = true
+			prev[ny][nx] = p
+			if goal(nx, ny) {
+				for c := p; c.X >= 0; c = prev[c.Y][c.X] {
+					if !isReserved(c.X, c.Y) {
+						l.Tiles[c.Y][c.X].IsWalkable = true
+					}
+				}
+				return true
+			}
+			queue = append(queue, image.Pt(nx, ny))
+		}
+	}
+	return false
+}
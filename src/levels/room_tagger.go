@@ -10,9 +10,14 @@ func TagRooms(l *Level, spawnX, spawnY, exitX, exitY int, bossFloor bool) {
 		return
 	}
 
-	// Reset all tags.
+	// Reset all tags. Prefab and secret rooms sit out the automatic roles
+	// below and get their fixed tags back at the end.
+	var fixed []*Room
 	for i := range l.Rooms {
 		l.Rooms[i].Tags = nil
+		if l.Rooms[i].fixedRole() {
+			fixed = append(fixed, &l.Rooms[i])
+		}
 	}
 
 	// Step 1: Tag spawn and exit rooms.
@@ -25,6 +30,7 @@ func TagRooms(l *Level, spawnX, spawnY, exitX, exitY int, bossFloor bool) {
 	if exitRoom != nil {
 		exitRoom.AddTag(TagExit)
 	}
+	skip := append([]*Room{spawnRoom, exitRoom}, fixed...)
 
 	// Step 2: Classify room connectivity (count walkable exits per room).
 	exits := countRoomExits(l)
@@ -32,7 +38,7 @@ func TagRooms(l *Level, spawnX, spawnY, exitX, exitY int, bossFloor bool) {
 	// Step 3: Tag dead-ends and crossroads from connectivity.
 	for i := range l.Rooms {
 		r := &l.Rooms[i]
-		if r.HasTag(TagSpawn) || r.HasTag(TagExit) {
+		if r.HasTag(TagSpawn) || r.HasTag(TagExit) || r.fixedRole() {
 			continue
 		}
 		e := exits[r.Index]
@@ -46,7 +52,7 @@ func TagRooms(l *Level, spawnX, spawnY, exitX, exitY int, bossFloor bool) {
 
 	// Step 4: Boss arena on boss floors (largest room).
 	if bossFloor {
-		best := largestUntaggedRoom(l, spawnRoom, exitRoom)
+		best := largestUntaggedRoom(l, skip...)
 		if best != nil {
 			best.Tags = nil // clear any connectivity tags
 			best.AddTag(TagBossArena)
@@ -59,15 +65,35 @@ func TagRooms(l *Level, spawnX, spawnY, exitX, exitY int, bossFloor bool) {
 	pickSanctuary(l, spawnX, spawnY)
 
 	// Step 6: Pick treasure rooms — 1-2 per floor from dead-ends / small optionals.
-	pickTreasure(l, spawnRoom, exitRoom)
+	pickTreasure(l, skip...)
 
 	// Step 7: All remaining untagged rooms → common.
 	for i := range l.Rooms {
 		r := &l.Rooms[i]
-		if r.PrimaryTag() == TagCommon && !r.HasTag(TagDeadEnd) && !r.HasTag(TagCrossroads) {
+		if !r.fixedRole() && r.PrimaryTag() == TagCommon && !r.HasTag(TagDeadEnd) && !r.HasTag(TagCrossroads) {
 			r.AddTag(TagCommon)
 		}
 	}
+
+	// Step 8: Prefab rooms keep spawn/exit and take their authored tags.
+	// Secret rooms are monster-free loot rooms off the critical path.
+	for _, r := range fixed {
+		if r.Secret {
+			r.AddTag(TagSecret)
+			r.AddTag(TagLoot)
+			r.AddTag(TagCleared)
+			r.AddTag(TagOptional)
+		}
+		for _, t := range r.PrefabTags {
+			r.AddTag(t)
+		}
+	}
+}
+
+// fixedRole reports whether a room sits out automatic tagging: prefab rooms
+// carry authored tags and secret rooms are always tagged secret.
+func (r *Room) fixedRole() bool {
+	return r.Prefab != "" || r.Secret
 }
 
 // countRoomExits counts how many walkable border tiles connect each room to
@@ -141,6 +167,9 @@ func pickSanctuary(l *Level, spawnX, spawnY int) {
 	for i := range l.Rooms {
 		r := &l.Rooms[i]
 		// Skip rooms that already have a primary role.
+		if r.fixedRole() {
+			continue
+		}
 		pt := r.PrimaryTag()
 		if pt == TagSpawn || pt == TagExit || pt == TagBossArena {
 			continue
@@ -173,7 +202,8 @@ func pickSanctuary(l *Level, spawnX, spawnY int) {
 	}
 }
 
-// pickTreasure selects 1-2 dead-end or optional rooms as treasure rooms.
+// pickTreasure selects 1-2 treasure rooms: rooms behind an optional lock
+// first, then dead ends, then small optional rooms.
 func pickTreasure(l *Level, skip ...*Room) {
 	skipIdx := make(map[int]bool)
 	for _, r := range skip {
@@ -185,13 +215,36 @@ func pickTreasure(l *Level, skip ...*Room) {
 	count := 0
 	maxTreasure := 2
 
-	// First pass: dead-end rooms make the best treasure rooms.
+	// First pass: rooms behind a lock the exit does not need are vaults.
 	for i := range l.Rooms {
 		if count >= maxTreasure {
 			break
 		}
 		r := &l.Rooms[i]
-		if skipIdx[r.Index] {
+		if skipIdx[r.Index] || r.Lock == 0 {
+			continue
+		}
+		if lk := l.LockByID(r.Lock); lk == nil || !lk.Optional {
+			continue
+		}
+		if pt := r.PrimaryTag(); pt != TagCommon && pt != TagDeadEnd {
+			continue
+		}
+		r.Tags = filterModifiers(r.Tags)
+		r.AddTag(TagTreasure)
+		r.AddTag(TagLoot)
+		r.AddTag(TagDecorated)
+		r.AddTag(TagOptional)
+		count++
+	}
+
+	// Second pass: dead-end rooms make the best treasure rooms.
+	for i := range l.Rooms {
+		if count >= maxTreasure {
+			break
+		}
+		r := &l.Rooms[i]
+		if skipIdx[r.Index] || r.HasTag(TagTreasure) {
 			continue
 		}
 		pt := r.PrimaryTag()
@@ -209,7 +262,7 @@ func pickTreasure(l *Level, skip ...*Room) {
 		count++
 	}
 
-	// Second pass: any small optional room.
+	// Third pass: any small optional room.
 	for i := range l.Rooms {
 		if count >= maxTreasure {
 			break
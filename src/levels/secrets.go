@@ -0,0 +1,166 @@
+package levels
+
+import (
+	"math/rand/v2"
+
+	"dungeoneer/sprites"
+	"dungeoneer/tiles"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Secret room tuning.
+const (
+	maxSecretRooms  = 2 // rolls of SecretRoomChance per floor
+	secretRoomMin   = 3 // interior size range, in tiles
+	secretRoomMax   = 5
+	secretWallHP    = 2 // hits the cracked entrance takes before it breaks
+	secretRoomTries = 60
+)
+
+// carveSecretRooms hollows small rooms out of solid rock next to generated
+// rooms, each sealed off by a single cracked wall. The interior is walkable
+// floor from the start but can't be reached until the wall is broken. The
+// room is marked Secret: spawn placement starts outside it, room tagging
+// gives it the secret role and the level checker leaves it out of its
+// connectivity checks.
+func carveSecretRooms(l *Level, p GenParams, rng *rand.Rand) {
+	if p.SecretRoomChance <= 0 || len(l.Rooms) == 0 {
+		return
+	}
+	floorImg, wallImg, crackedImg := secretImages(p)
+	if floorImg == nil {
+		return
+	}
+	for i := 0; i < maxSecretRooms; i++ {
+		if rng.Float64() >= p.SecretRoomChance {
+			continue
+		}
+		for try := 0; try < secretRoomTries; try++ {
+			if carveSecretRoom(l, p, rng, floorImg, wallImg, crackedImg) {
+				break
+			}
+		}
+	}
+}
+
+// carveSecretRoom makes one attempt at a secret room behind a random wall of
+// a random room and reports whether it carved one.
+func carveSecretRoom(l *Level, p GenParams, rng *rand.Rand, floorImg, wallImg, crackedImg *ebiten.Image) bool {
+	r := l.Rooms[rng.IntN(len(l.Rooms))]
+	if r.fixedRole() || r.W < 3 || r.H < 3 {
+		return false
+	}
+	w := secretRoomMin + rng.IntN(secretRoomMax-secretRoomMin+1)
+	h := secretRoomMin + rng.IntN(secretRoomMax-secretRoomMin+1)
+
+	// Pick the cracked wall on one side of the room, then lay the secret
+	// room out beyond it with the wall somewhere along its near side.
+	var ex, ey, x0, y0 int
+	switch rng.IntN(4) {
+	case 0: // north
+		ex, ey = r.X+1+rng.IntN(r.W-2), r.Y-1
+		x0, y0 = ex-rng.IntN(w), ey-h
+	case 1: // south
+		ex, ey = r.X+1+rng.IntN(r.W-2), r.Y+r.H
+		x0, y0 = ex-rng.IntN(w), ey+1
+	case 2: // west
+		ex, ey = r.X-1, r.Y+1+rng.IntN(r.H-2)
+		x0, y0 = ex-w, ey-rng.IntN(h)
+	default: // east
+		ex, ey = r.X+r.W, r.Y+1+rng.IntN(r.H-2)
+		x0, y0 = ex+1, ey-rng.IntN(h)
+	}
+
+	// The wall must face open floor, and the room plus its wall ring must
+	// be untouched rock inside the map.
+	entry := l.Tile(ex, ey)
+	if entry == nil || entry.IsWalkable || entry.HasTag(tiles.TagDoor) || !secretEntryFacesFloor(l, ex, ey) {
+		return false
+	}
+	if x0 < 1 || y0 < 1 || x0+w >= l.W || y0+h >= l.H {
+		return false
+	}
+	for y := y0 - 1; y <= y0+h; y++ {
+		for x := x0 - 1; x <= x0+w; x++ {
+			t := l.Tiles[y][x]
+			if t.IsWalkable || t.HasTag(tiles.TagDoor) || isReserved(x, y) || l.RoomAt(x, y) != nil {
+				return false
+			}
+		}
+	}
+
+	for y := y0 - 1; y <= y0+h; y++ {
+		for x := x0 - 1; x <= x0+w; x++ {
+			t := l.Tiles[y][x]
+			if x >= x0 && y >= y0 && x < x0+w && y < y0+h {
+				t.IsWalkable = true
+				t.Sprites = nil
+				t.AddSpriteByID(p.FloorFlavor+"_floor", floorImg)
+			} else if len(t.Sprites) == 0 {
+				t.AddSpriteByID(p.WallFlavor+"_wall", wallImg)
+			}
+		}
+	}
+	entry.Sprites = nil
+	entry.AddSpriteByID(p.WallFlavor+"_chunk", crackedImg)
+	entry.WallHP = secretWallHP
+
+	l.Rooms = append(l.Rooms, Room{
+		X: x0, Y: y0, W: w, H: h,
+		CenterX: x0 + w/2,
+		CenterY: y0 + h/2,
+		Size:    ClassifyRoomSize(w, h),
+		Index:   len(l.Rooms),
+		Secret:  true,
+	})
+	return true
+}
+
+// secretEntryFacesFloor reports whether the wall at (x, y) has a plain,
+// walkable floor tile on some side, so the player can walk up to it.
+func secretEntryFacesFloor(l *Level, x, y int) bool {
+	for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		if t := l.Tile(x+d[0], y+d[1]); t != nil && t.IsWalkable && !t.HasTag(tiles.TagDoor) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretImages returns the floor, wall and cracked wall sprites for the
+// params' flavors, or nils when the sheets cannot be loaded.
+func secretImages(p GenParams) (floor, wall, cracked *ebiten.Image) {
+	wss, err := sprites.LoadWallSpriteSheet(p.WallFlavor)
+	if err != nil {
+		return nil, nil, nil
+	}
+	fss, err := sprites.LoadWallSpriteSheet(p.FloorFlavor)
+	if err != nil {
+		return nil, nil, nil
+	}
+	return fss.Floor, wss.Wall, wss.Chunk
+}
+
+// HitWall deals one hit to the cracked wall at (x, y) and reports whether it
+// broke. A broken wall turns into floor, borrowing the floor sprite of a
+// walkable neighbour; solid walls shrug hits off.
+func (l *Level) HitWall(x, y int) bool {
+	t := l.Tile(x, y)
+	if t == nil || t.WallHP == 0 {
+		return false
+	}
+	t.WallHP--
+	if t.WallHP > 0 {
+		return false
+	}
+	t.IsWalkable = true
+	t.Sprites = nil
+	for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		if n := l.Tile(x+d[0], y+d[1]); n != nil && n.IsWalkable && len(n.Sprites) > 0 {
+			t.AddSpriteByID(n.Sprites[0].ID, n.Sprites[0].Image)
+			break
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package levels
+
+import (
+	"image"
+	"math/rand/v2"
+
+	"dungeoneer/tiles"
+)
+
+// EntityTrap is the PlacedEntity type of a trap; its Trap field holds the kind
+// and its Wire links it to other traps.
+const EntityTrap = "Trap"
+
+// Trap kinds. Stepping on a trap's tile sets it off, and setting off a trap
+// with a Wire also fires every other trap on that wire, so a plate wired to a
+// dart launcher in the wall shoots whoever stepped on the plate.
+const (
+	TrapPlate    = "plate"    // does nothing itself; fires its wire
+	TrapDart     = "dart"     // wall launcher; shoots at the tile that fired it
+	TrapSpikes   = "spikes"   // hurts whoever stands on it, then re-arms
+	TrapCollapse = "collapse" // gives way into a pit shortly after being stepped on
+	TrapAlarm    = "alarm"    // wakes dormant ambushers nearby
+)
+
+// TrapKinds lists every trap kind.
+var TrapKinds = []string{TrapPlate, TrapDart, TrapSpikes, TrapCollapse, TrapAlarm}
+
+// trapWeights is how often placeTraps picks each kind. A dart is always
+// placed together with the plate that fires it.
+var trapWeights = []struct {
+	Kind   string
+	Weight int
+}{
+	{TrapSpikes, 4},
+	{TrapDart, 3},
+	{TrapCollapse, 2},
+	{TrapAlarm, 1},
+}
+
+// Trap placement tuning.
+const (
+	trapClearance  = 4 // Chebyshev distance kept clear around spawn and exit
+	dartMinRange   = 2
+	dartMaxRange   = 6
+	trapPlaceTries = 40
+)
+
+// placeTraps scatters up to budget traps over the floor. Traps stay off
+// doors, hazards, vaults, other entities and the area around spawn and exit;
+// collapsing floors only go inside rooms, away from the walls, so a pit can
+// never cut the floor in two.
+func placeTraps(l *Level, budget int, rng *rand.Rand) {
+	if budget <= 0 {
+		return
+	}
+	sx, sy, ex, ey := FindSpawnAndExit(l)
+	taken := map[image.Point]bool{}
+	wire := 0
+	for _, e := range l.Entities {
+		taken[image.Pt(e.X, e.Y)] = true
+		wire = max(wire, e.Wire)
+	}
+	free := func(x, y int) bool {
+		if !l.IsSafe(x, y) || l.Tiles[y][x].HasTag(tiles.TagDoor) || isPrefabTile(x, y) || taken[image.Pt(x, y)] {
+			return false
+		}
+		return max(abs(x-sx), abs(y-sy)) > trapClearance && max(abs(x-ex), abs(y-ey)) > trapClearance
+	}
+
+	total := 0
+	for _, w := range trapWeights {
+		total += w.Weight
+	}
+	for placed := 0; placed < budget; placed++ {
+		roll := rng.IntN(total)
+		kind := trapWeights[0].Kind
+		for _, w := range trapWeights {
+			if roll < w.Weight {
+				kind = w.Kind
+				break
+			}
+			roll -= w.Weight
+		}
+		for try := 0; try < trapPlaceTries; try++ {
+			x, y := 1+rng.IntN(l.W-2), 1+rng.IntN(l.H-2)
+			if !free(x, y) || kind == TrapCollapse && !inRoomInterior(l, x, y) {
+				continue
+			}
+			e := PlacedEntity{X: x, Y: y, Type: EntityTrap, Trap: kind}
+			if kind == TrapDart {
+				lx, ly, ok := findDartLauncher(l, x, y, taken, rng)
+				if !ok {
+					continue
+				}
+				wire++
+				e.Trap, e.Wire = TrapPlate, wire
+				l.Entities = append(l.Entities, PlacedEntity{X: lx, Y: ly, Type: EntityTrap, Trap: TrapDart, Wire: wire})
+				taken[image.Pt(lx, ly)] = true
+			}
+			l.Entities = append(l.Entities, e)
+			taken[image.Pt(x, y)] = true
+			break
+		}
+	}
+}
+
+// inRoomInterior reports whether (x, y) lies in a generated room, off its
+// outer ring of tiles. Prefab and secret rooms don't count.
+func inRoomInterior(l *Level, x, y int) bool {
+	r := l.RoomAt(x, y)
+	return r != nil && !r.fixedRole() && x > r.X && y > r.Y && x < r.X+r.W-1 && y < r.Y+r.H-1
+}
+
+// findDartLauncher looks along the four directions from a plate for a wall
+// tile in dart range with a clear run of floor between the two.
+func findDartLauncher(l *Level, px, py int, taken map[image.Point]bool, rng *rand.Rand) (int, int, bool) {
+	dirs := [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	start := rng.IntN(len(dirs))
+	for i := range dirs {
+		d := dirs[(start+i)%len(dirs)]
+		for n := 1; n <= dartMaxRange; n++ {
+			x, y := px+d[0]*n, py+d[1]*n
+			t := l.Tile(x, y)
+			if t == nil || t.HasTag(tiles.TagDoor) {
+				break
+			}
+			if t.IsWalkable {
+				continue
+			}
+			if n > dartMinRange && !taken[image.Pt(x, y)] && !isPrefabTile(x, y) {
+				return x, y, true
+			}
+			break
+		}
+	}
+	return 0, 0, false
+}
@@ -3,12 +3,16 @@ package main
 import (
 	"dungeoneer/game"
 	"dungeoneer/images"
+	"flag"
 	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
+	replay := flag.String("replay", "", "play back a recorded run from the replays directory")
+	flag.Parse()
+
 	ebiten.SetWindowTitle("Dungeoneer")
 	ebiten.SetWindowSize(640, 480)
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
@@ -18,6 +22,11 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if *replay != "" {
+		if err := g.StartReplay(*replay); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	if err = ebiten.RunGame(g); err != nil {
 		log.Fatal(err)
@@ -2,12 +2,20 @@
 //
 // Implementation notes:
 //   - Movement supports 8 directions (4 orthogonal + 4 diagonal). Orthogonal
-//     steps cost 10, diagonal steps cost 14 (≈ 10√2). The heuristic is octile
-//     distance, which is admissible under these costs.
-//   - The A* here uses simple slices and maps for open/closed lists. For large
-//     maps or frequent queries, replacing `open` with a binary heap and
-//     reusing node objects from a pool will reduce allocations and improve
-//     performance (expected O(N log N) with a heap).
+//     steps cost 10, diagonal steps cost 14 (≈ 10√2), plus the hazard cost of
+//     the tile stepped onto. The heuristic is octile distance, which is
+//     admissible under these costs.
+//   - Each level gets a flat search grid (see grid.go) that is reused across
+//     queries, so a search allocates nothing but the returned path. The open
+//     list is a binary heap with lazy deletion: a node whose cost improves is
+//     pushed again and the stale entry is skipped when popped.
+//   - Closed doors are walls to AStar. AStarThroughDoors is for monsters that
+//     open, bash or phase through them; locked doors block every search.
+//   - Recent results are cached per level by start and goal (see cache.go).
+//     The cache notices door state changes by itself; anything else that
+//     changes which tiles are walkable must call InvalidateCache.
+//   - Monsters chasing the player share a FlowField (see flowfield.go): one
+//     Dijkstra from the player's tile that any monster samples in O(1).
 package pathing
 
 import (
@@ -15,18 +23,17 @@ import (
 	"dungeoneer/tiles"
 )
 
-type Node struct {
-	X, Y   int
-	GCost  int // Cost from start
-	HCost  int // Heuristic to goal
-	FCost  int // G + H
-	Parent *Node
-}
-
 type PathNode struct {
 	X, Y int
 }
 
+// neighbours lists the 8 steps as [dx, dy, cost]: 4 orthogonal (cost 10) and
+// 4 diagonal (cost 14).
+var neighbours = [8][3]int{
+	{0, 1, 10}, {1, 0, 10}, {0, -1, 10}, {-1, 0, 10},
+	{1, 1, 14}, {1, -1, 14}, {-1, 1, 14}, {-1, -1, 14},
+}
+
 func heuristic(x1, y1, x2, y2 int) int {
 	dx := abs(x1 - x2)
 	dy := abs(y1 - y2)
@@ -38,20 +45,6 @@ func heuristic(x1, y1, x2, y2 int) int {
 	return 10*dy + 4*dx
 }
 
-func reconstructPath(end *Node) []PathNode {
-	var path []PathNode
-	for n := end; n != nil; n = n.Parent {
-		path = append([]PathNode{{X: n.X, Y: n.Y}}, path...)
-	}
-	// Drop the start node — callers are already at that position.
-	// Including it caused the controller to interpolate back to the start
-	// tile when a new path was issued mid-step.
-	if len(path) > 0 {
-		path = path[1:]
-	}
-	return path
-}
-
 func abs(n int) int {
 	if n < 0 {
 		return -n
@@ -59,110 +52,127 @@ func abs(n int) int {
 	return n
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
+// AStar returns the path from (startX, startY) to (goalX, goalY), excluding
+// the start tile, or nil when the goal cannot be reached. The returned slice
+// belongs to the caller.
+func AStar(level *levels.Level, startX, startY, goalX, goalY int) []PathNode {
+	return astar(level, startX, startY, goalX, goalY, false)
 }
 
-func AStar(level *levels.Level, startX, startY, goalX, goalY int) []PathNode {
+// AStarThroughDoors is AStar for walkers that get past closed doors: those
+// count as floor. Locked doors still block.
+func AStarThroughDoors(level *levels.Level, startX, startY, goalX, goalY int) []PathNode {
+	return astar(level, startX, startY, goalX, goalY, true)
+}
+
+func astar(level *levels.Level, startX, startY, goalX, goalY int, throughDoors bool) []PathNode {
 	if goalX < 0 || goalY < 0 || goalX >= level.W || goalY >= level.H {
 		return nil
 	}
-	// Note: `open` is a simple slice of *Node. This code scans the slice to
-	// find the smallest FCost which is O(n) per extraction. Switching to a
-	// priority queue (binary heap) avoids this linear scan and is recommended
-	// for production workloads.
-	open := []*Node{}
-	closed := map[[2]int]bool{}
-
-	start := &Node{X: startX, Y: startY}
-	start.HCost = heuristic(startX, startY, goalX, goalY)
-	start.FCost = start.HCost
-
-	open = append(open, start)
-
-	var current *Node
-
-	for len(open) > 0 {
-		// Get node with lowest F cost
-		currentIndex := 0
-		current = open[0]
-		for i, n := range open {
-			if n.FCost < current.FCost {
-				current = n
-				currentIndex = i
-			}
-		}
+	gr := gridFor(level)
+	if gr.doors.changed(level) {
+		gr.forget()
+	}
+	key := pathKey{startX, startY, goalX, goalY, throughDoors}
+	path, ok := gr.cache[key]
+	if !ok {
+		path = gr.search(level, startX, startY, goalX, goalY, throughDoors)
+		gr.remember(key, path)
+	}
+	if path == nil {
+		return nil
+	}
+	return append(make([]PathNode, 0, len(path)), path...)
+}
 
-		// Remove from open
-		open = append(open[:currentIndex], open[currentIndex+1:]...)
-		closed[[2]int{current.X, current.Y}] = true
+// search runs A* on the grid without touching the path cache.
+func (gr *grid) search(level *levels.Level, startX, startY, goalX, goalY int, throughDoors bool) []PathNode {
+	if startX < 0 || startY < 0 || startX >= gr.w || startY >= gr.h {
+		return nil
+	}
+	gr.reset()
+	start := gr.index(startX, startY)
+	goal := gr.index(goalX, goalY)
+	gr.visit(start, 0, -1)
+	gr.open.push(start, heuristic(startX, startY, goalX, goalY), 0)
+
+	for gr.open.len() > 0 {
+		current := gr.open.pop().cell
+		if gr.closed[current] == gr.gen {
+			continue // stale entry; a cheaper one was already expanded
+		}
+		gr.closed[current] = gr.gen
 
 		// Reached goal
-		if current.X == goalX && current.Y == goalY {
-			return reconstructPath(current)
+		if current == goal {
+			return gr.reconstructPath(goal)
 		}
 
-		// Explore neighbors: 4 orthogonal (cost 10) + 4 diagonal (cost 14).
-		// [dx, dy, cost]
-		for _, dir := range [8][3]int{
-			{0, 1, 10}, {1, 0, 10}, {0, -1, 10}, {-1, 0, 10},
-			{1, 1, 14}, {1, -1, 14}, {-1, 1, 14}, {-1, -1, 14},
-		} {
+		cx, cy := int(current)%gr.w, int(current)/gr.w
+		cg := int(gr.g[current])
+		for _, dir := range neighbours {
 			dx, dy, moveCost := dir[0], dir[1], dir[2]
-			nx, ny := current.X+dx, current.Y+dy
+			nx, ny := cx+dx, cy+dy
 
-			if !level.IsWalkable(nx, ny) || closed[[2]int{nx, ny}] {
+			if !enterable(level, nx, ny, throughDoors) {
 				continue
 			}
-
-			// Check for closed/locked doors (they block movement even if tile is walkable)
-			tile := level.Tile(nx, ny)
-			if tile != nil && tile.HasTag(tiles.TagDoor) && (tile.DoorState == 2 || tile.DoorState == 3) {
+			n := gr.index(nx, ny)
+			if gr.closed[n] == gr.gen {
 				continue
 			}
+			tile := level.Tiles[ny][nx]
 
 			// Corner-cutting prevention: a diagonal step is only valid when both
 			// orthogonal neighbours are clear. This stops the player squeezing
 			// through the gap between two touching walls.
 			if dx != 0 && dy != 0 {
-				if !level.IsWalkable(current.X+dx, current.Y) || !level.IsWalkable(current.X, current.Y+dy) {
+				if !level.IsWalkable(cx+dx, cy) || !level.IsWalkable(cx, cy+dy) {
 					continue
 				}
 			}
 
-			newGCost := current.GCost + moveCost
-
-			// If already in the open list, only update if we found a cheaper path.
-			updated := false
-			for _, existing := range open {
-				if existing.X == nx && existing.Y == ny {
-					if newGCost < existing.GCost {
-						existing.GCost = newGCost
-						existing.FCost = newGCost + existing.HCost
-						existing.Parent = current
-					}
-					updated = true
-					break
-				}
-			}
-			if updated {
+			// Hazards stay walkable but cost extra, so paths skirt them
+			// when there is a reasonable way around.
+			newG := cg + moveCost + tile.HazardCost()
+			if gr.seen[n] == gr.gen && newG >= int(gr.g[n]) {
 				continue
 			}
-
-			n := &Node{
-				X:      nx,
-				Y:      ny,
-				GCost:  newGCost,
-				HCost:  heuristic(nx, ny, goalX, goalY),
-				Parent: current,
-			}
-			n.FCost = n.GCost + n.HCost
-			open = append(open, n)
+			gr.visit(n, newG, current)
+			h := heuristic(nx, ny, goalX, goalY)
+			gr.open.push(n, newG+h, h)
 		}
 	}
 
 	return nil // No path
 }
+
+// enterable reports whether a search may step onto (x, y). Closed and locked
+// doors are walls, unless throughDoors lets it through closed ones.
+func enterable(level *levels.Level, x, y int, throughDoors bool) bool {
+	if level.IsWalkable(x, y) {
+		return true
+	}
+	if !throughDoors {
+		return false
+	}
+	t := level.Tile(x, y)
+	return t != nil && t.HasTag(tiles.TagDoor) && t.DoorState == 2
+}
+
+// reconstructPath walks the parent links back from end. The start node is
+// dropped: callers are already at that position, and including it caused the
+// controller to interpolate back to the start tile when a new path was issued
+// mid-step.
+func (gr *grid) reconstructPath(end int32) []PathNode {
+	n := 0
+	for i := end; gr.parent[i] >= 0; i = gr.parent[i] {
+		n++
+	}
+	path := make([]PathNode, n)
+	for i := end; gr.parent[i] >= 0; i = gr.parent[i] {
+		n--
+		path[n] = PathNode{X: int(i) % gr.w, Y: int(i) / gr.w}
+	}
+	return path
+}
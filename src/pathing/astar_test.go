@@ -0,0 +1,232 @@
+package pathing
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"dungeoneer/levels"
+	"dungeoneer/tiles"
+)
+
+// legacyNode and legacyAStar are the original A*: a linear scan of an open
+// slice, a closed map per call and a prepending path rebuild. They are kept
+// here as the reference the new search is checked and benchmarked against.
+type legacyNode struct {
+	X, Y   int
+	GCost  int
+	HCost  int
+	FCost  int
+	Parent *legacyNode
+}
+
+func legacyAStar(level *levels.Level, startX, startY, goalX, goalY int) []PathNode {
+	if goalX < 0 || goalY < 0 || goalX >= level.W || goalY >= level.H {
+		return nil
+	}
+	open := []*legacyNode{}
+	closed := map[[2]int]bool{}
+
+	start := &legacyNode{X: startX, Y: startY}
+	start.HCost = heuristic(startX, startY, goalX, goalY)
+	start.FCost = start.HCost
+	open = append(open, start)
+
+	for len(open) > 0 {
+		currentIndex := 0
+		current := open[0]
+		for i, n := range open {
+			if n.FCost < current.FCost {
+				current = n
+				currentIndex = i
+			}
+		}
+		open = append(open[:currentIndex], open[currentIndex+1:]...)
+		closed[[2]int{current.X, current.Y}] = true
+
+		if current.X == goalX && current.Y == goalY {
+			var path []PathNode
+			for n := current; n != nil; n = n.Parent {
+				path = append([]PathNode{{X: n.X, Y: n.Y}}, path...)
+			}
+			return path[1:]
+		}
+
+		for _, dir := range neighbours {
+			dx, dy, moveCost := dir[0], dir[1], dir[2]
+			nx, ny := current.X+dx, current.Y+dy
+			if !level.IsWalkable(nx, ny) || closed[[2]int{nx, ny}] {
+				continue
+			}
+			tile := level.Tile(nx, ny)
+			if tile != nil && tile.HasTag(tiles.TagDoor) && (tile.DoorState == 2 || tile.DoorState == 3) {
+				continue
+			}
+			if dx != 0 && dy != 0 {
+				if !level.IsWalkable(current.X+dx, current.Y) || !level.IsWalkable(current.X, current.Y+dy) {
+					continue
+				}
+			}
+			newGCost := current.GCost + moveCost
+			if tile != nil {
+				newGCost += tile.HazardCost()
+			}
+			updated := false
+			for _, existing := range open {
+				if existing.X == nx && existing.Y == ny {
+					if newGCost < existing.GCost {
+						existing.GCost = newGCost
+						existing.FCost = newGCost + existing.HCost
+						existing.Parent = current
+					}
+					updated = true
+					break
+				}
+			}
+			if updated {
+				continue
+			}
+			n := &legacyNode{X: nx, Y: ny, GCost: newGCost, HCost: heuristic(nx, ny, goalX, goalY), Parent: current}
+			n.FCost = n.GCost + n.HCost
+			open = append(open, n)
+		}
+	}
+	return nil
+}
+
+type query struct {
+	level          *levels.Level
+	sx, sy, gx, gy int
+}
+
+// generatedQueries builds a few generated floors and picks random pairs of
+// walkable tiles on each.
+func generatedQueries(tb testing.TB) []query {
+	tb.Helper()
+	var qs []query
+	for seed := int64(1); seed <= 4; seed++ {
+		l := levels.Generate64x64(levels.GenParams{Seed: seed, Width: 64, Height: 64})
+		var open [][2]int
+		for y := 0; y < l.H; y++ {
+			for x := 0; x < l.W; x++ {
+				if l.IsWalkable(x, y) {
+					open = append(open, [2]int{x, y})
+				}
+			}
+		}
+		if len(open) < 2 {
+			tb.Fatalf("seed %d: floor has no walkable tiles", seed)
+		}
+		rng := rand.New(rand.NewPCG(uint64(seed), 0))
+		for i := 0; i < 32; i++ {
+			a, b := open[rng.IntN(len(open))], open[rng.IntN(len(open))]
+			qs = append(qs, query{l, a[0], a[1], b[0], b[1]})
+		}
+	}
+	return qs
+}
+
+// pathCost sums the step costs of path from (sx, sy), or -1 for no path.
+func pathCost(l *levels.Level, sx, sy int, path []PathNode) int {
+	if path == nil {
+		return -1
+	}
+	cost := 0
+	x, y := sx, sy
+	for _, p := range path {
+		if p.X != x && p.Y != y {
+			cost += 14
+		} else {
+			cost += 10
+		}
+		cost += l.Tile(p.X, p.Y).HazardCost()
+		x, y = p.X, p.Y
+	}
+	return cost
+}
+
+func TestAStarMatchesLegacy(t *testing.T) {
+	qs := generatedQueries(t)
+	check := func(stage string) {
+		for _, q := range qs {
+			want := pathCost(q.level, q.sx, q.sy, legacyAStar(q.level, q.sx, q.sy, q.gx, q.gy))
+			got := pathCost(q.level, q.sx, q.sy, AStar(q.level, q.sx, q.sy, q.gx, q.gy))
+			if got != want {
+				t.Errorf("%s: (%d,%d)->(%d,%d) cost %d, legacy %d", stage, q.sx, q.sy, q.gx, q.gy, got, want)
+			}
+		}
+	}
+	check("fresh")
+	check("cached")
+
+	// Closing every door must not leave paths through them in the cache.
+	closed := map[*levels.Level]bool{}
+	for _, q := range qs {
+		if closed[q.level] {
+			continue
+		}
+		closed[q.level] = true
+		for _, row := range q.level.Tiles {
+			for _, tile := range row {
+				if tile.HasTag(tiles.TagDoor) {
+					tile.DoorState = 2
+					tile.IsWalkable = false
+				}
+			}
+		}
+	}
+	check("doors closed")
+}
+
+func BenchmarkAStarLegacy(b *testing.B) {
+	qs := generatedQueries(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q := qs[i%len(qs)]
+		legacyAStar(q.level, q.sx, q.sy, q.gx, q.gy)
+	}
+}
+
+func BenchmarkAStarUncached(b *testing.B) {
+	qs := generatedQueries(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q := qs[i%len(qs)]
+		gridFor(q.level).search(q.level, q.sx, q.sy, q.gx, q.gy, false)
+	}
+}
+
+func BenchmarkAStarCached(b *testing.B) {
+	qs := generatedQueries(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q := qs[i%len(qs)]
+		AStar(q.level, q.sx, q.sy, q.gx, q.gy)
+	}
+}
+
+func TestAStarThroughDoors(t *testing.T) {
+	// A corridor with a door in the middle.
+	l := levels.NewEmptyLevel(7, 3)
+	for x := 1; x <= 5; x++ {
+		l.Tiles[1][x].IsWalkable = true
+	}
+	door := l.Tiles[1][3]
+	door.SetTag(tiles.TagDoor)
+	door.DoorState = 2
+	door.IsWalkable = false
+
+	if p := AStar(l, 1, 1, 5, 1); p != nil {
+		t.Fatalf("AStar went through a closed door: %v", p)
+	}
+	if p := AStarThroughDoors(l, 1, 1, 5, 1); len(p) != 4 {
+		t.Fatalf("AStarThroughDoors = %v, want the 4 steps down the corridor", p)
+	}
+
+	door.DoorState = 3
+	if p := AStarThroughDoors(l, 1, 1, 5, 1); p != nil {
+		t.Fatalf("AStarThroughDoors went through a locked door: %v", p)
+	}
+}
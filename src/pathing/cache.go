@@ -0,0 +1,57 @@
+package pathing
+
+// pathCacheSize is how many recent paths each level remembers. When it is
+// full the oldest path is forgotten.
+const pathCacheSize = 256
+
+type pathKey struct {
+	sx, sy, gx, gy int
+	throughDoors   bool
+}
+
+// pathCache remembers recent AStar results for one level, including failed
+// searches, which are the most expensive to repeat. Paths are only valid for
+// the door states they were found with.
+type pathCache struct {
+	cache map[pathKey][]PathNode
+	order []pathKey // ring of cached keys, oldest at next
+	next  int
+	doors doorWatch
+}
+
+// remember caches path under key, forgetting the oldest path if full.
+func (c *pathCache) remember(key pathKey, path []PathNode) {
+	if c.cache == nil {
+		c.cache = make(map[pathKey][]PathNode, pathCacheSize)
+	}
+	if len(c.order) < pathCacheSize {
+		c.order = append(c.order, key)
+	} else {
+		delete(c.cache, c.order[c.next])
+		c.order[c.next] = key
+		c.next = (c.next + 1) % pathCacheSize
+	}
+	c.cache[key] = path
+}
+
+// forget drops every cached path.
+func (c *pathCache) forget() {
+	clear(c.cache)
+	c.order = c.order[:0]
+	c.next = 0
+}
+
+// geometryGen counts InvalidateCache calls, so flow fields built before one
+// know to rebuild.
+var geometryGen uint32
+
+// InvalidateCache drops every cached path and flow field and re-reads each
+// level's doors. Must be called whenever walls or floor change outside of
+// door toggles (e.g. a wall breaking or the floor collapsing).
+func InvalidateCache() {
+	for level, gr := range grids {
+		gr.forget()
+		gr.doors.track(level)
+	}
+	geometryGen++
+}
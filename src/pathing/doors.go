@@ -0,0 +1,48 @@
+package pathing
+
+import (
+	"dungeoneer/levels"
+	"dungeoneer/tiles"
+)
+
+// doorWatch remembers the state of a level's doors so that a result worked
+// out earlier can tell when one has been opened, closed or locked since.
+type doorWatch struct {
+	cells []int32 // y*W+x of each door tile
+	state []uint8 // doorKey of each door when last checked
+}
+
+// doorKey packs what about a door tile affects paths through it.
+func doorKey(t *tiles.Tile) uint8 {
+	k := t.DoorState << 1
+	if t.IsWalkable {
+		k |= 1
+	}
+	return k
+}
+
+// track records the level's door tiles and their current states.
+func (d *doorWatch) track(level *levels.Level) {
+	d.cells, d.state = d.cells[:0], d.state[:0]
+	for y := 0; y < level.H; y++ {
+		for x := 0; x < level.W; x++ {
+			if t := level.Tiles[y][x]; t != nil && t.HasTag(tiles.TagDoor) {
+				d.cells = append(d.cells, int32(y*level.W+x))
+				d.state = append(d.state, doorKey(t))
+			}
+		}
+	}
+}
+
+// changed reports whether any door changed state since the last check.
+func (d *doorWatch) changed(level *levels.Level) bool {
+	changed := false
+	for i, cell := range d.cells {
+		k := doorKey(level.Tiles[int(cell)/level.W][int(cell)%level.W])
+		if k != d.state[i] {
+			d.state[i] = k
+			changed = true
+		}
+	}
+	return changed
+}
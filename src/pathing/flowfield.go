@@ -0,0 +1,132 @@
+package pathing
+
+import "dungeoneer/levels"
+
+// FlowField holds the cost of walking from every tile to one target tile,
+// worked out with a single Dijkstra pass under the same step costs as AStar.
+// Any number of monsters chasing the target read their next step from it in
+// O(1) instead of each running its own search. The zero value is ready to
+// use.
+type FlowField struct {
+	level            *levels.Level
+	targetX, targetY int
+	dist             []int32 // cost to the target; -1 where it can't be reached
+	open             openHeap
+	doors            doorWatch
+	gen              uint32 // geometryGen when built
+}
+
+// Update points the field at (tx, ty) on level. The field is only rebuilt
+// when the target tile, the level or a door has changed, or InvalidateCache
+// was called since the last build; it reports whether it rebuilt.
+func (f *FlowField) Update(level *levels.Level, tx, ty int) bool {
+	if level == nil || tx < 0 || ty < 0 || tx >= level.W || ty >= level.H {
+		return false
+	}
+	if f.level == level && f.targetX == tx && f.targetY == ty && f.gen == geometryGen &&
+		!f.doors.changed(level) {
+		return false
+	}
+	if f.level != level || f.gen != geometryGen {
+		f.doors.track(level)
+	}
+	f.level, f.targetX, f.targetY, f.gen = level, tx, ty, geometryGen
+	f.rebuild()
+	return true
+}
+
+// rebuild runs Dijkstra outward from the target. Costs are those of walking
+// toward the target, so a step pays the hazard cost of the tile it lands on.
+func (f *FlowField) rebuild() {
+	l := f.level
+	n := l.W * l.H
+	if cap(f.dist) < n {
+		f.dist = make([]int32, n)
+	}
+	f.dist = f.dist[:n]
+	for i := range f.dist {
+		f.dist[i] = -1
+	}
+	target := int32(f.targetY*l.W + f.targetX)
+	f.dist[target] = 0
+	f.open.items = f.open.items[:0]
+	f.open.push(target, 0, 0)
+
+	for f.open.len() > 0 {
+		item := f.open.pop()
+		if item.f > f.dist[item.cell] {
+			continue // stale entry
+		}
+		cx, cy := int(item.cell)%l.W, int(item.cell)/l.W
+		stepCost := int(item.f) + l.Tiles[cy][cx].HazardCost()
+		for _, dir := range neighbours {
+			dx, dy, moveCost := dir[0], dir[1], dir[2]
+			nx, ny := cx+dx, cy+dy
+			if !canStep(l, nx, ny, -dx, -dy) {
+				continue
+			}
+			i := int32(ny*l.W + nx)
+			d := stepCost + moveCost
+			if f.dist[i] < 0 || d < int(f.dist[i]) {
+				f.dist[i] = int32(d)
+				f.open.push(i, d, 0)
+			}
+		}
+	}
+}
+
+// canStep reports whether a walker on (x, y) may step by (dx, dy): both tiles
+// walkable and, for a diagonal, no corner cut between two walls.
+func canStep(l *levels.Level, x, y, dx, dy int) bool {
+	if !l.IsWalkable(x, y) || !l.IsWalkable(x+dx, y+dy) {
+		return false
+	}
+	return dx == 0 || dy == 0 || l.IsWalkable(x+dx, y) && l.IsWalkable(x, y+dy)
+}
+
+// Dist returns the walking cost from (x, y) to the target, or -1 if the
+// target can't be reached from there.
+func (f *FlowField) Dist(x, y int) int {
+	if f.level == nil || x < 0 || y < 0 || x >= f.level.W || y >= f.level.H {
+		return -1
+	}
+	return int(f.dist[y*f.level.W+x])
+}
+
+// Covers reports whether the field was built for level and leads from (x, y)
+// to the target.
+func (f *FlowField) Covers(level *levels.Level, x, y int) bool {
+	return f.level == level && f.Dist(x, y) >= 0
+}
+
+// Next returns the neighbour of (x, y) to step onto to get closer to the
+// target. Only neighbours nearer the target than (x, y) are considered, and
+// the one with the lowest total cost through it plus penalty wins; penalty
+// may be nil, and a negative penalty rules a tile out. ok is false at the
+// target, or when every way on is ruled out.
+func (f *FlowField) Next(x, y int, penalty func(x, y int) int) (nx, ny int, ok bool) {
+	here := f.Dist(x, y)
+	if here <= 0 {
+		return x, y, false
+	}
+	best := -1
+	for _, dir := range neighbours {
+		tx, ty := x+dir[0], y+dir[1]
+		d := f.Dist(tx, ty)
+		if d < 0 || d >= here || !canStep(f.level, x, y, dir[0], dir[1]) {
+			continue
+		}
+		d += dir[2] + f.level.Tiles[ty][tx].HazardCost()
+		if penalty != nil {
+			p := penalty(tx, ty)
+			if p < 0 {
+				continue
+			}
+			d += p
+		}
+		if best < 0 || d < best {
+			best, nx, ny = d, tx, ty
+		}
+	}
+	return nx, ny, best >= 0
+}
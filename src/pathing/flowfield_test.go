@@ -0,0 +1,38 @@
+package pathing
+
+import "testing"
+
+func TestFlowFieldMatchesAStar(t *testing.T) {
+	var f FlowField
+	for _, q := range generatedQueries(t) {
+		f.Update(q.level, q.gx, q.gy)
+		want := pathCost(q.level, q.sx, q.sy, AStar(q.level, q.sx, q.sy, q.gx, q.gy))
+		if got := f.Dist(q.sx, q.sy); got != want {
+			t.Errorf("(%d,%d)->(%d,%d): field cost %d, A* cost %d", q.sx, q.sy, q.gx, q.gy, got, want)
+		}
+		// Walking the field must reach the goal at the cost it promised.
+		x, y, cost := q.sx, q.sy, 0
+		for f.Dist(x, y) > 0 {
+			nx, ny, ok := f.Next(x, y, nil)
+			if !ok {
+				t.Fatalf("(%d,%d)->(%d,%d): field stuck at (%d,%d)", q.sx, q.sy, q.gx, q.gy, x, y)
+			}
+			cost += pathCost(q.level, x, y, []PathNode{{X: nx, Y: ny}})
+			x, y = nx, ny
+		}
+		if want >= 0 && cost != want {
+			t.Errorf("(%d,%d)->(%d,%d): walked cost %d, want %d", q.sx, q.sy, q.gx, q.gy, cost, want)
+		}
+	}
+}
+
+func BenchmarkFlowFieldRebuild(b *testing.B) {
+	qs := generatedQueries(b)
+	var f FlowField
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q := qs[i%len(qs)]
+		f.Update(q.level, q.gx, q.gy)
+	}
+}
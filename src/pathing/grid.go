@@ -0,0 +1,125 @@
+package pathing
+
+import "dungeoneer/levels"
+
+// maxGrids caps how many levels keep a search grid. A run only has a floor's
+// layers and the hub in play at once; past the cap every grid is dropped and
+// rebuilt on demand.
+const maxGrids = 8
+
+var grids = map[*levels.Level]*grid{}
+
+// grid is the reusable A* state for one level. Cells are indexed y*w+x.
+// Instead of clearing the arrays before every search, each search bumps gen
+// and a cell only counts as seen or closed when its stamp matches.
+type grid struct {
+	w, h   int
+	g      []int32  // cost from the start
+	parent []int32  // previous cell on the best path; -1 at the start
+	seen   []uint32 // gen when g and parent were last set
+	closed []uint32 // gen when the cell was expanded
+	gen    uint32
+	open   openHeap
+
+	pathCache
+}
+
+// gridFor returns the search grid of level, building it on first use.
+func gridFor(level *levels.Level) *grid {
+	gr := grids[level]
+	if gr != nil && gr.w == level.W && gr.h == level.H {
+		return gr
+	}
+	if len(grids) >= maxGrids {
+		grids = map[*levels.Level]*grid{}
+	}
+	n := level.W * level.H
+	gr = &grid{
+		w:      level.W,
+		h:      level.H,
+		g:      make([]int32, n),
+		parent: make([]int32, n),
+		seen:   make([]uint32, n),
+		closed: make([]uint32, n),
+	}
+	gr.doors.track(level)
+	grids[level] = gr
+	return gr
+}
+
+func (gr *grid) index(x, y int) int32 {
+	return int32(y*gr.w + x)
+}
+
+// reset starts a new search.
+func (gr *grid) reset() {
+	gr.gen++
+	if gr.gen == 0 {
+		// The stamps wrapped; clear them so old ones can't match.
+		clear(gr.seen)
+		clear(gr.closed)
+		gr.gen = 1
+	}
+	gr.open.items = gr.open.items[:0]
+}
+
+// visit records a new best cost and parent for cell i.
+func (gr *grid) visit(i int32, g int, parent int32) {
+	gr.g[i] = int32(g)
+	gr.parent[i] = parent
+	gr.seen[i] = gr.gen
+}
+
+// openItem is an entry in the open list. Ties on f go to the entry nearer
+// the goal, which expands fewer nodes on open floors.
+type openItem struct {
+	cell int32
+	f, h int32
+}
+
+func (a openItem) less(b openItem) bool {
+	return a.f < b.f || a.f == b.f && a.h < b.h
+}
+
+// openHeap is a binary min-heap of open list entries.
+type openHeap struct {
+	items []openItem
+}
+
+func (o *openHeap) len() int { return len(o.items) }
+
+func (o *openHeap) push(cell int32, f, h int) {
+	o.items = append(o.items, openItem{cell: cell, f: int32(f), h: int32(h)})
+	i := len(o.items) - 1
+	for i > 0 {
+		p := (i - 1) / 2
+		if !o.items[i].less(o.items[p]) {
+			break
+		}
+		o.items[i], o.items[p] = o.items[p], o.items[i]
+		i = p
+	}
+}
+
+func (o *openHeap) pop() openItem {
+	top := o.items[0]
+	last := len(o.items) - 1
+	o.items[0] = o.items[last]
+	o.items = o.items[:last]
+	i := 0
+	for {
+		l, r := 2*i+1, 2*i+2
+		m := i
+		if l < last && o.items[l].less(o.items[m]) {
+			m = l
+		}
+		if r < last && o.items[r].less(o.items[m]) {
+			m = r
+		}
+		if m == i {
+			return top
+		}
+		o.items[i], o.items[m] = o.items[m], o.items[i]
+		i = m
+	}
+}
@@ -0,0 +1,36 @@
+// Package simrand is the shared random source for in-run simulation: monster
+// AI, spell scatter and anything else rolled during a tick. Seeding it at run
+// start makes a run reproducible from its inputs alone.
+package simrand
+
+import "math/rand/v2"
+
+var (
+	src = rand.NewPCG(rand.Uint64(), rand.Uint64())
+	rng = rand.New(src)
+)
+
+// Seed resets the source to a known state.
+func Seed(seed1, seed2 uint64) {
+	src.Seed(seed1, seed2)
+}
+
+// State returns the serialised source position.
+func State() []byte {
+	b, _ := src.MarshalBinary()
+	return b
+}
+
+// Restore rewinds the source to a position returned by State.
+func Restore(state []byte) error {
+	return src.UnmarshalBinary(state)
+}
+
+// IntN returns a uniform int in [0, n). It panics if n <= 0.
+func IntN(n int) int { return rng.IntN(n) }
+
+// Float64 returns a uniform float64 in [0, 1).
+func Float64() float64 { return rng.Float64() }
+
+// Shuffle pseudo-randomizes the order of n elements.
+func Shuffle(n int, swap func(i, j int)) { rng.Shuffle(n, swap) }
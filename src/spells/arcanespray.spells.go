@@ -3,9 +3,9 @@ package spells
 import (
 	"image/color"
 	"math"
-	"math/rand/v2"
 
 	"dungeoneer/levels"
+	"dungeoneer/simrand"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -94,16 +94,16 @@ func (as *ArcaneSpray) Update(level *levels.Level, dt float64) {
 	// Spawn new particles while channeling.
 	if as.Channeling {
 		// Spawn 2-3 particles per frame.
-		for range 2 + rand.IntN(2) {
-			angle := as.DirAngle + (rand.Float64()*2-1)*as.ConeAngle
-			speed := 6 + rand.Float64()*4
+		for range 2 + simrand.IntN(2) {
+			angle := as.DirAngle + (simrand.Float64()*2-1)*as.ConeAngle
+			speed := 6 + simrand.Float64()*4
 			as.Particles = append(as.Particles, sprayParticle{
 				X:       as.OriginX,
 				Y:       as.OriginY,
 				DirX:    math.Cos(angle),
 				DirY:    math.Sin(angle),
 				Speed:   speed,
-				MaxLife: 0.45 + rand.Float64()*0.2,
+				MaxLife: 0.45 + simrand.Float64()*0.2,
 			})
 		}
 	}
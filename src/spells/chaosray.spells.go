@@ -3,9 +3,9 @@ package spells
 import (
 	"image/color"
 	"math"
-	"math/rand/v2"
 
 	"dungeoneer/levels"
+	"dungeoneer/simrand"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -53,7 +53,7 @@ func generateJaggedLightningPath(x1, y1, x2, y2 float64) []Point {
 	for i := 1; i < segments; i++ {
 		t := float64(i) / float64(segments)
 		// Apply jitter to t (non-uniform spacing)
-		t += (rand.Float64()*2 - 1) * 0.05
+		t += (simrand.Float64()*2 - 1) * 0.05
 		if t < 0 {
 			t = 0
 		}
@@ -64,8 +64,8 @@ func generateJaggedLightningPath(x1, y1, x2, y2 float64) []Point {
 		py := y1 + dy*t
 
 		// Random offset perpendicular to direction
-		magnitude := 0.2 + 0.3*rand.Float64()
-		offset := (rand.Float64()*2 - 1) * magnitude
+		magnitude := 0.2 + 0.3*simrand.Float64()
+		offset := (simrand.Float64()*2 - 1) * magnitude
 
 		px += nx * offset
 		py += ny * offset
@@ -2,9 +2,9 @@ package spells
 
 import (
 	"math"
-	"math/rand"
 
 	"dungeoneer/levels"
+	"dungeoneer/simrand"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -107,7 +107,7 @@ func (fb *FractalBloom) buildNodes(x, y float64, dmg int, radius int, depth int,
 	childRadius := radius - 1
 	childDamage := int(float64(dmg) * dropoff)
 	for _, d := range dirs {
-		if rand.Float64() > 0.7 {
+		if simrand.Float64() > 0.7 {
 			continue
 		}
 		tx := int(math.Round(x)) + d[0]
@@ -2,9 +2,9 @@ package spells
 
 import (
 	"image/color"
-	"math/rand"
 
 	"dungeoneer/levels"
+	"dungeoneer/simrand"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -14,11 +14,15 @@ type lightningPoint struct {
 	X, Y float64
 }
 
+// boltSegments is how many points the drawn bolt zigzags through.
+const boltSegments = 10
+
 // LightningStrike represents a vertical bolt that strikes a tile from above.
 type LightningStrike struct {
 	Info          SpellInfo
 	X, Y          float64
 	points        []lightningPoint
+	jitter        [boltSegments]float64 // bolt zigzag, rerolled each tick
 	age           float64
 	Duration      float64
 	DamageApplied bool
@@ -28,14 +32,14 @@ type LightningStrike struct {
 
 // NewLightningStrike creates a new lightning strike spell targeting the given tile.
 func NewLightningStrike(info SpellInfo, targetX, targetY float64, impact *ebiten.Image) *LightningStrike {
-	startY := targetY - 5 - rand.Float64()*5
+	startY := targetY - 5 - simrand.Float64()*5
 	segments := 6
 	pts := make([]lightningPoint, segments+1)
 	pts[0] = lightningPoint{X: targetX, Y: startY}
 	for i := 1; i < segments; i++ {
 		t := float64(i) / float64(segments)
 		py := startY + (targetY-startY)*t
-		px := targetX + (rand.Float64()-0.5)*0.5
+		px := targetX + (simrand.Float64()-0.5)*0.5
 		pts[i] = lightningPoint{X: px, Y: py}
 	}
 	pts[segments] = lightningPoint{X: targetX, Y: targetY}
@@ -57,6 +61,13 @@ func (l *LightningStrike) Update(level *levels.Level, dt float64) {
 	if l.age >= l.Duration {
 		l.Finished = true
 	}
+	// The zigzag is rolled here rather than in Draw so rendering never
+	// touches the simulation RNG.
+	if l.age <= l.Duration*0.2 {
+		for i := range l.jitter {
+			l.jitter[i] = simrand.Float64() - 0.5
+		}
+	}
 }
 
 func (l *LightningStrike) Draw(screen *ebiten.Image, tileSize int, camX, camY, camScale, cx, cy float64) {
@@ -75,14 +86,13 @@ func (l *LightningStrike) Draw(screen *ebiten.Image, tileSize int, camX, camY, c
 
 		centerX, centerY := screenCoordsFromWorldTile(l.X, l.Y, tileSize, camX, camY, camScale, cx, cy)
 
-		const segmentCount = 10
 		const strikeHeight = 150.0
 		const jitter = 10.0
 
-		screenSegments := make([]Point, segmentCount)
-		for i := 0; i < segmentCount; i++ {
-			t := float64(i) / float64(segmentCount-1)
-			sx := centerX + (rand.Float64()-0.5)*jitter
+		screenSegments := make([]Point, boltSegments)
+		for i := 0; i < boltSegments; i++ {
+			t := float64(i) / float64(boltSegments-1)
+			sx := centerX + l.jitter[i]*jitter
 			sy := centerY - t*strikeHeight
 			screenSegments[i] = Point{sx, sy}
 		}
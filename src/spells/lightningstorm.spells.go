@@ -3,9 +3,9 @@ package spells
 import (
 	"image/color"
 	"math"
-	"math/rand"
 
 	"dungeoneer/levels"
+	"dungeoneer/simrand"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -75,7 +75,7 @@ func (ls *LightningStorm) Update(level *levels.Level, dt float64) {
 		}
 
 		// Pick a random tile in the AOE zone
-		idx := rand.Intn(len(ls.validStrikeTiles))
+		idx := simrand.IntN(len(ls.validStrikeTiles))
 		tile := ls.validStrikeTiles[idx]
 
 		// Ensure the tile is walkable or visually allowed
@@ -0,0 +1,42 @@
+package tiles
+
+// Hazard types. A hazard tile stays walkable; entities standing on it take
+// damage or a status effect (see entities.ApplyHazard) and pathfinding treats
+// it as expensive rather than blocked.
+const (
+	HazardNone   uint8 = 0
+	HazardLava   uint8 = 1
+	HazardWater  uint8 = 2 // deep water
+	HazardSpikes uint8 = 3
+	HazardGas    uint8 = 4 // poison gas
+)
+
+// HazardInfo describes a hazard type.
+type HazardInfo struct {
+	Name     string // used in data files, e.g. "lava"
+	SpriteID string // sprite painted over the floor
+	PathCost int    // extra A* cost of stepping onto the tile (a plain step is 10)
+}
+
+// Hazards maps each hazard type to its description.
+var Hazards = map[uint8]HazardInfo{
+	HazardLava:   {Name: "lava", SpriteID: "LavaPool", PathCost: 60},
+	HazardWater:  {Name: "water", SpriteID: "DeepWater", PathCost: 20},
+	HazardSpikes: {Name: "spikes", SpriteID: "Spikes", PathCost: 30},
+	HazardGas:    {Name: "gas", SpriteID: "PoisonGas", PathCost: 40},
+}
+
+// HazardByName returns the hazard type with the given name.
+func HazardByName(name string) (uint8, bool) {
+	for h, info := range Hazards {
+		if info.Name == name {
+			return h, true
+		}
+	}
+	return HazardNone, false
+}
+
+// HazardCost returns the extra pathing cost of stepping onto the tile.
+func (t *Tile) HazardCost() int {
+	return Hazards[t.Hazard].PathCost
+}
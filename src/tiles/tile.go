@@ -22,6 +22,8 @@ type Tile struct {
 	// Door state: 0 = no door, 1 = open, 2 = closed, 3 = locked
 	DoorState  uint8
 	DoorSpriteID string // ID of door sprite (for removal/changing)
+	Hazard       uint8  // HazardNone, HazardLava, ...; see hazard.go
+	WallHP       uint8  // hits a cracked wall takes before it breaks; 0 = solid
 }
 
 type SpriteRef struct {
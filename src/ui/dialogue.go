@@ -6,7 +6,6 @@ import (
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"golang.org/x/image/font/basicfont"
@@ -93,7 +92,7 @@ func (dp *DialoguePanel) Resize(w, h int) {
 }
 
 // Update handles input for the dialogue panel.
-func (dp *DialoguePanel) Update() {
+func (dp *DialoguePanel) Update(in Input) {
 	if !dp.Active || dp.CurrentNode == nil {
 		return
 	}
@@ -106,10 +105,10 @@ func (dp *DialoguePanel) Update() {
 		}
 	}
 
-	mx, my := ebiten.CursorPosition()
+	mx, my := in.CursorPosition()
 
 	// Click handling
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+	if in.MouseJustPressed(ebiten.MouseButtonLeft) {
 		// If typewriter still going, skip to end
 		if !dp.TextDone {
 			dp.TextProgress = float64(len(dp.CurrentNode.Text))
@@ -133,7 +132,7 @@ func (dp *DialoguePanel) Update() {
 	}
 
 	// Escape to close (only when no mandatory responses)
-	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+	if in.KeyJustPressed(ebiten.KeyEscape) {
 		dp.Close()
 		return
 	}
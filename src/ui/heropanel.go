@@ -8,7 +8,6 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 // HeroPanel displays player stats and allows spending attribute points.
@@ -38,12 +37,12 @@ func (hp *HeroPanel) Hide()                        { hp.visible = false }
 func (hp *HeroPanel) Toggle()                      { hp.visible = !hp.visible }
 func (hp *HeroPanel) IsVisible() bool              { return hp.visible }
 
-func (hp *HeroPanel) Update() {
+func (hp *HeroPanel) Update(in Input) {
 	if !hp.visible || hp.player == nil {
 		return
 	}
-	mx, my := ebiten.CursorPosition()
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+	mx, my := in.CursorPosition()
+	if in.MouseJustPressed(ebiten.MouseButtonLeft) {
 		for attr, r := range hp.plus {
 			if pointInRect(mx, my, r) && hp.player.UnspentPoints > 0 {
 				switch attr {
@@ -0,0 +1,13 @@
+package ui
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// Input is what the in-run panels (inventory, dialogue, hero panel) read
+// instead of polling Ebiten's devices, so the game can record their input
+// for replays and script it in tests.
+type Input interface {
+	KeyJustPressed(key ebiten.Key) bool
+	MousePressed(button ebiten.MouseButton) bool
+	MouseJustPressed(button ebiten.MouseButton) bool
+	CursorPosition() (int, int)
+}
@@ -9,7 +9,6 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/basicfont"
@@ -73,30 +72,31 @@ func NewInventoryScreen() *InventoryScreen {
 	}
 }
 
-func (s *InventoryScreen) Open() {
+// Open shows the screen, laid out for a screen h pixels high.
+func (s *InventoryScreen) Open(h int) {
 	s.Active = true
-	_, h := ebiten.WindowSize()
 	s.YOffset = h * 30 / 100
 }
 func (s *InventoryScreen) Close() { s.Active = false }
 
-// Update handles mouse and keyboard input while the screen is open.
-func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop func(*items.Item)) {
+// Update handles mouse and keyboard input while the screen is open. use
+// applies a usable item, reporting whether it was used up.
+func (s *InventoryScreen) Update(in Input, p *entities.Player, hint func(string), drop func(*items.Item), use func(*items.Item) bool) {
 	if !s.Active || p == nil || p.Inventory == nil {
 		return
 	}
-	mx, my := ebiten.CursorPosition()
+	mx, my := in.CursorPosition()
 
 	if s.confirmActive {
 		s.confirmHover = -1
-		msg := fmt.Sprintf("Are you sure you want to destroy %s?", s.confirmItem.Name)
+		msg := fmt.Sprintf("Are you sure you want to destroy %s?", s.confirmItem.DisplayName())
 		lines := WrapText(msg, 32)
 		btnY := s.confirmPos.Y + 20 + len(lines)*16
 		yes := image.Rect(s.confirmPos.X+20, btnY, s.confirmPos.X+80, btnY+16)
 		no := image.Rect(s.confirmPos.X+110, btnY, s.confirmPos.X+170, btnY+16)
 		if mx >= yes.Min.X && mx <= yes.Max.X && my >= yes.Min.Y && my <= yes.Max.Y {
 			s.confirmHover = 0
-			if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			if in.MouseJustPressed(ebiten.MouseButtonLeft) {
 				if s.confirmSlot != "" {
 					p.DropEquipped(s.confirmSlot)
 				} else if s.confirmTargetY >= 0 {
@@ -110,7 +110,7 @@ func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop fun
 			}
 		} else if mx >= no.Min.X && mx <= no.Max.X && my >= no.Min.Y && my <= no.Max.Y {
 			s.confirmHover = 1
-			if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			if in.MouseJustPressed(ebiten.MouseButtonLeft) {
 				s.confirmActive = false
 				s.confirmItem = nil
 				s.confirmSlot = ""
@@ -118,7 +118,7 @@ func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop fun
 				return
 			}
 		}
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		if in.MouseJustPressed(ebiten.MouseButtonRight) || in.KeyJustPressed(ebiten.KeyEscape) {
 			s.confirmActive = false
 			s.confirmItem = nil
 			s.confirmSlot = ""
@@ -133,7 +133,7 @@ func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop fun
 			r := image.Rect(s.menuPos.X, s.menuPos.Y+i*16, s.menuPos.X+80, s.menuPos.Y+(i+1)*16)
 			if mx >= r.Min.X && mx <= r.Max.X && my >= r.Min.Y && my <= r.Max.Y {
 				s.menuHover = i
-				if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+				if in.MouseJustPressed(ebiten.MouseButtonLeft) {
 					switch opt {
 					case "Equip":
 						it := p.Inventory.Grid[s.menuTargetY][s.menuTargetX]
@@ -143,6 +143,16 @@ func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop fun
 								hint("Cannot equip")
 							}
 						}
+					case "Use":
+						it := p.Inventory.Grid[s.menuTargetY][s.menuTargetX]
+						if it != nil && use != nil && use(it) {
+							p.ConsumeItem(it.ID)
+						}
+					case "To Belt":
+						it := p.Inventory.Grid[s.menuTargetY][s.menuTargetX]
+						if it != nil && !p.AssignBelt(it.ID) && hint != nil {
+							hint("Belt is full")
+						}
 					case "Drop":
 						var it *items.Item
 						if s.menuSlot != "" {
@@ -175,7 +185,7 @@ func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop fun
 				}
 			}
 		}
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		if in.MouseJustPressed(ebiten.MouseButtonRight) || in.KeyJustPressed(ebiten.KeyEscape) {
 			s.menuActive = false
 		}
 		return
@@ -188,14 +198,14 @@ func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop fun
 		s.HoverGridX, s.HoverGridY = gx, gy
 	}
 
-	if s.HoverGridX >= 0 && s.HoverGridY >= 0 && inpututil.IsKeyJustPressed(ebiten.KeyD) {
+	if s.HoverGridX >= 0 && s.HoverGridY >= 0 && in.KeyJustPressed(ebiten.KeyD) {
 		if it := p.DropFromInventory(s.HoverGridX, s.HoverGridY, 1); it != nil && drop != nil {
 			drop(it)
 		}
 	}
 
 	if s.Dragging {
-		if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
+		if !in.MousePressed(ebiten.MouseButtonLeft) {
 			if s.HoverGridX >= 0 && s.HoverGridY >= 0 {
 				dest := p.Inventory.Grid[s.HoverGridY][s.HoverGridX]
 				if s.DragFromSlot != "" {
@@ -265,7 +275,7 @@ func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop fun
 			s.DragItem = nil
 			s.DragFromSlot = ""
 		}
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		if in.MouseJustPressed(ebiten.MouseButtonRight) {
 			if s.DragFromSlot != "" {
 				p.Equipment[s.DragFromSlot] = s.DragItem
 				if s.DragItem.OnEquip != nil {
@@ -279,7 +289,7 @@ func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop fun
 			s.DragItem = nil
 			s.DragFromSlot = ""
 		}
-		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		if in.KeyJustPressed(ebiten.KeyEscape) {
 			if s.DragFromSlot != "" {
 				p.Equipment[s.DragFromSlot] = s.DragItem
 				if s.DragItem.OnEquip != nil {
@@ -296,7 +306,7 @@ func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop fun
 		return
 	}
 
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+	if in.MouseJustPressed(ebiten.MouseButtonLeft) {
 		if s.HoverGridX >= 0 {
 			it := p.Inventory.Grid[s.HoverGridY][s.HoverGridX]
 			if it != nil {
@@ -327,7 +337,7 @@ func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop fun
 			}
 		}
 	}
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+	if in.MouseJustPressed(ebiten.MouseButtonRight) {
 		if s.HoverGridX >= 0 {
 			it := p.Inventory.Grid[s.HoverGridY][s.HoverGridX]
 			if it != nil {
@@ -339,6 +349,9 @@ func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop fun
 				if it.Equippable {
 					s.menuOpts = append(s.menuOpts, "Equip")
 				}
+				if it.Usable {
+					s.menuOpts = append(s.menuOpts, "Use", "To Belt")
+				}
 				s.menuOpts = append(s.menuOpts, "Drop", "Destroy")
 			}
 		} else {
@@ -359,7 +372,7 @@ func (s *InventoryScreen) Update(p *entities.Player, hint func(string), drop fun
 		}
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+	if in.KeyJustPressed(ebiten.KeyEscape) || in.KeyJustPressed(ebiten.KeyTab) {
 		s.Close()
 	}
 }
@@ -449,7 +462,7 @@ func (s *InventoryScreen) Draw(dst *ebiten.Image, p *entities.Player) {
 	// Tooltip on hovered grid cell
 	if s.HoverGridX >= 0 && s.HoverGridY >= 0 && !s.menuActive && !s.confirmActive {
 		if it := p.Inventory.Grid[s.HoverGridY][s.HoverGridX]; it != nil {
-			DrawItemTooltip(dst, it, mx+16, my+16)
+			DrawItemTooltip(dst, it, p.Equipment, mx+16, my+16)
 		}
 	}
 
@@ -459,7 +472,7 @@ func (s *InventoryScreen) Draw(dst *ebiten.Image, p *entities.Player) {
 			rr := r.Add(image.Pt(0, s.YOffset))
 			if mx >= rr.Min.X && mx <= rr.Max.X && my >= rr.Min.Y && my <= rr.Max.Y {
 				if it := p.Equipment[slot]; it != nil {
-					DrawItemTooltip(dst, it, mx+16, my+16)
+					DrawItemTooltip(dst, it, p.Equipment, mx+16, my+16)
 				}
 				break
 			}
@@ -491,7 +504,7 @@ func (s *InventoryScreen) Draw(dst *ebiten.Image, p *entities.Player) {
 	}
 
 	if s.confirmActive {
-		msg := fmt.Sprintf("Are you sure you want to destroy %s?", s.confirmItem.Name)
+		msg := fmt.Sprintf("Are you sure you want to destroy %s?", s.confirmItem.DisplayName())
 		lines := WrapText(msg, 32)
 		width := 260
 		height := 40 + len(lines)*16
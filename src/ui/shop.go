@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ShopMenu lists a merchant's wares and what the player can sell using a
+// ScrollList. The game builds the options, and rebuilds them after every
+// trade so prices and purses stay current.
+type ShopMenu struct {
+	List *ScrollList
+}
+
+// NewShopMenu creates the shop window centred on a w x h screen.
+func NewShopMenu(w, h int) *ShopMenu {
+	mw, mh := 420, 480
+	rect := image.Rect((w-mw)/2, (h-mh)/2, (w+mw)/2, (h+mh)/2)
+	return &ShopMenu{List: NewScrollList(rect, "MERCHANT", nil, DefaultMenuStyles())}
+}
+
+func (sm *ShopMenu) Show()           { sm.List.Show() }
+func (sm *ShopMenu) Hide()           { sm.List.Hide() }
+func (sm *ShopMenu) IsVisible() bool { return sm.List.IsVisible() }
+func (sm *ShopMenu) SetRect(r image.Rectangle) {
+	sm.List.rect = r
+	sm.List.calcVisible()
+}
+
+// SetOptions replaces the listed wares, keeping the selection in place.
+func (sm *ShopMenu) SetOptions(opts []MenuOption) { sm.List.SetOptions(opts) }
+
+// SetStatus sets the footer lines, such as the player's gold.
+func (sm *ShopMenu) SetStatus(lines []string) { sm.List.SetInstructions(lines) }
+
+// Update handles selection and buying or selling the chosen line.
+func (sm *ShopMenu) Update() { sm.List.Update() }
+
+// Draw renders the shop window.
+func (sm *ShopMenu) Draw(screen *ebiten.Image) { sm.List.Draw(screen) }
@@ -19,6 +19,11 @@ type TextInputMenu struct {
 	onSubmit     func(text string)
 	onCancel     func()
 	Instructions []string
+
+	// Validate, when set, replaces the default filename check. It returns an
+	// error message to reject the input, or "" to accept it. Menus with a
+	// Validate func may also be submitted empty.
+	Validate func(text string) string
 }
 
 func NewTextInputMenu(rect image.Rectangle, title, prompt string, onSubmit func(string), onCancel func()) *TextInputMenu {
@@ -59,8 +64,13 @@ func (t *TextInputMenu) Update() {
 			t.onCancel()
 		}
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && len(t.input) > 0 {
-		if t.Title != "NEW LAYER" {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && (len(t.input) > 0 || t.Validate != nil) {
+		if t.Validate != nil {
+			if msg := t.Validate(t.input); msg != "" {
+				t.Instructions = []string{msg}
+				return
+			}
+		} else if t.Title != "NEW LAYER" {
 			if !isValidFilename(t.input) {
 				t.Instructions = []string{"Invalid filename. Use a-z, 0-9, _, -, and end with .json"}
 				return
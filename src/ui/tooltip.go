@@ -4,6 +4,7 @@ import (
 	"dungeoneer/items"
 	"fmt"
 	"image/color"
+	"slices"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text"
@@ -26,8 +27,9 @@ func QualityColor(quality string) color.RGBA {
 }
 
 // DrawItemTooltip renders an item tooltip anchored at (x, y), clamped to the
-// screen so it never overflows the window bounds.
-func DrawItemTooltip(dst *ebiten.Image, it *items.Item, x, y int) {
+// screen so it never overflows the window bounds. equipped is what the player
+// is wearing, used to highlight set members and bonuses.
+func DrawItemTooltip(dst *ebiten.Image, it *items.Item, equipped map[string]*items.Item, x, y int) {
 	const (
 		lineH  = 15
 		padX   = 6
@@ -44,7 +46,7 @@ func DrawItemTooltip(dst *ebiten.Image, it *items.Item, x, y int) {
 	var lines []tline
 
 	nameClr := QualityColor(it.Quality)
-	lines = append(lines, tline{it.Name, nameClr})
+	lines = append(lines, tline{it.DisplayName(), nameClr})
 
 	typeStr := string(it.Type)
 	if it.GrantsAbility != "" {
@@ -58,25 +60,64 @@ func DrawItemTooltip(dst *ebiten.Image, it *items.Item, x, y int) {
 		lines = append(lines, tline{it.Description, color.RGBA{220, 220, 180, 255}})
 	}
 
-	if len(it.Stats) > 0 {
-		order := []string{"Strength", "Dexterity", "Vitality", "Intelligence", "Luck"}
-		for _, stat := range order {
-			if v, ok := it.Stats[stat]; ok {
-				clr := color.RGBA{80, 220, 80, 255}
-				if v < 0 {
-					clr = color.RGBA{220, 80, 80, 255}
-				}
-				lines = append(lines, tline{fmt.Sprintf("%s %+d", stat, v), clr})
+	for _, stat := range statOrder {
+		if v, ok := it.Stats[stat]; ok {
+			clr := color.RGBA{80, 220, 80, 255}
+			if v < 0 {
+				clr = color.RGBA{220, 80, 80, 255}
+			}
+			lines = append(lines, tline{fmt.Sprintf("%s %+d", stat, v), clr})
+		}
+	}
+
+	// Rolled affixes, with the value this instance got.
+	if it.Unidentified {
+		lines = append(lines, tline{"Unidentified", color.RGBA{220, 120, 120, 255}})
+	} else {
+		for _, a := range it.Affixes {
+			if d, ok := a.Def(); ok {
+				lines = append(lines, tline{fmt.Sprintf("%s %+d (%s)", d.Stat, a.Value, d.Name), color.RGBA{130, 190, 255, 255}})
 			}
 		}
 	}
 
 	if it.Effect != nil {
-		txt := fmt.Sprintf("%s: %s %d%%", it.Effect.Trigger, it.Effect.Type, it.Effect.MagnitudePct)
-		if it.Effect.ChancePct != 0 {
-			txt += fmt.Sprintf(" (%d%% chance)", it.Effect.ChancePct)
+		lines = append(lines, tline{effectText(it.Effect), color.RGBA{200, 180, 255, 255}})
+	}
+
+	// Set section: members with the worn ones lit, then each bonus, lit
+	// once enough pieces are worn.
+	if set, ok := items.Sets[it.SetID]; ok {
+		var ids []string
+		for _, e := range equipped {
+			if e != nil {
+				ids = append(ids, e.ID)
+			}
+		}
+		worn := items.SetPieces(ids)[set.ID]
+		setClr := color.RGBA{120, 230, 160, 255}
+		dimClr := color.RGBA{110, 110, 110, 255}
+		lines = append(lines, tline{fmt.Sprintf("%s (%d/%d)", set.Name, worn, len(set.Members)), setClr})
+		for _, id := range set.Members {
+			tmpl, ok := items.Registry[id]
+			if !ok {
+				continue
+			}
+			clr := dimClr
+			if slices.Contains(ids, id) {
+				clr = color.RGBA{230, 230, 230, 255}
+			}
+			lines = append(lines, tline{"  " + tmpl.Name, clr})
+		}
+		for _, b := range set.Bonuses {
+			clr := dimClr
+			if worn >= b.Pieces {
+				clr = setClr
+			}
+			for _, txt := range setBonusText(b) {
+				lines = append(lines, tline{fmt.Sprintf("(%d) %s", b.Pieces, txt), clr})
+			}
 		}
-		lines = append(lines, tline{txt, color.RGBA{200, 180, 255, 255}})
 	}
 
 	// Measure width and height.
@@ -107,3 +148,32 @@ func DrawItemTooltip(dst *ebiten.Image, it *items.Item, x, y int) {
 		text.Draw(dst, ln.text, basicfont.Face7x13, x+padX, ty, ln.clr)
 	}
 }
+
+// statOrder is the order stat bonuses are listed in.
+var statOrder = []string{"Strength", "Dexterity", "Vitality", "Intelligence", "Luck"}
+
+// effectText describes an item effect on one line.
+func effectText(eff *items.ItemEffect) string {
+	txt := fmt.Sprintf("%s: %s %d%%", eff.Trigger, eff.Type, eff.MagnitudePct)
+	if eff.ChancePct != 0 {
+		txt += fmt.Sprintf(" (%d%% chance)", eff.ChancePct)
+	}
+	return txt
+}
+
+// setBonusText describes what a set bonus grants, one line per part.
+func setBonusText(b items.SetBonus) []string {
+	var parts []string
+	for _, stat := range statOrder {
+		if v, ok := b.Stats[stat]; ok {
+			parts = append(parts, fmt.Sprintf("%s %+d", stat, v))
+		}
+	}
+	if b.GrantsAbility != "" {
+		parts = append(parts, "grants "+b.GrantsAbility)
+	}
+	if b.Effect != nil {
+		parts = append(parts, effectText(b.Effect))
+	}
+	return parts
+}